@@ -0,0 +1,166 @@
+// Command pricing-sim replays historical bookings through a candidate pricing
+// configuration and reports how revenue would have changed, per vehicle type
+// and per surge zone, so rate card and surge changes can be judged before
+// they go live.
+//
+//	go run ./cmd/pricing-sim -candidate candidate.json -from 2024-01-01 -out report
+//
+// The candidate file replaces some rate cards and surge settings; anything
+// it leaves out is priced as it was at the time of each booking:
+//
+//	{
+//	  "rate_cards": [{"type": "van", "base_price": 25, "price_per_km": 0.2, "price_per_minute": 0.34}],
+//	  "surge": {"sensitivity": 0.4, "max_multiplier": 2}
+//	}
+//
+// The report is written to the -out directory as report.json and as
+// by_vehicle_type.csv, by_zone.csv, distribution.csv and outliers.csv.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"logistics-platform/lib/config"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/routing"
+	"logistics-platform/lib/surge"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// candidate is a pricing configuration to backtest. Rate cards replace the
+// card for their region and vehicle type on every booking replayed; surge
+// settings left at zero keep their current values.
+type candidate struct {
+	RateCards []models.VehiclePricing `json:"rate_cards"`
+	Surge     struct {
+		Sensitivity   float64 `json:"sensitivity"`
+		MaxMultiplier float64 `json:"max_multiplier"`
+	} `json:"surge"`
+}
+
+func main() {
+	candidatePath := flag.String("candidate", "", "candidate pricing configuration (JSON)")
+	from := flag.String("from", "", "replay bookings made on or after this date (YYYY-MM-DD)")
+	to := flag.String("to", "", "replay bookings made before this date (YYYY-MM-DD)")
+	vehicleType := flag.String("vehicle-type", "", "only replay bookings of this vehicle type")
+	outlierPercent := flag.Float64("outlier", 25, "report bookings whose price changes by more than this percentage")
+	out := flag.String("out", "pricing-sim-report", "directory the report is written to")
+	flag.Parse()
+
+	if *candidatePath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.LoadConfig(); err != nil {
+		log.Printf("No .env loaded, using the environment: %v", err)
+	}
+
+	cand, err := loadCandidate(*candidatePath)
+	if err != nil {
+		log.Fatalf("Failed to read candidate: %v", err)
+	}
+
+	var window bookingWindow
+	if window.from, err = parseDate(*from); err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	if window.to, err = parseDate(*to); err != nil {
+		log.Fatalf("Invalid -to: %v", err)
+	}
+	window.vehicleType = *vehicleType
+
+	ctx := context.Background()
+	pool, err := pgxpool.Connect(ctx, config.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pool.Close()
+
+	current, err := loadRateCards(ctx, pool)
+	if err != nil {
+		log.Fatalf("Failed to load vehicle pricing: %v", err)
+	}
+	zones, err := loadSurgeZones(ctx, pool)
+	if err != nil {
+		log.Fatalf("Failed to load surge zones: %v", err)
+	}
+	variants, err := loadExperimentVariants(ctx, pool)
+	if err != nil {
+		log.Fatalf("Failed to load pricing experiments: %v", err)
+	}
+	bookings, err := loadBookings(ctx, pool, window, variants)
+	if err != nil {
+		log.Fatalf("Failed to load bookings: %v", err)
+	}
+
+	currentSurge := surge.LoadConfig()
+	candidateSurge := currentSurge
+	if cand.Surge.Sensitivity > 0 {
+		candidateSurge.Sensitivity = cand.Surge.Sensitivity
+	}
+	if cand.Surge.MaxMultiplier > 0 {
+		candidateSurge.MaxMultiplier = cand.Surge.MaxMultiplier
+	}
+
+	sim := &simulator{
+		current:        current,
+		candidate:      candidateRateCards(cand.RateCards),
+		currentSurge:   currentSurge,
+		candidateSurge: candidateSurge,
+		zones:          zones,
+		routing:        routing.NewProvider(nil),
+	}
+
+	var results []result
+	for _, booking := range bookings {
+		res, err := sim.replay(ctx, booking)
+		if err != nil {
+			log.Printf("Skipping booking %d: %v", booking.id, err)
+			continue
+		}
+		results = append(results, res)
+	}
+
+	rep := buildReport(results, *outlierPercent)
+	if err := rep.write(*out); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	fmt.Printf("Replayed %d of %d bookings, report written to %s\n", len(results), len(bookings), *out)
+	for _, group := range rep.ByVehicleType {
+		fmt.Printf("  %-12s %-3s %6d bookings  %12.2f -> %12.2f  (%+.2f%%)\n",
+			group.VehicleType, group.Currency, group.Bookings, group.CurrentRevenue, group.CandidateRevenue, group.DeltaPercent)
+	}
+}
+
+func loadCandidate(path string) (candidate, error) {
+	var cand candidate
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cand, err
+	}
+	if err := json.Unmarshal(data, &cand); err != nil {
+		return cand, err
+	}
+	for _, card := range cand.RateCards {
+		if card.Type == "" {
+			return cand, fmt.Errorf("rate card without a type")
+		}
+	}
+	return cand, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
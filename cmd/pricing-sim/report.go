@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// bucketBounds split price changes, in percent, into the distribution's
+// buckets; a change falls in the first bucket whose bound is above it.
+var bucketBounds = []float64{-20, -10, -5, -1, 1, 5, 10, 20}
+
+type report struct {
+	GeneratedAt    time.Time    `json:"generated_at"`
+	Bookings       int          `json:"bookings"`
+	OutlierPercent float64      `json:"outlier_percent"`
+	ByVehicleType  []group      `json:"by_vehicle_type"`
+	ByZone         []group      `json:"by_zone"`
+	Distribution   distribution `json:"distribution"`
+	Outliers       []result     `json:"outliers"`
+}
+
+// group is the revenue of some of the bookings before and after repricing.
+// Revenue is only summed within a currency.
+type group struct {
+	VehicleType      string  `json:"vehicle_type,omitempty"`
+	Zone             string  `json:"zone,omitempty"`
+	Currency         string  `json:"currency"`
+	Bookings         int     `json:"bookings"`
+	CurrentRevenue   float64 `json:"current_revenue"`
+	CandidateRevenue float64 `json:"candidate_revenue"`
+	Delta            float64 `json:"delta"`
+	DeltaPercent     float64 `json:"delta_percent"`
+	MedianChange     float64 `json:"median_change_percent"`
+
+	changes []float64
+}
+
+type distribution struct {
+	Buckets     []bucket           `json:"buckets"`
+	Percentiles map[string]float64 `json:"percentiles"`
+	Increased   int                `json:"increased"`
+	Decreased   int                `json:"decreased"`
+	Unchanged   int                `json:"unchanged"`
+}
+
+// bucket counts the bookings whose price changed by at least From and less
+// than To percent; an open end is omitted.
+type bucket struct {
+	From     *float64 `json:"from_percent,omitempty"`
+	To       *float64 `json:"to_percent,omitempty"`
+	Bookings int      `json:"bookings"`
+}
+
+func buildReport(results []result, outlierPercent float64) report {
+	rep := report{
+		GeneratedAt:    time.Now(),
+		Bookings:       len(results),
+		OutlierPercent: outlierPercent,
+		Outliers:       []result{},
+	}
+
+	byVehicleType := map[[2]string]*group{}
+	byZone := map[[2]string]*group{}
+	var changes []float64
+	for _, res := range results {
+		add(byVehicleType, [2]string{res.VehicleType, res.Currency}, group{VehicleType: res.VehicleType, Currency: res.Currency}, res)
+		add(byZone, [2]string{res.Zone, res.Currency}, group{Zone: res.Zone, Currency: res.Currency}, res)
+		changes = append(changes, res.DeltaPercent)
+
+		if math.Abs(res.DeltaPercent) > outlierPercent {
+			rep.Outliers = append(rep.Outliers, res)
+		}
+	}
+	rep.ByVehicleType = finish(byVehicleType)
+	rep.ByZone = finish(byZone)
+	rep.Distribution = distributionOf(changes)
+
+	sort.Slice(rep.Outliers, func(i, j int) bool {
+		return math.Abs(rep.Outliers[i].DeltaPercent) > math.Abs(rep.Outliers[j].DeltaPercent)
+	})
+	return rep
+}
+
+func add(groups map[[2]string]*group, key [2]string, empty group, res result) {
+	g, ok := groups[key]
+	if !ok {
+		g = &empty
+		groups[key] = g
+	}
+	g.Bookings++
+	g.CurrentRevenue += res.CurrentPrice
+	g.CandidateRevenue += res.CandidatePrice
+	g.changes = append(g.changes, res.DeltaPercent)
+}
+
+func finish(groups map[[2]string]*group) []group {
+	finished := []group{}
+	for _, g := range groups {
+		g.CurrentRevenue = round(g.CurrentRevenue)
+		g.CandidateRevenue = round(g.CandidateRevenue)
+		g.Delta = round(g.CandidateRevenue - g.CurrentRevenue)
+		if g.CurrentRevenue != 0 {
+			g.DeltaPercent = round(g.Delta / g.CurrentRevenue * 100)
+		}
+		sort.Float64s(g.changes)
+		g.MedianChange = percentile(g.changes, 50)
+		finished = append(finished, *g)
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		a, b := finished[i], finished[j]
+		if a.VehicleType+a.Zone != b.VehicleType+b.Zone {
+			return a.VehicleType+a.Zone < b.VehicleType+b.Zone
+		}
+		return a.Currency < b.Currency
+	})
+	return finished
+}
+
+func distributionOf(changes []float64) distribution {
+	sort.Float64s(changes)
+
+	dist := distribution{Percentiles: map[string]float64{}}
+	for i := 0; i <= len(bucketBounds); i++ {
+		var b bucket
+		if i > 0 {
+			b.From = &bucketBounds[i-1]
+		}
+		if i < len(bucketBounds) {
+			b.To = &bucketBounds[i]
+		}
+		dist.Buckets = append(dist.Buckets, b)
+	}
+
+	for _, change := range changes {
+		dist.Buckets[sort.SearchFloat64s(bucketBounds, math.Nextafter(change, math.Inf(1)))].Bookings++
+		switch {
+		case change > 0:
+			dist.Increased++
+		case change < 0:
+			dist.Decreased++
+		default:
+			dist.Unchanged++
+		}
+	}
+
+	for _, p := range []float64{5, 25, 50, 75, 95} {
+		dist.Percentiles["p"+strconv.Itoa(int(p))] = percentile(changes, p)
+	}
+	return dist
+}
+
+// percentile is the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// write saves the report as JSON and as one CSV per section.
+func (r report) write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), data, 0o644); err != nil {
+		return err
+	}
+
+	groupHeader := []string{"bookings", "currency", "current_revenue", "candidate_revenue", "delta", "delta_percent", "median_change_percent"}
+	groupRow := func(name string, g group) []string {
+		return []string{name, strconv.Itoa(g.Bookings), g.Currency, formatFloat(g.CurrentRevenue), formatFloat(g.CandidateRevenue),
+			formatFloat(g.Delta), formatFloat(g.DeltaPercent), formatFloat(g.MedianChange)}
+	}
+
+	vehicleRows := [][]string{append([]string{"vehicle_type"}, groupHeader...)}
+	for _, g := range r.ByVehicleType {
+		vehicleRows = append(vehicleRows, groupRow(g.VehicleType, g))
+	}
+	zoneRows := [][]string{append([]string{"zone"}, groupHeader...)}
+	for _, g := range r.ByZone {
+		zoneRows = append(zoneRows, groupRow(g.Zone, g))
+	}
+
+	distributionRows := [][]string{{"from_percent", "to_percent", "bookings"}}
+	for _, b := range r.Distribution.Buckets {
+		from, to := "", ""
+		if b.From != nil {
+			from = formatFloat(*b.From)
+		}
+		if b.To != nil {
+			to = formatFloat(*b.To)
+		}
+		distributionRows = append(distributionRows, []string{from, to, strconv.Itoa(b.Bookings)})
+	}
+
+	outlierRows := [][]string{{"booking_id", "vehicle_type", "zone", "currency", "booked_at", "current_price", "candidate_price", "delta",
+		"delta_percent", "current_surge", "candidate_surge", "candidate_rule_version"}}
+	for _, o := range r.Outliers {
+		outlierRows = append(outlierRows, []string{strconv.Itoa(int(o.BookingID)), o.VehicleType, o.Zone, o.Currency,
+			o.BookedAt.Format(time.RFC3339), formatFloat(o.CurrentPrice), formatFloat(o.CandidatePrice), formatFloat(o.Delta),
+			formatFloat(o.DeltaPercent), formatFloat(o.CurrentSurge), formatFloat(o.CandidateSurge), strconv.Itoa(o.CandidateVersion)})
+	}
+
+	for name, rows := range map[string][][]string{
+		"by_vehicle_type.csv": vehicleRows,
+		"by_zone.csv":         zoneRows,
+		"distribution.csv":    distributionRows,
+		"outliers.csv":        outlierRows,
+	} {
+		if err := writeCSV(filepath.Join(dir, name), rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(path string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
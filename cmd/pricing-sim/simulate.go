@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"logistics-platform/lib/fare"
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/routing"
+	"logistics-platform/lib/surge"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/spf13/viper"
+)
+
+// noZone is what bookings picked up outside every surge zone are grouped as.
+const noZone = "(no zone)"
+
+type bookingWindow struct {
+	from, to    time.Time
+	vehicleType string
+}
+
+// booking is a historical booking as it was priced. Price is before any
+// promo code discount, which is a promotion cost rather than pricing.
+type booking struct {
+	id          int32
+	vehicleType string
+	price       float64
+	currency    string
+	regionID    int32
+	shared      bool
+	pickup      models.GeoPoint
+	dropoff     models.GeoPoint
+	cargo       models.Cargo
+	bookedAt    time.Time
+	// variant is the pricing experiment variant the booking was quoted
+	// under, if any
+	variant *models.ExperimentVariant
+}
+
+// result is one booking repriced under the candidate configuration.
+type result struct {
+	BookingID        int32     `json:"booking_id"`
+	VehicleType      string    `json:"vehicle_type"`
+	Zone             string    `json:"zone"`
+	Currency         string    `json:"currency"`
+	BookedAt         time.Time `json:"booked_at"`
+	CurrentPrice     float64   `json:"current_price"`
+	CandidatePrice   float64   `json:"candidate_price"`
+	Delta            float64   `json:"delta"`
+	DeltaPercent     float64   `json:"delta_percent"`
+	CurrentSurge     float64   `json:"current_surge"`
+	CandidateSurge   float64   `json:"candidate_surge"`
+	CandidateVersion int       `json:"candidate_rule_version"`
+}
+
+type rateCardKey struct {
+	regionID    int32
+	vehicleType string
+}
+
+// rateCards are every version of every card, newest first.
+type rateCards map[rateCardKey][]models.VehiclePricing
+
+// at returns the card in effect at a time in a region. A region without cards
+// of its own is priced on the default ones, as the pricing service does.
+func (r rateCards) at(regionID int32, vehicleType string, at time.Time) (models.VehiclePricing, bool) {
+	key := rateCardKey{regionID, vehicleType}
+	if len(r[key]) == 0 {
+		key.regionID = 0
+	}
+	for _, version := range r[key] {
+		if !version.EffectiveFrom.After(at) {
+			return version, true
+		}
+	}
+	return models.VehiclePricing{}, false
+}
+
+// candidateRateCards indexes the candidate's cards, which apply to every
+// booking regardless of when it was made.
+func candidateRateCards(cards []models.VehiclePricing) rateCards {
+	indexed := rateCards{}
+	for _, card := range cards {
+		key := rateCardKey{card.RegionID, card.Type}
+		indexed[key] = []models.VehiclePricing{card}
+	}
+	return indexed
+}
+
+type simulator struct {
+	current        rateCards
+	candidate      rateCards
+	currentSurge   surge.Config
+	candidateSurge surge.Config
+	zones          []models.SurgeZone
+	routing        routing.Provider
+}
+
+// replay reprices a booking. The surge a booking paid is not stored, so it is
+// worked back out of the price and its rate card, and rescaled for the
+// candidate's sensitivity on the assumption that the zone was at its target
+// multiplier when the booking was made.
+func (s *simulator) replay(ctx context.Context, b booking) (result, error) {
+	card, ok := s.current.at(b.regionID, b.vehicleType, b.bookedAt)
+	if !ok {
+		return result{}, fmt.Errorf("no rate card for %s at %s", b.vehicleType, b.bookedAt.Format(time.RFC3339))
+	}
+	// a candidate card replaces the card that priced the booking
+	candidateCard := card
+	if cards := s.candidate[rateCardKey{card.RegionID, card.Type}]; len(cards) > 0 {
+		candidateCard = cards[0]
+	}
+	// a booking quoted in an experiment was priced at its variant's rates,
+	// which would still override the candidate's
+	if b.variant != nil {
+		card, candidateCard = b.variant.Apply(card), b.variant.Apply(candidateCard)
+	}
+
+	route, err := s.routing.Route(ctx, b.pickup, b.dropoff)
+	if err != nil {
+		return result{}, err
+	}
+
+	sharedFactor := 1.0
+	if b.shared {
+		sharedFactor = 1 - fare.SharedLoadDiscount
+	}
+
+	components, surcharges := fare.Compute(card, route.Distance, route.Duration, b.cargo, b.currency)
+	currentSurge := 1.0
+	if base := components.Total().Float64() * sharedFactor; base > 0 {
+		currentSurge = math.Max(1, (b.price-surcharges.Total().Float64())/base)
+	}
+
+	zone, zoneMax := s.zoneAt(b.pickup)
+	candidateSurge := currentSurge
+	if s.currentSurge.Sensitivity > 0 {
+		candidateSurge = 1 + (currentSurge-1)*s.candidateSurge.Sensitivity/s.currentSurge.Sensitivity
+	}
+	// a zone can only lower the configured maximum, as in surge.Target
+	limit := s.candidateSurge.MaxMultiplier
+	if zoneMax > 0 && zoneMax < limit {
+		limit = zoneMax
+	}
+	candidateSurge = math.Max(1, math.Min(candidateSurge, limit))
+
+	components, surcharges = fare.Compute(candidateCard, route.Distance, route.Duration, b.cargo, b.currency)
+	surged := components.Total().Mul(candidateSurge).Round(b.currency)
+	candidate := surged + surcharges.Total()
+	if b.shared {
+		candidate -= fare.SharedDiscount(surged, b.currency)
+	}
+	candidatePrice := candidate.Float64()
+
+	res := result{
+		BookingID:        b.id,
+		VehicleType:      b.vehicleType,
+		Zone:             zone,
+		Currency:         b.currency,
+		BookedAt:         b.bookedAt,
+		CurrentPrice:     round(b.price),
+		CandidatePrice:   candidatePrice,
+		Delta:            round(candidatePrice - b.price),
+		CurrentSurge:     round(currentSurge),
+		CandidateSurge:   round(candidateSurge),
+		CandidateVersion: candidateCard.Version,
+	}
+	if b.price != 0 {
+		res.DeltaPercent = round((candidatePrice - b.price) / b.price * 100)
+	}
+	return res, nil
+}
+
+func (s *simulator) zoneAt(point models.GeoPoint) (string, float64) {
+	for _, zone := range s.zones {
+		if geo.InPolygon(point, zone.Polygon) {
+			return zone.Name, zone.MaxMultiplier
+		}
+	}
+	return noZone, 0
+}
+
+func loadRateCards(ctx context.Context, pool *pgxpool.Pool) (rateCards, error) {
+	rows, err := pool.Query(ctx, `SELECT COALESCE(region_id, 0), vehicle_type, version, base_price, price_per_km, price_per_minute, price_per_kg,
+		price_per_cubic_metre, minimum_charges, fragile_surcharge, hazardous_surcharge, loading_assistance_fee, effective_from
+		FROM vehicle_pricing ORDER BY vehicle_type, effective_from DESC, version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := rateCards{}
+	for rows.Next() {
+		var vp models.VehiclePricing
+		var minimumCharges []byte
+		if err := rows.Scan(&vp.RegionID, &vp.Type, &vp.Version, &vp.BasePrice, &vp.PricePerKm, &vp.PricePerMinute, &vp.PricePerKg,
+			&vp.PricePerCubicMetre, &minimumCharges, &vp.FragileSurcharge, &vp.HazardousSurcharge, &vp.LoadingAssistanceFee,
+			&vp.EffectiveFrom); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(minimumCharges, &vp.MinimumCharges); err != nil {
+			return nil, err
+		}
+		// retired versions still priced the bookings made before them
+		key := rateCardKey{vp.RegionID, vp.Type}
+		cards[key] = append(cards[key], vp)
+	}
+	return cards, rows.Err()
+}
+
+// loadSurgeZones loads every zone, including inactive ones, since bookings
+// made while a zone was active are still grouped under it.
+func loadSurgeZones(ctx context.Context, pool *pgxpool.Pool) ([]models.SurgeZone, error) {
+	rows, err := pool.Query(ctx, "SELECT id, name, polygon, max_multiplier, active, created_at FROM surge_zones ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var zones []models.SurgeZone
+	for rows.Next() {
+		var zone models.SurgeZone
+		if err := rows.Scan(&zone.ID, &zone.Name, &zone.Polygon, &zone.MaxMultiplier, &zone.Active, &zone.CreatedAt); err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	return zones, rows.Err()
+}
+
+type experimentVariantKey struct {
+	experimentID int32
+	variant      string
+}
+
+// loadExperimentVariants loads the variants of every pricing experiment,
+// including ended ones, since bookings quoted while one ran were priced at
+// its rates.
+func loadExperimentVariants(ctx context.Context, pool *pgxpool.Pool) (map[experimentVariantKey]models.ExperimentVariant, error) {
+	rows, err := pool.Query(ctx, "SELECT id, variants FROM pricing_experiments")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make(map[experimentVariantKey]models.ExperimentVariant)
+	for rows.Next() {
+		var experimentID int32
+		var experimentVariants []models.ExperimentVariant
+		if err := rows.Scan(&experimentID, &experimentVariants); err != nil {
+			return nil, err
+		}
+		for _, variant := range experimentVariants {
+			variants[experimentVariantKey{experimentID, variant.Name}] = variant
+		}
+	}
+	return variants, rows.Err()
+}
+
+// loadBookings reads the bookings in the window that were not cancelled,
+// with the experiment variant each was quoted under.
+func loadBookings(ctx context.Context, pool *pgxpool.Pool, window bookingWindow, variants map[experimentVariantKey]models.ExperimentVariant) ([]booking, error) {
+	query := `SELECT id, vehicle_type, price + discount, COALESCE(currency, ''), COALESCE(region_id, 0), shared_trip_id IS NOT NULL,
+		pickup_latitude, pickup_longitude, dropoff_latitude, dropoff_longitude, cargo_weight, cargo_volume, fragile, hazardous,
+		loading_assistance, created_at, COALESCE(experiment_id, 0), COALESCE(experiment_variant, '')
+		FROM booking WHERE status != 'cancelled'`
+	var args []interface{}
+	var conditions []string
+	if !window.from.IsZero() {
+		args = append(args, window.from)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !window.to.IsZero() {
+		args = append(args, window.to)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if window.vehicleType != "" {
+		args = append(args, window.vehicleType)
+		conditions = append(conditions, fmt.Sprintf("vehicle_type = $%d", len(args)))
+	}
+	for _, condition := range conditions {
+		query += " AND " + condition
+	}
+	query += " ORDER BY created_at"
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []booking
+	for rows.Next() {
+		var b booking
+		var experimentID int32
+		var variantName string
+		if err := rows.Scan(&b.id, &b.vehicleType, &b.price, &b.currency, &b.regionID, &b.shared, &b.pickup.Latitude, &b.pickup.Longitude,
+			&b.dropoff.Latitude, &b.dropoff.Longitude, &b.cargo.Weight, &b.cargo.Volume, &b.cargo.Fragile, &b.cargo.Hazardous,
+			&b.cargo.LoadingAssistance, &b.bookedAt, &experimentID, &variantName); err != nil {
+			return nil, err
+		}
+		if experimentID != 0 {
+			if variant, ok := variants[experimentVariantKey{experimentID, variantName}]; ok {
+				b.variant = &variant
+			}
+		}
+		if b.currency == "" {
+			b.currency = defaultCurrency()
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, rows.Err()
+}
+
+// defaultCurrency is what bookings made before regional pricing were priced
+// in.
+func defaultCurrency() string {
+	if viper.IsSet("PRICING_CURRENCY") {
+		return strings.ToUpper(viper.GetString("PRICING_CURRENCY"))
+	}
+	return "USD"
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,100 @@
+import React, { useEffect, useState } from 'react';
+import { useStyletron } from 'baseui';
+import { Button } from 'baseui/button';
+import { Input } from 'baseui/input';
+import { getChatHistory } from '../services/api';
+
+const CHAT_TYPES = ['chat_message', 'chat_read', 'chat_closed', 'chat_error'];
+
+export const isChatEvent = (data) => CHAT_TYPES.includes(data.type);
+
+// TripChat is the chat of one active booking, sent over the dashboard's
+// existing notification socket. role is "user" or "driver".
+const TripChat = ({ socket, bookingId, role }) => {
+  const [css, theme] = useStyletron();
+  const [messages, setMessages] = useState([]);
+  const [draft, setDraft] = useState('');
+  const [closed, setClosed] = useState(false);
+
+  const markRead = () => {
+    if (socket && socket.readyState === WebSocket.OPEN) {
+      socket.send(JSON.stringify({ type: 'chat_read', booking_id: bookingId }));
+    }
+  };
+
+  useEffect(() => {
+    if (!bookingId) return;
+    getChatHistory(role, bookingId)
+      .then((response) => {
+        setMessages(response.data.messages);
+        markRead();
+      })
+      .catch(() => console.log('No chat history found.'));
+  }, [bookingId, role]);
+
+  useEffect(() => {
+    if (!socket) return;
+
+    const onMessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (!isChatEvent(data) || data.booking_id !== bookingId) return;
+
+      if (data.type === 'chat_message') {
+        setMessages((current) => [
+          ...current.filter((m) => !(data.client_id && m.client_id === data.client_id)),
+          data,
+        ]);
+        if (data.sender_role !== role) markRead();
+      } else if (data.type === 'chat_read') {
+        setMessages((current) => current.map((m) =>
+          data.message_ids.includes(m.id) ? { ...m, read_at: data.at } : m));
+      } else if (data.type === 'chat_closed') {
+        setClosed(true);
+      } else if (data.type === 'chat_error') {
+        setMessages((current) => current.map((m) =>
+          m.client_id === data.client_id ? { ...m, failed: data.error } : m));
+      }
+    };
+
+    socket.addEventListener('message', onMessage);
+    return () => socket.removeEventListener('message', onMessage);
+  }, [socket, bookingId, role]);
+
+  const send = () => {
+    const body = draft.trim();
+    if (!body || !socket || socket.readyState !== WebSocket.OPEN) return;
+
+    const clientId = `${Date.now()}-${Math.random().toString(36).slice(2)}`;
+    setMessages((current) => [...current, { client_id: clientId, body, sender_role: role, pending: true }]);
+    socket.send(JSON.stringify({ type: 'chat_message', booking_id: bookingId, body, client_id: clientId }));
+    setDraft('');
+  };
+
+  return (
+    <div className={css({ marginTop: theme.sizing.scale600 })}>
+      <strong>Chat</strong>
+      <div className={css({ maxHeight: '200px', overflowY: 'auto', marginTop: theme.sizing.scale300 })}>
+        {messages.map((m) => (
+          <div key={m.id || m.client_id} className={css({ textAlign: m.sender_role === role ? 'right' : 'left' })}>
+            <span>{m.body}</span>
+            {m.sender_role === role && (
+              <small className={css({ marginLeft: theme.sizing.scale200 })}>
+                {m.failed ? `failed: ${m.failed}` : m.pending ? 'sending' : m.read_at ? 'read' : 'sent'}
+              </small>
+            )}
+          </div>
+        ))}
+      </div>
+      {closed ? (
+        <p>Chat closed.</p>
+      ) : (
+        <div className={css({ display: 'flex', gap: theme.sizing.scale300, marginTop: theme.sizing.scale300 })}>
+          <Input value={draft} onChange={(e) => setDraft(e.target.value)} placeholder="Message" />
+          <Button onClick={send}>Send</Button>
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default TripChat;
@@ -1,4 +1,4 @@
-import React, { useEffect, useState, useCallback } from 'react';
+import React, { useEffect, useState, useCallback, useRef } from 'react';
 import { useStyletron } from 'baseui';
 import { Button } from 'baseui/button';
 import { FormControl } from "baseui/form-control";
@@ -12,6 +12,7 @@ import { Tabs, Tab } from "baseui/tabs-motion";
 import { Accordion, Panel } from "baseui/accordion";
 import { ProgressBar } from "baseui/progress-bar";
 import Navbar from '../components/Navbar';
+import TripChat, { isChatEvent } from '../components/TripChat';
 import { confirmBooking, updateBookingStatus, getDriverBookingHistory, getCurrentDriverBooking } from '../services/api';
 
 const DriverDashboard = () => {
@@ -24,6 +25,7 @@ const DriverDashboard = () => {
   const [journey, setJourney] = useState(false);
   const [userId, setUserId] = useState(null);
   const [userName, setUserName] = useState(null);
+  const [bookingId, setBookingId] = useState(null);
   const [journeyStatus, setJourneyStatus] = useState([
     { label: 'Enroute to Pickup', id: 'enroute_to_pickup' },
   ]);
@@ -33,6 +35,16 @@ const DriverDashboard = () => {
     { label: 'Completed', id: 'completed' },
   ]);
   const [bookingHistory, setBookingHistory] = useState([]);
+  const [declineReason, setDeclineReason] = useState([]);
+  const [heatmap, setHeatmap] = useState(null);
+  const pendingOfferRef = useRef(null);
+  const declineReasons = [
+    { label: 'Too far', id: 'too_far' },
+    { label: 'Price too low', id: 'price_too_low' },
+    { label: 'Wrong vehicle', id: 'wrong_vehicle' },
+    { label: 'Busy', id: 'busy' },
+    { label: 'Other', id: 'other' },
+  ];
   const [journeyPickup, setJourneyPickup] = useState({
     name: "Pickup Location",
     latitude: 0,
@@ -68,6 +80,7 @@ const DriverDashboard = () => {
         const data = response.data.booking;
         setJourney(true);
         setUserId(data.user_id);
+        setBookingId(data.id);
         setJourneyStatus(statusOptions.filter((status) => status.id === data.status));
         setJourneyPickup(data.pickup);
         setJourneyDropoff(data.dropoff);
@@ -96,6 +109,18 @@ const DriverDashboard = () => {
 
     socket.onmessage = (event) => {
       const data = JSON.parse(event.data);
+      if (data.type === 'offer_ack') {
+        handleOfferAck(data);
+        return;
+      }
+      if (data.type === 'surge_heatmap') {
+        setHeatmap(data);
+        return;
+      }
+      if (isChatEvent(data)) {
+        // handled by TripChat
+        return;
+      }
       console.log('Received booking request:', data);
       setBookingRequest(data);
     };
@@ -148,18 +173,43 @@ const DriverDashboard = () => {
     }
   };
 
+  const startJourney = (offer, data) => {
+    setJourney(true);
+    setUserId(data.user_id || (data.user_ids && data.user_ids[0]));
+    setJourneyPickup(offer.pickup);
+    setJourneyDropoff(offer.dropoff);
+    setUserName(offer.user_name);
+    // the booking id for the chat is only known once the booking is stored
+    fetchCurrentBooking();
+  };
+
+  const handleOfferAck = (ack) => {
+    if (ack.action === 'accept_offer') {
+      const offer = pendingOfferRef.current;
+      pendingOfferRef.current = null;
+      if (ack.success && offer) {
+        startJourney(offer, ack);
+        toaster.positive("Booking confirmed successfully!", {});
+      } else {
+        toaster.negative(`Error confirming booking: ${ack.error}`, {});
+      }
+    } else if (ack.action === 'decline_offer' && !ack.success) {
+      toaster.negative(`Error declining booking: ${ack.error}`, {});
+    }
+  };
+
   const handleConfirmBooking = async () => {
+    if (ws && ws.readyState === WebSocket.OPEN) {
+      pendingOfferRef.current = bookingRequest;
+      ws.send(JSON.stringify({ type: 'accept_offer', request_id: bookingRequest.mongo_id, mongo_id: bookingRequest.mongo_id }));
+      setBookingRequest(null);
+      return;
+    }
+
     try {
       const response = await confirmBooking({ mongo_id: bookingRequest.mongo_id });
       if (response.status === 200) {
-        const data = response.data;
-        setJourney(true);
-        setUserId(data.user_id);
-        setJourneyPickup(bookingRequest.pickup);
-        setJourneyDropoff(bookingRequest.dropoff);
-        setUserName(bookingRequest.user_name);
-        // setJourneyPickup(data.pickup);
-        // setJourneyDropoff(data.dropoff);
+        startJourney(bookingRequest, response.data);
         toaster.positive("Booking confirmed successfully!", {});
       }
     } catch (error) {
@@ -173,6 +223,20 @@ const DriverDashboard = () => {
     toaster.info("Booking request ignored.", {});
   };
 
+  const handleDeclineBooking = () => {
+    if (ws && ws.readyState === WebSocket.OPEN) {
+      ws.send(JSON.stringify({
+        type: 'decline_offer',
+        request_id: bookingRequest.mongo_id,
+        mongo_id: bookingRequest.mongo_id,
+        reason: declineReason.length ? declineReason[0].id : 'other',
+      }));
+    }
+    setBookingRequest(null);
+    setDeclineReason([]);
+    toaster.info("Booking request declined.", {});
+  };
+
   const updateJourneyStatus = async () => {
     if (!journeyStatus.length || !userId) return;
     
@@ -195,6 +259,7 @@ const DriverDashboard = () => {
   const resetJourney = () => {
     setJourney(false);
     setUserId(null);
+    setBookingId(null);
     setJourneyStatus([{ label: 'Enroute to Pickup', id: 'enroute_to_pickup' }]);
   };
 
@@ -234,6 +299,7 @@ const DriverDashboard = () => {
           >
             Update Status
           </Button>
+          {bookingId && <TripChat socket={ws} bookingId={bookingId} role="driver" />}
         </div>
       </StyledBody>
     </Card>
@@ -251,14 +317,51 @@ const DriverDashboard = () => {
           <FlexGridItem><strong>Pickup:</strong> {bookingRequest?.pickup?.name}</FlexGridItem>
           <FlexGridItem><strong>Dropoff:</strong> {bookingRequest?.dropoff?.name}</FlexGridItem>
         </FlexGrid>
+        <FormControl label="Decline reason">
+          <Select
+            options={declineReasons}
+            labelKey="label"
+            valueKey="id"
+            type={TYPE.select}
+            value={declineReason}
+            onChange={({ value }) => setDeclineReason(value)}
+          />
+        </FormControl>
         <div className={css({ display: 'flex', justifyContent: 'space-between', marginTop: theme.sizing.scale800 })}>
           <Button onClick={handleIgnoreBooking} kind="secondary">Ignore</Button>
+          <Button onClick={handleDeclineBooking} kind="secondary">Decline</Button>
           <Button onClick={handleConfirmBooking}>Confirm</Button>
         </div>
       </StyledBody>
     </Card>
   );
 
+  const renderSurgeHeatmap = () => {
+    const zones = [...heatmap.zones].sort((a, b) => b.multiplier - a.multiplier);
+    return (
+      <Card>
+        <StyledBody>
+          <HeadingLevel>
+            <Heading styleLevel={3}>Surge Zones</Heading>
+          </HeadingLevel>
+          {zones.length === 0 ? (
+            <p>No surge zones.</p>
+          ) : (
+            <FlexGrid flexGridColumnCount={2} flexGridColumnGap="scale300" flexGridRowGap="scale300">
+              {zones.map((zone) => (
+                <FlexGridItem key={zone.zone_id}>
+                  <strong>{zone.zone_name}:</strong>{' '}
+                  <Tag closeable={false} kind={zone.multiplier > 1 ? 'warning' : 'neutral'}>x{zone.multiplier.toFixed(2)}</Tag>
+                </FlexGridItem>
+              ))}
+            </FlexGrid>
+          )}
+          <p>Updated {new Date(heatmap.generated_at).toLocaleTimeString()}</p>
+        </StyledBody>
+      </Card>
+    );
+  };
+
   const renderBookingHistory = () => (
     <Card>
       <StyledBody>
@@ -326,6 +429,7 @@ const DriverDashboard = () => {
                 </Card>
               )}
               {bookingRequest && renderBookingNotification()}
+              {heatmap && renderSurgeHeatmap()}
             </div>
           </Tab>
           <Tab title="Booking History">
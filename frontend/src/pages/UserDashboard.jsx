@@ -1,5 +1,7 @@
 import React, { useState, useEffect, useCallback } from "react";
 import { FormControl } from "baseui/form-control";
+import { Input } from "baseui/input";
+import { Checkbox } from "baseui/checkbox";
 import { Button } from "baseui/button";
 import { Heading, HeadingLevel } from "baseui/heading";
 import { useStyletron } from "baseui";
@@ -14,7 +16,8 @@ import { Tabs, Tab } from "baseui/tabs-motion";
 import { ProgressBar } from "baseui/progress-bar";
 import Navbar from "../components/Navbar";
 import TrackingMap from "../components/TrackingMap";
-import { makeBooking, getPrice, getUserBookingHistory, getLocationCoordinates, getCurrentUserBooking } from "../services/api";
+import TripChat, { isChatEvent } from "../components/TripChat";
+import { makeBooking, getPrice, getVehicleTypes, getUserBookingHistory, getLocationCoordinates, getCurrentUserBooking } from "../services/api";
 import _ from "lodash";
 
 export default function UserDashboard() {
@@ -22,6 +25,10 @@ export default function UserDashboard() {
   const [activeTab, setActiveTab] = useState("0");
   const [vehicleType, setVehicleType] = useState('');
   const [price, setPrice] = useState('');
+  const [promoCode, setPromoCode] = useState('');
+  const [discount, setDiscount] = useState(null);
+  const [breakdown, setBreakdown] = useState(null);
+  const [cargo, setCargo] = useState({ weight: '', volume: '', fragile: false, hazardous: false, loadingAssistance: false });
   const [isConnected, setIsConnected] = useState(false);
   const [pickupOptions, setPickupOptions] = useState([]);
   const [dropoffOptions, setDropoffOptions] = useState([]);
@@ -39,6 +46,9 @@ export default function UserDashboard() {
   ]);
   const [driverName, setDriverName] = useState('');
   const [status, setStatus] = useState('');
+  const [eta, setEta] = useState(null);
+  const [socket, setSocket] = useState(null);
+  const [bookingId, setBookingId] = useState(null);
   const [bookingHistory, setBookingHistory] = useState([]);
   const [waitingForDriver, setWaitingForDriver] = useState(false);
   const [bookingTime, setBookingTime] = useState(null);
@@ -46,6 +56,20 @@ export default function UserDashboard() {
   const debouncedFetchPickup = useCallback(_.debounce((query) => fetchLocations(query, setPickupOptions), 500), []);
   const debouncedFetchDropoff = useCallback(_.debounce((query) => fetchLocations(query, setDropoffOptions), 500), []);
 
+  useEffect(() => {
+    // offer only the vehicle types with a rate card in effect
+    getVehicleTypes()
+      .then((response) => {
+        if (response.data.length) {
+          setVehicleOptions(response.data.map((vehicle) => ({
+            id: _.startCase(vehicle.type),
+            value: vehicle.type,
+          })));
+        }
+      })
+      .catch((error) => console.error("Error fetching vehicle types:", error));
+  }, []);
+
   useEffect(() => {
     const fetchBookingHistory = async () => {
       try {
@@ -106,6 +130,7 @@ export default function UserDashboard() {
           // setDriverName(response.data.booking.driver_id);
           setDriverName(response.data.booking.driver_name);
           setStatus(response.data.booking.status);
+          setBookingId(response.data.booking.id);
           // start socket connection
           startSocketConnection();
 
@@ -137,6 +162,7 @@ export default function UserDashboard() {
 
   const startSocketConnection = () => {
     const socket = new WebSocket(`ws://localhost:8080/user/ws`);
+    setSocket(socket);
     socket.onopen = () => {
       setIsConnected(true);
       // toaster.info("Connected. Waiting for a driver to accept your request.", {});
@@ -145,6 +171,23 @@ export default function UserDashboard() {
     socket.onmessage = (event) => {
       const data = JSON.parse(event.data);
       console.log("Received data:", data);
+      if (isChatEvent(data)) {
+        // handled by TripChat
+        return;
+      }
+      if (data.type === "eta_update") {
+        setEta(data.eta_minutes);
+        return;
+      }
+      if (data.status === "payment_failed") {
+        toaster.negative("Your payment could not be authorized. The booking request was cancelled.", {});
+        resetStates();
+        return;
+      }
+      if (data.status === "promo_not_applied") {
+        toaster.warning("Your promo code had run out by the time a driver accepted. The full fare applies.", {});
+        return;
+      }
       if (data.status) {
         // setDriverName(data.driver_id);
         setStatus(data.status);
@@ -156,6 +199,7 @@ export default function UserDashboard() {
           setWaitingForDriver(false);
           setShowMap(true);
           setDriverName(data.driver_name)
+          setBookingId(data.booking_id);
           toaster.positive("Active Booking", {});
         }
         if (data.status === "completed") {
@@ -174,6 +218,9 @@ export default function UserDashboard() {
   const resetStates = () => {
     setVehicleType('');
     setPrice('');
+    setPromoCode('');
+    setDiscount(null);
+    setBreakdown(null);
     setPickupOptions([]);
     setDropoffOptions([]);
     setPickup([]);
@@ -182,6 +229,8 @@ export default function UserDashboard() {
     setIsConnected(false);
     setDriverName('');
     setStatus('');
+    setEta(null);
+    setBookingId(null);
     setWaitingForDriver(false);
     setShowMap(false);
     setBookingTime(null);
@@ -205,10 +254,17 @@ export default function UserDashboard() {
         dropoff: {
           "latitude": parseFloat(dropoff[0].latitude),
           "longitude": parseFloat(dropoff[0].longitude),
-        }
+        },
+        promo_code: promoCode.trim() || undefined,
+        ...cargoRequest(),
       });
       setPrice(response.data.price.toFixed(2));
-      toaster.positive(`Estimated Price: $${response.data.price.toFixed(2)}`, {});
+      setDiscount(response.data.discount || null);
+      setBreakdown(response.data);
+      if (response.data.promo_error) {
+        toaster.warning(response.data.promo_error, {});
+      }
+      toaster.positive(`Estimated Price: ${response.data.price.toFixed(2)} ${response.data.currency}`, {});
     } catch (error) {
       toaster.negative("Error fetching price. Please try again.", {});
     } finally {
@@ -216,6 +272,19 @@ export default function UserDashboard() {
     }
   };
 
+  const cargoRequest = () => ({
+    cargo_weight: parseFloat(cargo.weight) || undefined,
+    cargo_volume: parseFloat(cargo.volume) || undefined,
+    fragile: cargo.fragile || undefined,
+    hazardous: cargo.hazardous || undefined,
+    loading_assistance: cargo.loadingAssistance || undefined,
+  });
+
+  const updateCargo = (changes) => {
+    setCargo({ ...cargo, ...changes });
+    setPrice('');
+  };
+
   const bookRequest = async (e) => {
     e.preventDefault();
     if (!vehicleType || !price || !pickup.length || !dropoff.length) {
@@ -236,6 +305,8 @@ export default function UserDashboard() {
         },
         vehicle_type: vehicleType,
         price: parseFloat(price),
+        promo_code: discount ? discount.code : undefined,
+        ...cargoRequest(),
       });
       if (response.status === 200) {
         setWaitingForDriver(true);
@@ -243,7 +314,7 @@ export default function UserDashboard() {
         startSocketConnection();
       }
     } catch (error) {
-      toaster.negative("Error making booking. Please try again.", {});
+      toaster.negative(error.response?.data?.error || "Error making booking. Please try again.", {});
     }
   };
 
@@ -264,7 +335,9 @@ export default function UserDashboard() {
             <div className={css({ marginTop: theme.sizing.scale600 })}>
               <p><strong>Driver:</strong> {driverName}</p>
               <p><strong>Status:</strong> <Tag closeable={false}>{status}</Tag></p>
+              {eta !== null && <p><strong>ETA:</strong> {Math.round(eta)} min</p>}
             </div>
+            {bookingId && <TripChat socket={socket} bookingId={bookingId} role="user" />}
           </>
         ) : waitingForDriver ? (
           <div className={css({ textAlign: 'center' })}>
@@ -276,7 +349,7 @@ export default function UserDashboard() {
               <p><strong>Vehicle Type:</strong> {vehicleType}</p>
               <p><strong>Pickup:</strong> {pickup[0].id}</p>
               <p><strong>Dropoff:</strong> {dropoff[0].id}</p>
-              <p><strong>Estimated Price:</strong> ${price}</p>
+              <p><strong>Estimated Price:</strong> {price} {breakdown?.currency}</p>
               <p><strong>Booking Time:</strong> {bookingTime.toLocaleString()}</p>
             </div>
           </div>
@@ -323,6 +396,41 @@ export default function UserDashboard() {
               />
             </FormControl>
 
+            <FlexGrid flexGridColumnCount={2} flexGridColumnGap="scale600">
+              <FlexGridItem>
+                <FormControl label="Cargo Weight (kg)" caption="Optional">
+                  <Input type="number" min="0" value={cargo.weight} onChange={(e) => updateCargo({ weight: e.target.value })} />
+                </FormControl>
+              </FlexGridItem>
+              <FlexGridItem>
+                <FormControl label="Cargo Volume (m³)" caption="Optional">
+                  <Input type="number" min="0" value={cargo.volume} onChange={(e) => updateCargo({ volume: e.target.value })} />
+                </FormControl>
+              </FlexGridItem>
+            </FlexGrid>
+
+            <FormControl label="Handling">
+              <div>
+                <Checkbox checked={cargo.fragile} onChange={(e) => updateCargo({ fragile: e.target.checked })}>Fragile goods</Checkbox>
+                <Checkbox checked={cargo.hazardous} onChange={(e) => updateCargo({ hazardous: e.target.checked })}>Hazardous goods</Checkbox>
+                <Checkbox checked={cargo.loadingAssistance} onChange={(e) => updateCargo({ loadingAssistance: e.target.checked })}>
+                  Loading assistance
+                </Checkbox>
+              </div>
+            </FormControl>
+
+            <FormControl label="Promo Code" caption="Optional">
+              <Input
+                value={promoCode}
+                onChange={(e) => {
+                  setPromoCode(e.target.value);
+                  setPrice('');
+                  setDiscount(null);
+                }}
+                placeholder="Enter a promo code"
+              />
+            </FormControl>
+
             <div className={css({
               display: 'flex',
               justifyContent: 'space-between',
@@ -339,8 +447,36 @@ export default function UserDashboard() {
               >
                 {loadingPrice ? <Spinner /> : "Get Price"}
               </Button>
-              {price && <p className={css({ fontWeight: "bold" })}>Estimated Price: ${price}</p>}
+              {price && <p className={css({ fontWeight: "bold" })}>Estimated Price: {price} {breakdown?.currency}</p>}
             </div>
+            {price && breakdown && (
+              <div className={css({ marginTop: theme.sizing.scale300 })}>
+                <p>Base fare ({breakdown.vehicle_type} v{breakdown.rule_version}): {breakdown.base_fare.toFixed(2)} {breakdown.currency}</p>
+                <p>Distance ({breakdown.distance.toFixed(1)} km via {breakdown.routing_provider}): {breakdown.distance_charge.toFixed(2)}</p>
+                <p>Time ({Math.round(breakdown.duration)} min): {breakdown.time_charge.toFixed(2)}</p>
+                {breakdown.weight_charge && <p>Weight ({cargo.weight} kg): {breakdown.weight_charge.toFixed(2)}</p>}
+                {breakdown.volume_charge && <p>Volume ({cargo.volume} m³): {breakdown.volume_charge.toFixed(2)}</p>}
+                {breakdown.minimum_charge_adjustment && <p>Minimum charge: {breakdown.minimum_charge_adjustment.toFixed(2)}</p>}
+                {breakdown.surge_amount !== 0 && (
+                  <p>Surge (x{breakdown.surge_multiplier.toFixed(2)}): {breakdown.surge_amount.toFixed(2)}</p>
+                )}
+                {breakdown.fragile_surcharge && <p>Fragile goods: {breakdown.fragile_surcharge.toFixed(2)}</p>}
+                {breakdown.hazardous_surcharge && <p>Hazardous goods: {breakdown.hazardous_surcharge.toFixed(2)}</p>}
+                {breakdown.loading_assistance_fee && <p>Loading assistance: {breakdown.loading_assistance_fee.toFixed(2)}</p>}
+                {breakdown.shared_discount && <p>Shared load: -{breakdown.shared_discount.toFixed(2)}</p>}
+                {breakdown.tax !== 0 && (
+                  <p>
+                    {breakdown.tax_name} ({breakdown.tax_rate}%{breakdown.tax_inclusive ? ", included" : ""}): {breakdown.tax.toFixed(2)}
+                  </p>
+                )}
+                <p>Payable: {breakdown.payable.toFixed(2)} {breakdown.currency}</p>
+              </div>
+            )}
+            {price && discount && (
+              <p className={css({ color: theme.colors.positive })}>
+                Promo {discount.code}: -{discount.amount.toFixed(2)} {breakdown?.currency}
+              </p>
+            )}
 
             {price && (
               <div className={css({
@@ -29,6 +29,13 @@ const adminApi = axios.create({
   },
 })
 
+const notificationApi = axios.create({
+  baseURL: `http://${NOTIFICATION_URL}`,
+  headers: {
+    'Content-Type': 'application/json',
+  },
+})
+
 const pricingApi = axios.create({
   baseURL: PRICING_URL,
   headers: {
@@ -52,6 +59,14 @@ bookingApi.interceptors.request.use((config) => {
   return config
 })
 
+notificationApi.interceptors.request.use((config) => {
+  const token = localStorage.getItem('token')
+  if (token) {
+    config.headers['Authorization'] = `Bearer ${token}`
+  }
+  return config
+})
+
 pricingApi.interceptors.request.use((config) => {
   const token = localStorage.getItem('token')
   if (token) {
@@ -69,6 +84,7 @@ export const loginDriver = (credentials) => authApi.post('/driver/login', creden
 export const getDriverProfile = (id) => authApi.get(`/driver/profile/${id}`)
 
 export const getPrice = (data) => pricingApi.post('/pricing/estimate', data)
+export const getVehicleTypes = () => pricingApi.get('/pricing/vehicles')
 
 export const makeBooking = (bookingData) => bookingApi.post('/booking', bookingData)
 export const confirmBooking = (bookingId) => bookingApi.post(`/booking/accept`, bookingId)
@@ -78,6 +94,8 @@ export const getDriverBookingHistory = () => bookingApi.get('/driver/booking-his
 export const getCurrentUserBooking = () => bookingApi.get('/user/booking')
 export const getCurrentDriverBooking = () => bookingApi.get('/driver/booking')
 
+export const getChatHistory = (role, bookingId) => notificationApi.get(`/${role}/chat/${bookingId}`)
+
 export const getFleetStats = () => adminApi.get('/fleet-stats')
 export const getDriverPerformance = () => adminApi.get('/driver-performance')
 export const getBookingAnalytics = () => adminApi.get('/booking-analytics')
@@ -13,3 +13,36 @@ func LoadConfig() error {
 func GetDBConnectionString() string {
 	return viper.GetString("POSTGRES_URL")
 }
+
+// defaultPricingGRPCAddress is the pricing service's gRPC server on the
+// docker-compose network.
+const defaultPricingGRPCAddress = "pricing:9086"
+
+func GetPricingGRPCAddress() string {
+	if address := viper.GetString("PRICING_GRPC_ADDRESS"); address != "" {
+		return address
+	}
+	return defaultPricingGRPCAddress
+}
+
+// IsDevelopment reports whether the service runs outside a release build,
+// where missing secrets may fall back to development defaults.
+func IsDevelopment() bool {
+	return viper.GetString("GIN_MODE") != "release"
+}
+
+func GetPaymentProvider() string {
+	return viper.GetString("PAYMENT_PROVIDER")
+}
+
+func GetPaymentWebhookSecret() string {
+	return viper.GetString("PAYMENT_WEBHOOK_SECRET")
+}
+
+func GetRoutingProvider() string {
+	return viper.GetString("ROUTING_PROVIDER")
+}
+
+func GetRoutingServiceURL() string {
+	return viper.GetString("ROUTING_SERVICE_URL")
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS booking_ratings;
\ No newline at end of file
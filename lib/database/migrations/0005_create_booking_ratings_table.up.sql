@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS booking_ratings (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL,
+    rater_role VARCHAR(16) NOT NULL,
+    rater_id INTEGER NOT NULL,
+    ratee_id INTEGER NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (booking_id, rater_role)
+);
+
+CREATE INDEX IF NOT EXISTS booking_ratings_ratee_idx ON booking_ratings (rater_role, ratee_id);
@@ -0,0 +1,21 @@
+-- Single-row counter locked inside the invoicing transaction so invoice
+-- numbers stay gap-free across booking service instances.
+CREATE TABLE IF NOT EXISTS invoice_sequence (
+    id SMALLINT PRIMARY KEY,
+    last_number BIGINT NOT NULL
+);
+
+INSERT INTO invoice_sequence (id, last_number) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    invoice_number VARCHAR(32) UNIQUE NOT NULL,
+    booking_id INTEGER UNIQUE NOT NULL,
+    user_id INTEGER NOT NULL,
+    driver_id INTEGER NOT NULL,
+    line_items JSONB NOT NULL,
+    subtotal FLOAT NOT NULL,
+    tax FLOAT NOT NULL,
+    total FLOAT NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
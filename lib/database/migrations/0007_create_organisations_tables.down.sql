@@ -0,0 +1,5 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS cost_centre_id;
+ALTER TABLE booking DROP COLUMN IF EXISTS organisation_id;
+DROP TABLE IF EXISTS cost_centres;
+DROP TABLE IF EXISTS organisation_members;
+DROP TABLE IF EXISTS organisations;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS organisations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    monthly_spend_limit FLOAT,
+    approval_threshold FLOAT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- a user belongs to at most one organisation
+CREATE TABLE IF NOT EXISTS organisation_members (
+    organisation_id INTEGER NOT NULL REFERENCES organisations (id),
+    user_id INTEGER UNIQUE NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organisation_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS cost_centres (
+    id SERIAL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL REFERENCES organisations (id),
+    code VARCHAR(64) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    UNIQUE (organisation_id, code)
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS organisation_id INTEGER;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS cost_centre_id INTEGER;
@@ -0,0 +1,2 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS shared_discount;
+ALTER TABLE booking DROP COLUMN IF EXISTS shared_trip_id;
@@ -0,0 +1,5 @@
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS shared_trip_id VARCHAR(64);
+
+-- the shared load discount a pooled booking was given, itemised on its
+-- invoice
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS shared_discount FLOAT NOT NULL DEFAULT 0;
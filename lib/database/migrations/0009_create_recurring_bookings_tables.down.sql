@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scheduled_bookings;
+DROP TABLE IF EXISTS recurring_bookings;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS recurring_bookings (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    pickup_latitude FLOAT NOT NULL,
+    pickup_longitude FLOAT NOT NULL,
+    pickup_name VARCHAR(255) NOT NULL,
+    dropoff_latitude FLOAT NOT NULL,
+    dropoff_longitude FLOAT NOT NULL,
+    dropoff_name VARCHAR(255) NOT NULL,
+    vehicle_type VARCHAR(255) NOT NULL,
+    frequency VARCHAR(16) NOT NULL,
+    by_days TEXT[] NOT NULL DEFAULT '{}',
+    time_of_day VARCHAR(5) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    starts_on DATE NOT NULL,
+    ends_on DATE,
+    exception_dates TEXT[] NOT NULL DEFAULT '{}',
+    prefer_same_driver BOOLEAN NOT NULL DEFAULT FALSE,
+    last_driver_id INTEGER,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS scheduled_bookings (
+    id SERIAL PRIMARY KEY,
+    -- NULL for a one-off request held until near pickup, kept in booking_request
+    recurring_booking_id INTEGER REFERENCES recurring_bookings (id),
+    user_id INTEGER NOT NULL,
+    pickup_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    booking_request JSONB,
+    status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (recurring_booking_id, pickup_at)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_bookings_due_idx ON scheduled_bookings (status, pickup_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS driver_preferences (
+    user_id INTEGER NOT NULL,
+    driver_id INTEGER NOT NULL,
+    preference VARCHAR(16) NOT NULL CHECK (preference IN ('favourite', 'blocked')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, driver_id)
+);
+
+CREATE INDEX IF NOT EXISTS driver_preferences_driver_idx ON driver_preferences (driver_id, preference);
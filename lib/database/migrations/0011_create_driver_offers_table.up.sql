@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS driver_offers (
+    id SERIAL PRIMARY KEY,
+    mongo_id VARCHAR(64) NOT NULL,
+    driver_id INTEGER NOT NULL,
+    offered_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    response VARCHAR(16),
+    reason VARCHAR(32),
+    note TEXT,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (mongo_id, driver_id)
+);
+
+CREATE INDEX IF NOT EXISTS driver_offers_driver_idx ON driver_offers (driver_id, offered_at);
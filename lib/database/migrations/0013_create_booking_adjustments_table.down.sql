@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS invoices_booking_idx;
+DROP INDEX IF EXISTS invoices_original_booking_idx;
+DELETE FROM invoices WHERE original_invoice_id IS NOT NULL;
+ALTER TABLE invoices DROP COLUMN IF EXISTS original_invoice_id;
+ALTER TABLE invoices ADD CONSTRAINT invoices_booking_id_key UNIQUE (booking_id);
+
+ALTER TABLE booking DROP COLUMN IF EXISTS adjustments_total;
+DROP TABLE IF EXISTS booking_adjustments;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS booking_adjustments (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    quantity FLOAT NOT NULL DEFAULT 0,
+    unit VARCHAR(16) NOT NULL DEFAULT '',
+    amount FLOAT NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    requested_by VARCHAR(16) NOT NULL,
+    -- the invoice an approved adjustment was billed on
+    invoice_id INTEGER REFERENCES invoices(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS booking_adjustments_booking_idx ON booking_adjustments (booking_id);
+-- a booking takes one tip and one of each fixed surcharge
+CREATE UNIQUE INDEX IF NOT EXISTS booking_adjustments_once_idx ON booking_adjustments (booking_id, kind)
+    WHERE kind IN ('tip', 'loading', 'unloading') AND status != 'rejected';
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS adjustments_total FLOAT NOT NULL DEFAULT 0;
+
+-- issued invoices never change; adjustments approved later are billed on
+-- supplementary invoices that point at the booking's original invoice
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS original_invoice_id INTEGER REFERENCES invoices(id);
+ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_booking_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS invoices_original_booking_idx ON invoices (booking_id) WHERE original_invoice_id IS NULL;
+CREATE INDEX IF NOT EXISTS invoices_booking_idx ON invoices (booking_id);
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    reference VARCHAR(64) NOT NULL UNIQUE,
+    booking_id INTEGER,
+    description VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS payout_statements (
+    id SERIAL PRIMARY KEY,
+    driver_id INTEGER NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    trip_fares FLOAT NOT NULL DEFAULT 0,
+    commission FLOAT NOT NULL DEFAULT 0,
+    tips FLOAT NOT NULL DEFAULT 0,
+    adjustments FLOAT NOT NULL DEFAULT 0,
+    bonuses FLOAT NOT NULL DEFAULT 0,
+    penalties FLOAT NOT NULL DEFAULT 0,
+    amount FLOAT NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    settled_at TIMESTAMP WITH TIME ZONE,
+    settlement_reference VARCHAR(128),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (driver_id, period_start)
+);
+
+-- driver_id is set on driver account entries only; statement_id is set once
+-- the entry has been included in a payout statement
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES ledger_transactions(id),
+    account VARCHAR(16) NOT NULL,
+    driver_id INTEGER,
+    kind VARCHAR(16) NOT NULL,
+    amount FLOAT NOT NULL,
+    statement_id INTEGER REFERENCES payout_statements(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_driver_idx ON ledger_entries (driver_id, created_at) WHERE account = 'driver';
+CREATE INDEX IF NOT EXISTS ledger_entries_statement_idx ON ledger_entries (statement_id);
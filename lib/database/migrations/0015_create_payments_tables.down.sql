@@ -0,0 +1,4 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS payment_status;
+DROP TABLE IF EXISTS payment_events;
+DROP TABLE IF EXISTS payment_refunds;
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,42 @@
+-- one payment per booking request, booking_id being set once a driver
+-- accepts, and one charge per invoice that bills beyond the booking's
+-- authorization, such as a supplementary invoice for tips and adjustments
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    mongo_id VARCHAR(24) UNIQUE,
+    booking_id INTEGER,
+    invoice_id INTEGER UNIQUE REFERENCES invoices(id),
+    user_id INTEGER NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    authorization_id VARCHAR(64) UNIQUE,
+    amount FLOAT NOT NULL,
+    captured_amount FLOAT NOT NULL DEFAULT 0,
+    refunded_amount FLOAT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(24) NOT NULL,
+    failure_reason VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS payments_booking_idx ON payments (booking_id);
+CREATE INDEX IF NOT EXISTS payments_status_idx ON payments (status);
+
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id SERIAL PRIMARY KEY,
+    payment_id INTEGER NOT NULL REFERENCES payments(id),
+    refund_id VARCHAR(64) NOT NULL,
+    amount FLOAT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- provider webhook events already applied, so redeliveries are ignored
+CREATE TABLE IF NOT EXISTS payment_events (
+    id VARCHAR(64) PRIMARY KEY,
+    authorization_id VARCHAR(64) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS payment_status VARCHAR(24);
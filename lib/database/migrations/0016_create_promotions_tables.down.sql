@@ -0,0 +1,4 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS discount;
+ALTER TABLE booking DROP COLUMN IF EXISTS promotion_id;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
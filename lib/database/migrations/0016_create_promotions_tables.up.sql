@@ -0,0 +1,39 @@
+-- promo codes; zero limits mean unlimited and an empty vehicle_types list
+-- applies to every vehicle
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    campaign VARCHAR(64),
+    description VARCHAR(255),
+    discount_type VARCHAR(16) NOT NULL,
+    discount_value FLOAT NOT NULL,
+    max_discount FLOAT NOT NULL DEFAULT 0,
+    first_booking_only BOOLEAN NOT NULL DEFAULT FALSE,
+    per_user_limit INTEGER NOT NULL DEFAULT 0,
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    redemption_count INTEGER NOT NULL DEFAULT 0,
+    vehicle_types TEXT[] NOT NULL DEFAULT '{}',
+    zone_latitude FLOAT NOT NULL DEFAULT 0,
+    zone_longitude FLOAT NOT NULL DEFAULT 0,
+    zone_radius_km FLOAT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS promotions_campaign_idx ON promotions (campaign);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id SERIAL PRIMARY KEY,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id),
+    user_id INTEGER NOT NULL,
+    booking_id INTEGER NOT NULL UNIQUE,
+    discount FLOAT NOT NULL,
+    redeemed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS promotion_redemptions_user_idx ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS promotion_id INTEGER;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS discount FLOAT NOT NULL DEFAULT 0;
@@ -0,0 +1,22 @@
+-- versions of each vehicle type's rate card; the one in effect is the latest
+-- effective_from that has passed, and a retired version withdraws the type
+CREATE TABLE IF NOT EXISTS vehicle_pricing (
+    id SERIAL PRIMARY KEY,
+    vehicle_type VARCHAR(32) NOT NULL,
+    version INTEGER NOT NULL,
+    base_price FLOAT NOT NULL,
+    price_per_km FLOAT NOT NULL,
+    price_per_minute FLOAT NOT NULL,
+    retired BOOLEAN NOT NULL DEFAULT FALSE,
+    effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (vehicle_type, version)
+);
+
+INSERT INTO vehicle_pricing (vehicle_type, version, base_price, price_per_km, price_per_minute, effective_from) VALUES
+    ('light_truck', 1, 20.0, 0.1117, 0.34, 'epoch'),
+    ('van', 1, 20.0, 0.1791, 0.34, 'epoch'),
+    ('truck', 1, 50.0, 0.2924, 0.5, 'epoch'),
+    ('heavy_truck', 1, 100.0, 0.3488, 0.6, 'epoch'),
+    ('trailer', 1, 200.0, 0.7859, 0.8, 'epoch')
+ON CONFLICT (vehicle_type, version) DO NOTHING;
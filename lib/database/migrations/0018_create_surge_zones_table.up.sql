@@ -0,0 +1,9 @@
+-- admin-drawn surge zones; polygon is a JSON array of {latitude, longitude}
+CREATE TABLE IF NOT EXISTS surge_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    polygon JSONB NOT NULL,
+    max_multiplier FLOAT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
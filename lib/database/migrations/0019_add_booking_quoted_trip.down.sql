@@ -0,0 +1,3 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS surge_amount;
+ALTER TABLE booking DROP COLUMN IF EXISTS quoted_duration;
+ALTER TABLE booking DROP COLUMN IF EXISTS quoted_distance;
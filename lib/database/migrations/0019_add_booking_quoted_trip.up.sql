@@ -0,0 +1,5 @@
+-- the routed trip and surge charge a booking was quoted with, which its
+-- invoice itemises; NULL for bookings quoted before they were kept
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS quoted_distance FLOAT;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS quoted_duration FLOAT;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS surge_amount FLOAT;
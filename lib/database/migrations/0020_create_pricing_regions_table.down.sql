@@ -0,0 +1,10 @@
+ALTER TABLE promotions DROP COLUMN IF EXISTS currency;
+ALTER TABLE booking DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE booking DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE booking DROP COLUMN IF EXISTS tax_name;
+ALTER TABLE booking DROP COLUMN IF EXISTS region_id;
+ALTER TABLE booking DROP COLUMN IF EXISTS currency;
+DROP INDEX IF EXISTS vehicle_pricing_region_version_idx;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS region_id;
+ALTER TABLE vehicle_pricing ADD CONSTRAINT vehicle_pricing_vehicle_type_version_key UNIQUE (vehicle_type, version);
+DROP TABLE IF EXISTS pricing_regions;
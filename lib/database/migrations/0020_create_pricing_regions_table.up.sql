@@ -0,0 +1,30 @@
+-- areas priced in their own currency and taxed under their own rules;
+-- polygon is a JSON array of {latitude, longitude}
+CREATE TABLE IF NOT EXISTS pricing_regions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    country VARCHAR(2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    polygon JSONB NOT NULL,
+    tax_name VARCHAR(16) NOT NULL DEFAULT 'Tax',
+    tax_rate FLOAT NOT NULL DEFAULT 0,
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- rate cards without a region apply wherever a region has none of its own
+-- and prices in the default currency
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS region_id INTEGER REFERENCES pricing_regions(id);
+ALTER TABLE vehicle_pricing DROP CONSTRAINT IF EXISTS vehicle_pricing_vehicle_type_version_key;
+CREATE UNIQUE INDEX IF NOT EXISTS vehicle_pricing_region_version_idx ON vehicle_pricing (COALESCE(region_id, 0), vehicle_type, version);
+
+-- the currency and tax rule a booking was priced under, for its invoice
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS region_id INTEGER;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_name VARCHAR(16);
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_rate FLOAT;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- flat discounts are only meaningful in one currency
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
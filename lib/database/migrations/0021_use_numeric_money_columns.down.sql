@@ -0,0 +1,26 @@
+ALTER TABLE organisations DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE organisations ALTER COLUMN approval_threshold TYPE FLOAT;
+ALTER TABLE organisations ALTER COLUMN monthly_spend_limit TYPE FLOAT;
+ALTER TABLE promotion_redemptions ALTER COLUMN discount TYPE FLOAT;
+ALTER TABLE payment_refunds ALTER COLUMN amount TYPE FLOAT;
+ALTER TABLE payments ALTER COLUMN refunded_amount TYPE FLOAT;
+ALTER TABLE payments ALTER COLUMN captured_amount TYPE FLOAT;
+ALTER TABLE payments ALTER COLUMN amount TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN amount TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN penalties TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN bonuses TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN adjustments TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN tips TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN commission TYPE FLOAT;
+ALTER TABLE payout_statements ALTER COLUMN trip_fares TYPE FLOAT;
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE FLOAT;
+ALTER TABLE invoices ALTER COLUMN total TYPE FLOAT;
+ALTER TABLE invoices ALTER COLUMN tax TYPE FLOAT;
+ALTER TABLE invoices ALTER COLUMN subtotal TYPE FLOAT;
+ALTER TABLE booking_adjustments ALTER COLUMN amount TYPE FLOAT;
+ALTER TABLE booking ALTER COLUMN surge_amount TYPE FLOAT;
+ALTER TABLE booking ALTER COLUMN shared_discount TYPE FLOAT;
+ALTER TABLE booking ALTER COLUMN adjustments_total TYPE FLOAT;
+ALTER TABLE booking ALTER COLUMN discount TYPE FLOAT;
+ALTER TABLE booking ALTER COLUMN price TYPE FLOAT;
@@ -0,0 +1,29 @@
+-- money is stored exactly, with room for every currency's minor unit and the
+-- four places money.Amount carries
+ALTER TABLE booking ALTER COLUMN price TYPE NUMERIC(19, 4);
+ALTER TABLE booking ALTER COLUMN discount TYPE NUMERIC(19, 4);
+ALTER TABLE booking ALTER COLUMN adjustments_total TYPE NUMERIC(19, 4);
+ALTER TABLE booking ALTER COLUMN shared_discount TYPE NUMERIC(19, 4);
+ALTER TABLE booking ALTER COLUMN surge_amount TYPE NUMERIC(19, 4);
+ALTER TABLE booking_adjustments ALTER COLUMN amount TYPE NUMERIC(19, 4);
+ALTER TABLE invoices ALTER COLUMN subtotal TYPE NUMERIC(19, 4);
+ALTER TABLE invoices ALTER COLUMN tax TYPE NUMERIC(19, 4);
+ALTER TABLE invoices ALTER COLUMN total TYPE NUMERIC(19, 4);
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN trip_fares TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN commission TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN tips TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN adjustments TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN bonuses TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN penalties TYPE NUMERIC(19, 4);
+ALTER TABLE payout_statements ALTER COLUMN amount TYPE NUMERIC(19, 4);
+ALTER TABLE payments ALTER COLUMN amount TYPE NUMERIC(19, 4);
+ALTER TABLE payments ALTER COLUMN captured_amount TYPE NUMERIC(19, 4);
+ALTER TABLE payments ALTER COLUMN refunded_amount TYPE NUMERIC(19, 4);
+ALTER TABLE payment_refunds ALTER COLUMN amount TYPE NUMERIC(19, 4);
+ALTER TABLE promotion_redemptions ALTER COLUMN discount TYPE NUMERIC(19, 4);
+ALTER TABLE organisations ALTER COLUMN monthly_spend_limit TYPE NUMERIC(19, 4);
+ALTER TABLE organisations ALTER COLUMN approval_threshold TYPE NUMERIC(19, 4);
+
+-- the currency an organisation's spend limit and approval threshold are set in
+ALTER TABLE organisations ADD COLUMN IF NOT EXISTS currency VARCHAR(3);
@@ -0,0 +1,11 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS loading_assistance;
+ALTER TABLE booking DROP COLUMN IF EXISTS hazardous;
+ALTER TABLE booking DROP COLUMN IF EXISTS fragile;
+ALTER TABLE booking DROP COLUMN IF EXISTS cargo_volume;
+ALTER TABLE booking DROP COLUMN IF EXISTS cargo_weight;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS loading_assistance_fee;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS hazardous_surcharge;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS fragile_surcharge;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS minimum_charges;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS price_per_cubic_metre;
+ALTER TABLE vehicle_pricing DROP COLUMN IF EXISTS price_per_kg;
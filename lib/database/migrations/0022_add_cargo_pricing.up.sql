@@ -0,0 +1,15 @@
+-- rate card components charged on the cargo; minimum_charges is a JSON array
+-- of {from_weight_kg, amount} tiers
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS price_per_kg FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS price_per_cubic_metre FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS minimum_charges JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS fragile_surcharge FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS hazardous_surcharge FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS loading_assistance_fee FLOAT NOT NULL DEFAULT 0;
+
+-- the cargo a booking was priced for, for its invoice
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS cargo_weight FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS cargo_volume FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS fragile BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS hazardous BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS loading_assistance BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+ALTER TABLE booking DROP COLUMN IF EXISTS experiment_variant;
+ALTER TABLE booking DROP COLUMN IF EXISTS experiment_id;
+DROP TABLE IF EXISTS pricing_experiment_exposures;
+DROP TABLE IF EXISTS pricing_experiments;
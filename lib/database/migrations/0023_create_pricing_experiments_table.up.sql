@@ -0,0 +1,29 @@
+-- variant rate cards tried out on a share of users; variants is a JSON array
+-- of {name, weight, base_price, price_per_km, ...} with rates left out
+-- keeping the rate card's
+CREATE TABLE IF NOT EXISTS pricing_experiments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    vehicle_type VARCHAR(32),
+    region_id INTEGER REFERENCES pricing_regions(id),
+    variants JSONB NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the users quoted under each experiment, which conversion is measured against
+CREATE TABLE IF NOT EXISTS pricing_experiment_exposures (
+    experiment_id INTEGER NOT NULL REFERENCES pricing_experiments(id),
+    user_id INTEGER NOT NULL,
+    variant VARCHAR(32) NOT NULL,
+    quotes INTEGER NOT NULL DEFAULT 1,
+    first_quoted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_quoted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (experiment_id, user_id)
+);
+
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS experiment_id INTEGER;
+ALTER TABLE booking ADD COLUMN IF NOT EXISTS experiment_variant VARCHAR(32);
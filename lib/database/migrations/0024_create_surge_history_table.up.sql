@@ -0,0 +1,12 @@
+-- every multiplier the surge engine computed, one row per zone per round
+CREATE TABLE IF NOT EXISTS surge_history (
+    id BIGSERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL REFERENCES surge_zones(id),
+    multiplier FLOAT NOT NULL,
+    open_requests INTEGER NOT NULL,
+    idle_drivers INTEGER NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS surge_history_zone_computed_at_idx ON surge_history (zone_id, computed_at);
+CREATE INDEX IF NOT EXISTS surge_history_computed_at_idx ON surge_history (computed_at);
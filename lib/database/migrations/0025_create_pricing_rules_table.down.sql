@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pricing_rules;
+ALTER TABLE pricing_regions DROP COLUMN IF EXISTS timezone;
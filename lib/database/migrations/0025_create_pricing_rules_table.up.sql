@@ -0,0 +1,26 @@
+-- the IANA time zone a region's pricing rules are evaluated in; empty is the
+-- default time zone
+ALTER TABLE pricing_regions ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+
+-- peak, weekend and holiday surge rules; days is a JSON array of weekdays
+-- (0 is Sunday) and dates a JSON array of YYYY-MM-DD, times are HH:MM in the
+-- region's time zone and a NULL region_id applies in every region
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id SERIAL PRIMARY KEY,
+    region_id INTEGER REFERENCES pricing_regions(id),
+    name VARCHAR(64) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    days JSONB NOT NULL DEFAULT '[]',
+    dates JSONB NOT NULL DEFAULT '[]',
+    start_time VARCHAR(5) NOT NULL DEFAULT '',
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    multiplier FLOAT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the peak hours surge was raised at before rules were configurable
+INSERT INTO pricing_rules (name, kind, start_time, end_time, multiplier) VALUES
+    ('Morning peak', 'peak', '07:00', '10:00', 1.2),
+    ('Evening peak', 'peak', '17:00', '20:00', 1.2);
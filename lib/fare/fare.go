@@ -0,0 +1,93 @@
+// Package fare applies a rate card to a trip. The pricing service quotes
+// with it and the booking service invoices with it, so an invoice splits a
+// fare into the same components it was quoted from.
+package fare
+
+import (
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+// SharedLoadDiscount is the share of the surged base price taken off a
+// request whose cargo is pooled with other shipments in one vehicle.
+const SharedLoadDiscount = 0.2
+
+// Components are the unsurged charges of a trip, each rounded to the
+// currency's minor unit.
+type Components struct {
+	BaseFare       money.Amount
+	DistanceCharge money.Amount
+	TimeCharge     money.Amount
+	WeightCharge   money.Amount
+	VolumeCharge   money.Amount
+	// MinimumChargeAdjustment tops the other components up to the minimum
+	// charge of the cargo's weight tier.
+	MinimumChargeAdjustment money.Amount
+}
+
+// Total is the trip's base price, which surge is applied to.
+func (c Components) Total() money.Amount {
+	return c.BaseFare + c.DistanceCharge + c.TimeCharge + c.WeightCharge + c.VolumeCharge + c.MinimumChargeAdjustment
+}
+
+// Surcharges are charged for how the cargo is handled. They are added after
+// surge and are not surged themselves.
+type Surcharges struct {
+	Fragile           money.Amount
+	Hazardous         money.Amount
+	LoadingAssistance money.Amount
+}
+
+func (s Surcharges) Total() money.Amount {
+	return s.Fragile + s.Hazardous + s.LoadingAssistance
+}
+
+// Compute prices a trip of distance km and duration minutes carrying cargo
+// on a rate card, in currency.
+func Compute(vp models.VehiclePricing, distance, duration float64, cargo models.Cargo, currency string) (Components, Surcharges) {
+	components := Components{
+		BaseFare:       money.FromFloat(vp.BasePrice).Round(currency),
+		DistanceCharge: money.FromFloat(distance * vp.PricePerKm).Round(currency),
+		TimeCharge:     money.FromFloat(duration * vp.PricePerMinute).Round(currency),
+		WeightCharge:   money.FromFloat(cargo.Weight * vp.PricePerKg).Round(currency),
+		VolumeCharge:   money.FromFloat(cargo.Volume * vp.PricePerCubicMetre).Round(currency),
+	}
+	if minimum, ok := MinimumCharge(vp.MinimumCharges, cargo.Weight); ok {
+		if shortfall := money.FromFloat(minimum).Round(currency) - components.Total(); shortfall > 0 {
+			components.MinimumChargeAdjustment = shortfall
+		}
+	}
+
+	var surcharges Surcharges
+	if cargo.Fragile {
+		surcharges.Fragile = components.Total().Percent(vp.FragileSurcharge).Round(currency)
+	}
+	if cargo.Hazardous {
+		surcharges.Hazardous = components.Total().Percent(vp.HazardousSurcharge).Round(currency)
+	}
+	if cargo.LoadingAssistance {
+		surcharges.LoadingAssistance = money.FromFloat(vp.LoadingAssistanceFee).Round(currency)
+	}
+
+	return components, surcharges
+}
+
+// MinimumCharge returns the minimum of the heaviest tier weight reaches. It
+// is false when weight is below every tier.
+func MinimumCharge(tiers []models.MinimumCharge, weight float64) (float64, bool) {
+	var tier models.MinimumCharge
+	found := false
+	for _, t := range tiers {
+		if t.FromWeight <= weight && (!found || t.FromWeight > tier.FromWeight) {
+			tier, found = t, true
+		}
+	}
+	return tier.Amount, found
+}
+
+// SharedDiscount is the discount of a pooled request with a surged base
+// price of surged, in currency. A shared vehicle handles the load the same
+// way, so handling surcharges are not discounted.
+func SharedDiscount(surged money.Amount, currency string) money.Amount {
+	return surged.Mul(SharedLoadDiscount).Round(currency)
+}
@@ -0,0 +1,121 @@
+package fare
+
+import (
+	"testing"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+var rateCard = models.VehiclePricing{
+	Type:                 "van",
+	BasePrice:            10,
+	PricePerKm:           1.25,
+	PricePerMinute:       0.3,
+	PricePerKg:           0.02,
+	PricePerCubicMetre:   2,
+	MinimumCharges:       []models.MinimumCharge{{FromWeight: 0, Amount: 25}, {FromWeight: 500, Amount: 60}},
+	FragileSurcharge:     10,
+	HazardousSurcharge:   25,
+	LoadingAssistanceFee: 15,
+}
+
+func amount(t *testing.T, value string) money.Amount {
+	t.Helper()
+	a, err := money.Parse(value)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", value, err)
+	}
+	return a
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name           string
+		distance       float64
+		duration       float64
+		cargo          models.Cargo
+		currency       string
+		wantComponents Components
+		wantTotal      string
+		wantSurcharges string
+	}{
+		{
+			name:     "distance, time and cargo",
+			distance: 20, duration: 40,
+			cargo:          models.Cargo{Weight: 100, Volume: 1.5},
+			currency:       "EUR",
+			wantComponents: Components{BaseFare: 100000, DistanceCharge: 250000, TimeCharge: 120000, WeightCharge: 20000, VolumeCharge: 30000},
+			wantTotal:      "52",
+			wantSurcharges: "0",
+		},
+		{
+			name:     "topped up to the minimum charge",
+			distance: 2, duration: 5,
+			currency:       "EUR",
+			wantComponents: Components{BaseFare: 100000, DistanceCharge: 25000, TimeCharge: 15000, MinimumChargeAdjustment: 110000},
+			wantTotal:      "25",
+			wantSurcharges: "0",
+		},
+		{
+			name:     "heavier tier minimum",
+			distance: 2, duration: 5,
+			cargo:          models.Cargo{Weight: 500},
+			currency:       "EUR",
+			wantComponents: Components{BaseFare: 100000, DistanceCharge: 25000, TimeCharge: 15000, WeightCharge: 100000, MinimumChargeAdjustment: 360000},
+			wantTotal:      "60",
+			wantSurcharges: "0",
+		},
+		{
+			name:     "handling surcharges",
+			distance: 20, duration: 40,
+			cargo:          models.Cargo{Weight: 100, Volume: 1.5, Fragile: true, Hazardous: true, LoadingAssistance: true},
+			currency:       "EUR",
+			wantComponents: Components{BaseFare: 100000, DistanceCharge: 250000, TimeCharge: 120000, WeightCharge: 20000, VolumeCharge: 30000},
+			wantTotal:      "52",
+			wantSurcharges: "33.2",
+		},
+		{
+			name:     "rounded to the currency",
+			distance: 3.333, duration: 7.77,
+			currency:       "JPY",
+			wantComponents: Components{BaseFare: 100000, DistanceCharge: 40000, TimeCharge: 20000, MinimumChargeAdjustment: 90000},
+			wantTotal:      "25",
+			wantSurcharges: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			components, surcharges := Compute(rateCard, tt.distance, tt.duration, tt.cargo, tt.currency)
+			if components != tt.wantComponents {
+				t.Errorf("components = %+v, want %+v", components, tt.wantComponents)
+			}
+			if got := components.Total(); got != amount(t, tt.wantTotal) {
+				t.Errorf("total = %s, want %s", got, tt.wantTotal)
+			}
+			if got := surcharges.Total(); got != amount(t, tt.wantSurcharges) {
+				t.Errorf("surcharges = %+v, want a total of %s", surcharges, tt.wantSurcharges)
+			}
+		})
+	}
+}
+
+func TestMinimumCharge(t *testing.T) {
+	tiers := []models.MinimumCharge{{FromWeight: 500, Amount: 60}, {FromWeight: 100, Amount: 30}}
+	tests := []struct {
+		weight    float64
+		want      float64
+		wantFound bool
+	}{
+		{weight: 50, want: 0, wantFound: false},
+		{weight: 100, want: 30, wantFound: true},
+		{weight: 499, want: 30, wantFound: true},
+		{weight: 2000, want: 60, wantFound: true},
+	}
+	for _, tt := range tests {
+		got, found := MinimumCharge(tiers, tt.weight)
+		if got != tt.want || found != tt.wantFound {
+			t.Errorf("MinimumCharge(%v) = %v, %v, want %v, %v", tt.weight, got, found, tt.want, tt.wantFound)
+		}
+	}
+}
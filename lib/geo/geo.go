@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"math"
+
+	"logistics-platform/lib/models"
+)
+
+const earthRadius = 6371 // km
+
+// AverageSpeed is the speed assumed when no better trip duration is known.
+const AverageSpeed = 40.0 // km/h
+
+// Distance returns the great-circle distance in km between two points using
+// the haversine formula.
+func Distance(from, to models.GeoPoint) float64 {
+	lat1 := toRadians(from.Latitude)
+	lon1 := toRadians(from.Longitude)
+	lat2 := toRadians(to.Latitude)
+	lon2 := toRadians(to.Longitude)
+
+	dlat := lat2 - lat1
+	dlon := lon2 - lon1
+
+	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*
+			math.Sin(dlon/2)*math.Sin(dlon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return earthRadius * c
+}
+
+// EstimateDuration converts a distance in km into minutes at AverageSpeed.
+func EstimateDuration(distance float64) float64 {
+	return distance / AverageSpeed * 60
+}
+
+func toRadians(deg float64) float64 {
+	return deg * (math.Pi / 180)
+}
+
+// InPolygon reports whether point lies inside polygon, whose vertices are
+// given in order. Polygons are small enough that latitude and longitude can
+// be treated as plane coordinates.
+func InPolygon(point models.GeoPoint, polygon []models.GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Latitude > point.Latitude) != (b.Latitude > point.Latitude) &&
+			point.Longitude < (b.Longitude-a.Longitude)*(point.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// BoundingCircle returns a centre and radius in km that enclose every point.
+func BoundingCircle(points []models.GeoPoint) (models.GeoPoint, float64) {
+	var centre models.GeoPoint
+	if len(points) == 0 {
+		return centre, 0
+	}
+
+	for _, p := range points {
+		centre.Latitude += p.Latitude
+		centre.Longitude += p.Longitude
+	}
+	centre.Latitude /= float64(len(points))
+	centre.Longitude /= float64(len(points))
+
+	var radius float64
+	for _, p := range points {
+		radius = math.Max(radius, Distance(centre, p))
+	}
+	return centre, radius
+}
@@ -1,6 +1,8 @@
 package kafka
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -27,3 +29,29 @@ func InitKafkaReader(topic, groupID string) *kafka.Reader {
 		CommitInterval: time.Second,
 	})
 }
+
+// InitBroadcastReader reads a topic every instance of a service needs in
+// full, such as messages for sockets that may be held by any instance. Each
+// instance gets its own consumer group, named after groupPrefix and the host,
+// and a new group starts from the latest message rather than replaying the
+// topic.
+func InitBroadcastReader(topic, groupPrefix string) *kafka.Reader {
+	brokers := viper.GetStringSlice("KAFKA_ADDR")
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        brokers,
+		Topic:          topic,
+		GroupID:        instanceGroupID(groupPrefix),
+		StartOffset:    kafka.LastOffset,
+		MinBytes:       10e3,
+		MaxBytes:       10e6,
+		CommitInterval: time.Second,
+	})
+}
+
+func instanceGroupID(prefix string) string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return fmt.Sprintf("%s_%d", prefix, os.Getpid())
+	}
+	return prefix + "_" + host
+}
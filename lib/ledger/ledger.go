@@ -0,0 +1,186 @@
+// Package ledger keeps the double-entry record of driver earnings. Every
+// transaction moves money between accounts and its entries always sum to
+// zero; a driver's balance is the sum of their entries on AccountDriver.
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+)
+
+// Accounts money moves between. Only AccountDriver is kept per driver.
+const (
+	// AccountCustomer is money collected from shippers.
+	AccountCustomer = "customer"
+	// AccountDriver is what the platform owes a driver.
+	AccountDriver = "driver"
+	// AccountCommission is the platform's revenue from trips.
+	AccountCommission = "commission"
+	// AccountIncentives funds bonuses and collects penalties.
+	AccountIncentives = "incentives"
+	// AccountPayouts is money sent to drivers' bank accounts.
+	AccountPayouts = "payouts"
+	// AccountPromotions funds promo code discounts.
+	AccountPromotions = "promotions"
+)
+
+// defaultCommissionRate is the platform's share of fares in percent, unless
+// EARNINGS_COMMISSION_RATE is set.
+const defaultCommissionRate = 20.0
+
+type Entry struct {
+	Account  string
+	DriverID int32
+	Kind     string
+	Amount   money.Amount
+}
+
+// Transaction is a balanced set of entries. Reference identifies what the
+// transaction records (a booking, an adjustment, a payout) and is unique, so
+// posting the same event twice has no effect.
+type Transaction struct {
+	Reference   string
+	BookingID   int32
+	StatementID int32
+	Description string
+	Entries     []Entry
+}
+
+// Post writes a transaction inside tx. It reports false when a transaction
+// with the same reference was posted before.
+func Post(ctx context.Context, tx pgx.Tx, txn Transaction) (bool, error) {
+	var sum money.Amount
+	for _, entry := range txn.Entries {
+		sum += entry.Amount
+	}
+	if sum != 0 {
+		return false, fmt.Errorf("ledger transaction %s is unbalanced by %s", txn.Reference, sum)
+	}
+
+	var transactionID int64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO ledger_transactions (reference, booking_id, description) VALUES ($1, $2, $3)
+		ON CONFLICT (reference) DO NOTHING RETURNING id`,
+		txn.Reference, nullableID(txn.BookingID), txn.Description).Scan(&transactionID)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error storing ledger transaction: %w", err)
+	}
+
+	for _, entry := range txn.Entries {
+		if entry.Amount == 0 {
+			continue
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO ledger_entries (transaction_id, account, driver_id, kind, amount, statement_id)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			transactionID, entry.Account, nullableID(entry.DriverID), entry.Kind, entry.Amount, nullableID(txn.StatementID))
+		if err != nil {
+			return false, fmt.Errorf("error storing ledger entry: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// CommissionRate returns the platform's share of fares and surcharges as a
+// fraction.
+func CommissionRate() float64 {
+	if viper.IsSet("EARNINGS_COMMISSION_RATE") {
+		return viper.GetFloat64("EARNINGS_COMMISSION_RATE") / 100
+	}
+	return defaultCommissionRate / 100
+}
+
+// TripEarnings credits the driver with the fare of a completed booking and
+// takes the platform's commission from it, both rounded to the booking's
+// currency. A promo code discount is paid by the platform, so the driver
+// earns on the fare before it.
+func TripEarnings(bookingID, driverID int32, price, discount money.Amount, currency string) Transaction {
+	price, discount = price.Round(currency), discount.Round(currency)
+	fare := price + discount
+	commission := fare.Mul(CommissionRate()).Round(currency)
+	return Transaction{
+		Reference:   fmt.Sprintf("booking:%d", bookingID),
+		BookingID:   bookingID,
+		Description: fmt.Sprintf("Trip fare for booking %d", bookingID),
+		Entries: []Entry{
+			{Account: AccountCustomer, Kind: models.EarningTripFare, Amount: -price},
+			{Account: AccountPromotions, Kind: models.EarningTripFare, Amount: -discount},
+			{Account: AccountDriver, DriverID: driverID, Kind: models.EarningTripFare, Amount: fare},
+			{Account: AccountDriver, DriverID: driverID, Kind: models.EarningCommission, Amount: -commission},
+			{Account: AccountCommission, Kind: models.EarningCommission, Amount: commission},
+		},
+	}
+}
+
+// AdjustmentEarnings credits the driver with an approved post-trip
+// adjustment. Tips go to the driver in full; surcharges carry commission like
+// the fare.
+func AdjustmentEarnings(adjustment models.BookingAdjustment, driverID int32, currency string) Transaction {
+	kind := models.EarningAdjustment
+	var commission money.Amount
+	if adjustment.Kind == models.AdjustmentTip {
+		kind = models.EarningTip
+	} else {
+		commission = adjustment.Amount.Mul(CommissionRate()).Round(currency)
+	}
+
+	return Transaction{
+		Reference:   fmt.Sprintf("adjustment:%d", adjustment.ID),
+		BookingID:   adjustment.BookingID,
+		Description: adjustment.Description,
+		Entries: []Entry{
+			{Account: AccountCustomer, Kind: kind, Amount: -adjustment.Amount},
+			{Account: AccountDriver, DriverID: driverID, Kind: kind, Amount: adjustment.Amount},
+			{Account: AccountDriver, DriverID: driverID, Kind: models.EarningCommission, Amount: -commission},
+			{Account: AccountCommission, Kind: models.EarningCommission, Amount: commission},
+		},
+	}
+}
+
+// Incentive credits a bonus to, or debits a penalty from, the driver.
+func Incentive(reference string, driverID, bookingID int32, kind, description string, amount money.Amount) Transaction {
+	if kind == models.EarningPenalty {
+		amount = -amount
+	}
+
+	return Transaction{
+		Reference:   reference,
+		BookingID:   bookingID,
+		Description: description,
+		Entries: []Entry{
+			{Account: AccountIncentives, Kind: kind, Amount: -amount},
+			{Account: AccountDriver, DriverID: driverID, Kind: kind, Amount: amount},
+		},
+	}
+}
+
+// Payout records that a statement's amount was paid to the driver. The
+// entries belong to the statement they settle, so they never show up on the
+// next one.
+func Payout(statementID, driverID int32, amount money.Amount) Transaction {
+	return Transaction{
+		Reference:   fmt.Sprintf("statement:%d", statementID),
+		StatementID: statementID,
+		Description: fmt.Sprintf("Payout of statement %d", statementID),
+		Entries: []Entry{
+			{Account: AccountDriver, DriverID: driverID, Kind: models.EarningPayout, Amount: -amount},
+			{Account: AccountPayouts, Kind: models.EarningPayout, Amount: amount},
+		},
+	}
+}
+
+func nullableID(id int32) *int32 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,102 @@
+package ledger
+
+import (
+	"testing"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+func TestTripEarnings(t *testing.T) {
+	tests := []struct {
+		name           string
+		price          float64
+		discount       float64
+		currency       string
+		wantFare       float64
+		wantCommission float64
+	}{
+		{name: "no discount", price: 50, currency: "EUR", wantFare: 50, wantCommission: 10},
+		{name: "discount paid by the platform", price: 45, discount: 5, currency: "EUR", wantFare: 50, wantCommission: 10},
+		{name: "commission rounded to the currency", price: 33.33, currency: "EUR", wantFare: 33.33, wantCommission: 6.67},
+		{name: "price rounded before the split", price: 12.345, discount: 0.004, currency: "EUR", wantFare: 12.35, wantCommission: 2.47},
+		{name: "currency without minor units", price: 1234, discount: 101, currency: "JPY", wantFare: 1335, wantCommission: 267},
+		{name: "fully discounted", discount: 20, currency: "EUR", wantFare: 20, wantCommission: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := TripEarnings(7, 3, money.FromFloat(tt.price), money.FromFloat(tt.discount), tt.currency)
+
+			var sum, driverBalance, fare, commission money.Amount
+			for _, entry := range txn.Entries {
+				sum += entry.Amount
+				if entry.Account == AccountDriver {
+					if entry.DriverID != 3 {
+						t.Errorf("driver entry for driver %d, want 3", entry.DriverID)
+					}
+					driverBalance += entry.Amount
+					if entry.Kind == models.EarningTripFare {
+						fare += entry.Amount
+					}
+				}
+				if entry.Account == AccountCommission {
+					commission += entry.Amount
+				}
+			}
+
+			if sum != 0 {
+				t.Errorf("entries sum to %s, want 0", sum)
+			}
+			if want := money.FromFloat(tt.wantFare); fare != want {
+				t.Errorf("driver fare = %s, want %s", fare, want)
+			}
+			if want := money.FromFloat(tt.wantCommission); commission != want {
+				t.Errorf("commission = %s, want %s", commission, want)
+			}
+			if want := money.FromFloat(tt.wantFare - tt.wantCommission); driverBalance != want {
+				t.Errorf("driver balance = %s, want %s", driverBalance, want)
+			}
+			if txn.Reference != "booking:7" || txn.BookingID != 7 {
+				t.Errorf("reference = %q, booking = %d, want booking:7, 7", txn.Reference, txn.BookingID)
+			}
+		})
+	}
+}
+
+func TestAdjustmentEarnings(t *testing.T) {
+	tests := []struct {
+		name           string
+		kind           string
+		amount         float64
+		currency       string
+		wantCommission float64
+	}{
+		{name: "tip goes to the driver in full", kind: models.AdjustmentTip, amount: 5, currency: "EUR", wantCommission: 0},
+		{name: "surcharge carries commission", kind: models.AdjustmentWaitingTime, amount: 12.5, currency: "EUR", wantCommission: 2.5},
+		{name: "commission rounded to the currency", kind: models.AdjustmentExtraStop, amount: 333, currency: "JPY", wantCommission: 67},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjustment := models.BookingAdjustment{ID: 11, BookingID: 7, Kind: tt.kind, Amount: money.FromFloat(tt.amount)}
+			txn := AdjustmentEarnings(adjustment, 3, tt.currency)
+
+			var sum, commission money.Amount
+			for _, entry := range txn.Entries {
+				sum += entry.Amount
+				if entry.Account == AccountCommission {
+					commission += entry.Amount
+				}
+			}
+
+			if sum != 0 {
+				t.Errorf("entries sum to %s, want 0", sum)
+			}
+			if want := money.FromFloat(tt.wantCommission); commission != want {
+				t.Errorf("commission = %s, want %s", commission, want)
+			}
+			if txn.Reference != "adjustment:11" {
+				t.Errorf("reference = %q, want adjustment:11", txn.Reference)
+			}
+		})
+	}
+}
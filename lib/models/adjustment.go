@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// Kinds of post-trip adjustment. Tips come from the user; the rest are
+// reported by the driver.
+const (
+	AdjustmentTip         = "tip"
+	AdjustmentWaitingTime = "waiting_time"
+	AdjustmentExtraStop   = "extra_stop"
+	AdjustmentLoading     = "loading"
+	AdjustmentUnloading   = "unloading"
+)
+
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApproved = "approved"
+	AdjustmentRejected = "rejected"
+)
+
+// BookingAdjustment is a charge added to a booking after it completed. Only
+// approved adjustments count towards invoices, driver earnings and revenue.
+type BookingAdjustment struct {
+	ID          int32        `json:"id"`
+	BookingID   int32        `json:"booking_id"`
+	Kind        string       `json:"kind"`
+	Description string       `json:"description"`
+	Quantity    float64      `json:"quantity,omitempty"`
+	Unit        string       `json:"unit,omitempty"`
+	Amount      money.Amount `json:"amount"`
+	Status      string       `json:"status"`
+	RequestedBy string       `json:"requested_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+	DecidedAt   *time.Time   `json:"decided_at,omitempty"`
+}
+
+// AdjustmentRequest is a driver-reported adjustment. Quantity is the waiting
+// time in minutes or the number of extra stops; the amount comes from the
+// platform's rates.
+type AdjustmentRequest struct {
+	Kind        string  `json:"kind" binding:"required,oneof=waiting_time extra_stop loading unloading"`
+	Quantity    float64 `json:"quantity"`
+	Description string  `json:"description"`
+}
+
+type TipRequest struct {
+	Amount money.Amount `json:"amount" binding:"required,gt=0"`
+}
+
+type AdjustmentDecision struct {
+	Approve bool `json:"approve"`
+}
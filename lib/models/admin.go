@@ -12,6 +12,9 @@ type DriverPerformance struct {
 	TripCount    int     `json:"tripCount"`
 	AvgTripTime  float64 `json:"avgTripTime"`
 	TotalRevenue float64 `json:"totalRevenue"`
+	AvgRating    float64 `json:"avgRating"`
+	RatingCount  int     `json:"ratingCount"`
+	Earnings     float64 `json:"earnings"`
 }
 
 type BookingAnalytics struct {
@@ -21,3 +24,17 @@ type BookingAnalytics struct {
 	AvgTripTime       float64 `json:"avgTripTime"`
 	TotalRevenue      float64 `json:"totalRevenue"`
 }
+
+// DriverAcceptance summarises how drivers respond to offers. Offers left
+// unanswered until they expired count as ignored; offers another driver took
+// first are not counted.
+type DriverAcceptance struct {
+	DriverID       int            `json:"driverID"`
+	Name           string         `json:"name"`
+	Offers         int            `json:"offers"`
+	Accepted       int            `json:"accepted"`
+	Declined       int            `json:"declined"`
+	Ignored        int            `json:"ignored"`
+	AcceptanceRate float64        `json:"acceptanceRate"`
+	DeclineReasons map[string]int `json:"declineReasons"`
+}
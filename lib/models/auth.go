@@ -21,18 +21,20 @@ type Admin struct {
 }
 
 type User struct {
-	ID       int32  `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	ID       int32          `json:"id"`
+	Name     string         `json:"name"`
+	Email    string         `json:"email"`
+	Password string         `json:"password"`
+	Rating   *RatingSummary `json:"rating,omitempty"`
 }
 
 type VehicleDriver struct {
-	ID            int32  `json:"id"`
-	Name          string `json:"name"`
-	VehicleID     string `json:"vehicleID"`
-	Email         string `json:"email"`
-	Password      string `json:"password"`
-	VehicleType   string `json:"vehicleType"`
-	VehicleVolume string `json:"vehicleVolume"`
+	ID            int32          `json:"id"`
+	Name          string         `json:"name"`
+	VehicleID     string         `json:"vehicleID"`
+	Email         string         `json:"email"`
+	Password      string         `json:"password"`
+	VehicleType   string         `json:"vehicleType"`
+	VehicleVolume string         `json:"vehicleVolume"`
+	Rating        *RatingSummary `json:"rating,omitempty"`
 }
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
 
 type BookingConfirmation struct {
 	BookingReq BookingRequest `json:"booking_request"`
@@ -9,26 +13,87 @@ type BookingConfirmation struct {
 }
 
 type Booking struct {
-	ID          int32     `json:"id"`
-	UserID      int32     `json:"user_id"`
-	UserName    string    `json:"user_name,omitempty"`
-	DriverID    int32     `json:"driver_id"`
-	DriverName  string    `json:"driver_name,omitempty"`
-	Price       float64   `json:"price"`
-	Pickup      GeoPoint  `json:"pickup"`
-	Dropoff     GeoPoint  `json:"dropoff"`
-	BookedAt    time.Time `json:"created_at"`
-	CompletedAt time.Time `json:"completed_at"`
-	Status      string    `json:"status"`
+	ID               int32        `json:"id"`
+	UserID           int32        `json:"user_id"`
+	UserName         string       `json:"user_name,omitempty"`
+	DriverID         int32        `json:"driver_id"`
+	DriverName       string       `json:"driver_name,omitempty"`
+	Price            money.Amount `json:"price"`
+	Currency         string       `json:"currency,omitempty"`
+	AdjustmentsTotal money.Amount `json:"adjustments_total"`
+	Pickup           GeoPoint     `json:"pickup"`
+	Dropoff          GeoPoint     `json:"dropoff"`
+	BookedAt         time.Time    `json:"created_at"`
+	CompletedAt      time.Time    `json:"completed_at"`
+	Status           string       `json:"status"`
+	PaymentStatus    string       `json:"payment_status,omitempty"`
 }
 
 type BookingRequest struct {
-	UserID      string    `json:"user_id" bson:"user_id"`
-	UserName    string    `json:"user_name" bson:"user_name"`
-	Pickup      GeoPoint  `json:"pickup" bson:"pickup"`
-	Dropoff     GeoPoint  `json:"dropoff" bson:"dropoff"`
-	VehicleType string    `json:"vehicle_type" bson:"vehicle_type"`
-	Price       float64   `json:"price" bson:"price"`
-	MongoID     string    `json:"mongo_id,omitempty" bson:"mongo_id,omitempty"`
-	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	UserID         string       `json:"user_id" bson:"user_id"`
+	UserName       string       `json:"user_name" bson:"user_name"`
+	Pickup         GeoPoint     `json:"pickup" bson:"pickup"`
+	Dropoff        GeoPoint     `json:"dropoff" bson:"dropoff"`
+	VehicleType    string       `json:"vehicle_type" bson:"vehicle_type"`
+	Price          money.Amount `json:"price" bson:"price"`
+	Currency       string       `json:"currency,omitempty" bson:"currency,omitempty"`
+	MongoID        string       `json:"mongo_id,omitempty" bson:"mongo_id,omitempty"`
+	CreatedAt      time.Time    `json:"created_at" bson:"created_at"`
+	CostCentre     string       `json:"cost_centre,omitempty" bson:"cost_centre,omitempty"`
+	OrganisationID int32        `json:"organisation_id,omitempty" bson:"organisation_id,omitempty"`
+	CostCentreID   int32        `json:"cost_centre_id,omitempty" bson:"cost_centre_id,omitempty"`
+	PickupAt       *time.Time   `json:"pickup_at,omitempty" bson:"pickup_at,omitempty"`
+	AllowShared    bool         `json:"allow_shared,omitempty" bson:"allow_shared,omitempty"`
+	Cargo          `bson:",inline"`
+	SharedPoolID   string `json:"shared_pool_id,omitempty" bson:"shared_pool_id,omitempty"`
+	Stops          []Stop `json:"stops,omitempty" bson:"stops,omitempty"`
+	PromoCode      string `json:"promo_code,omitempty" bson:"promo_code,omitempty"`
+	// RecurringBookingID links a request generated from a standing route back
+	// to its template; PreferredDriverID is offered the request in the first
+	// wave along with the shipper's favourite drivers. Both are only ever set
+	// by the service, never from a request body.
+	RecurringBookingID int32  `json:"-" bson:"recurring_booking_id,omitempty"`
+	PreferredDriverID  string `json:"-" bson:"preferred_driver_id,omitempty"`
+	// PromotionID and Discount are the promo code's quoted discount, already
+	// taken off Price. It is redeemed when a driver accepts the request.
+	PromotionID int32        `json:"-" bson:"promotion_id,omitempty"`
+	Discount    money.Amount `json:"-" bson:"discount,omitempty"`
+	// RegionID and the tax rule are those the request was priced under,
+	// kept on the booking for its invoice.
+	RegionID     int32   `json:"-" bson:"region_id,omitempty"`
+	TaxName      string  `json:"-" bson:"tax_name,omitempty"`
+	TaxRate      float64 `json:"-" bson:"tax_rate,omitempty"`
+	TaxInclusive bool    `json:"-" bson:"tax_inclusive,omitempty"`
+	// ExperimentID and ExperimentVariant are the pricing experiment variant
+	// the request was quoted under, if any.
+	ExperimentID      int32  `json:"-" bson:"experiment_id,omitempty"`
+	ExperimentVariant string `json:"-" bson:"experiment_variant,omitempty"`
+	// QuotedDistance (km), QuotedDuration (minutes) and SurgeAmount are the
+	// routed trip and surge charge of the quote, which the invoice itemises.
+	QuotedDistance float64      `json:"-" bson:"quoted_distance,omitempty"`
+	QuotedDuration float64      `json:"-" bson:"quoted_duration,omitempty"`
+	SurgeAmount    money.Amount `json:"-" bson:"surge_amount,omitempty"`
+	// SharedDiscount is quoted for requests that allow shared loads. It is
+	// taken off Price only when the request's pool is dispatched with other
+	// members.
+	SharedDiscount money.Amount `json:"-" bson:"shared_discount,omitempty"`
+}
+
+// Stop is one leg end of a shared trip. A pooled offer lists every member's
+// pickup and dropoff in the order the driver should visit them.
+type Stop struct {
+	Type     string   `json:"type" bson:"type"`
+	UserID   string   `json:"user_id" bson:"user_id"`
+	UserName string   `json:"user_name" bson:"user_name"`
+	Location GeoPoint `json:"location" bson:"location"`
+}
+
+type SharedPool struct {
+	ID          string           `json:"id" bson:"-"`
+	VehicleType string           `json:"vehicle_type" bson:"vehicle_type"`
+	Status      string           `json:"status" bson:"status"`
+	Members     []BookingRequest `json:"members" bson:"members"`
+	TotalVolume float64          `json:"total_volume" bson:"total_volume"`
+	CreatedAt   time.Time        `json:"created_at" bson:"created_at"`
+	DispatchAt  time.Time        `json:"dispatch_at" bson:"dispatch_at"`
 }
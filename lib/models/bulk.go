@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Bulk row statuses. Rows start as "invalid" or "queued" when the upload is
+// validated and queued rows move to one of the remaining states once the job
+// has processed them.
+const (
+	BulkRowInvalid          = "invalid"
+	BulkRowQueued           = "queued"
+	BulkRowSubmitted        = "submitted"
+	BulkRowScheduled        = "scheduled"
+	BulkRowAwaitingApproval = "awaiting_approval"
+	BulkRowFailed           = "failed"
+)
+
+type BulkRowResult struct {
+	Row    int    `json:"row"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkBookingJob struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Status    string          `json:"status"`
+	Total     int             `json:"total"`
+	Queued    int             `json:"queued"`
+	Invalid   int             `json:"invalid"`
+	Rows      []BulkRowResult `json:"rows"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Chat message types sent over the user and driver WebSockets. A client sends
+// chat_message and chat_read; the service relays both to the other party and
+// sends chat_closed to both once the booking completes or is cancelled.
+const (
+	ChatTypeMessage = "chat_message"
+	ChatTypeRead    = "chat_read"
+	ChatTypeClosed  = "chat_closed"
+)
+
+const (
+	ChatRoleUser   = "user"
+	ChatRoleDriver = "driver"
+)
+
+// ChatMessage is one message in the chat of an active booking. ClientID is
+// chosen by the sender's client and echoed back so it can match the stored
+// message to the one it displayed optimistically.
+type ChatMessage struct {
+	Type        string     `json:"type"`
+	ID          int32      `json:"id,omitempty"`
+	BookingID   int32      `json:"booking_id"`
+	SenderRole  string     `json:"sender_role,omitempty"`
+	SenderID    int32      `json:"sender_id,omitempty"`
+	RecipientID int32      `json:"recipient_id,omitempty"`
+	Body        string     `json:"body"`
+	ClientID    string     `json:"client_id,omitempty"`
+	SentAt      time.Time  `json:"sent_at"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+}
+
+// ChatReceipt tells a sender which of their messages the other party has read,
+// or, with type chat_closed, that the booking's chat has ended.
+type ChatReceipt struct {
+	Type       string    `json:"type"`
+	BookingID  int32     `json:"booking_id"`
+	ReaderRole string    `json:"reader_role,omitempty"`
+	MessageIDs []int32   `json:"message_ids,omitempty"`
+	At         time.Time `json:"at"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// Kinds of driver ledger entries.
+const (
+	EarningTripFare   = "trip_fare"
+	EarningCommission = "commission"
+	EarningTip        = "tip"
+	EarningAdjustment = "adjustment"
+	EarningBonus      = "bonus"
+	EarningPenalty    = "penalty"
+	EarningPayout     = "payout"
+)
+
+const (
+	PayoutPending = "pending"
+	PayoutSettled = "settled"
+)
+
+// LedgerEntry is one line of a driver's earnings account. Credits (money owed
+// to the driver) are positive, debits negative.
+type LedgerEntry struct {
+	ID          int64        `json:"id"`
+	Kind        string       `json:"kind"`
+	Amount      money.Amount `json:"amount"`
+	BookingID   int32        `json:"booking_id,omitempty"`
+	StatementID int32        `json:"statement_id,omitempty"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type DriverBalance struct {
+	DriverID int32 `json:"driver_id"`
+	// Balance is everything the platform owes the driver right now.
+	Balance money.Amount `json:"balance"`
+	// PendingPayout is the part of the balance on statements awaiting payment.
+	PendingPayout money.Amount `json:"pending_payout"`
+	// Unstatemented is earned since the last statement.
+	Unstatemented money.Amount  `json:"unstatemented"`
+	RecentEntries []LedgerEntry `json:"recent_entries"`
+}
+
+// PayoutStatement totals a driver's ledger entries for a week. Amount is what
+// is paid out when the statement is settled.
+type PayoutStatement struct {
+	ID                  int32         `json:"id"`
+	DriverID            int32         `json:"driver_id"`
+	DriverName          string        `json:"driver_name,omitempty"`
+	PeriodStart         string        `json:"period_start"`
+	PeriodEnd           string        `json:"period_end"`
+	TripFares           money.Amount  `json:"trip_fares"`
+	Commission          money.Amount  `json:"commission"`
+	Tips                money.Amount  `json:"tips"`
+	Adjustments         money.Amount  `json:"adjustments"`
+	Bonuses             money.Amount  `json:"bonuses"`
+	Penalties           money.Amount  `json:"penalties"`
+	Amount              money.Amount  `json:"amount"`
+	Status              string        `json:"status"`
+	SettledAt           *time.Time    `json:"settled_at,omitempty"`
+	SettlementReference string        `json:"settlement_reference,omitempty"`
+	CreatedAt           time.Time     `json:"created_at"`
+	Entries             []LedgerEntry `json:"entries,omitempty"`
+}
+
+// EarningAdjustmentRequest is an admin-issued bonus or penalty.
+type EarningAdjustmentRequest struct {
+	Kind        string       `json:"kind" binding:"required,oneof=bonus penalty"`
+	Amount      money.Amount `json:"amount" binding:"required,gt=0"`
+	Description string       `json:"description" binding:"required"`
+	BookingID   int32        `json:"booking_id"`
+	// Reference makes the request idempotent when the caller retries it.
+	Reference string `json:"reference"`
+}
+
+type PayoutSettlement struct {
+	Reference string `json:"reference" binding:"required"`
+}
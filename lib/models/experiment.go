@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// PricingExperiment tries out variant rate cards on a share of users. Each
+// signed-in user is assigned one variant for the life of the experiment by
+// hashing their user id, with chances in proportion to the variants'
+// weights. An empty VehicleType applies to every vehicle and a zero RegionID
+// to every pickup location. Where experiments overlap the one with the
+// lowest ID applies.
+type PricingExperiment struct {
+	ID          int32               `json:"id"`
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	VehicleType string              `json:"vehicle_type,omitempty"`
+	RegionID    int32               `json:"region_id,omitempty"`
+	Variants    []ExperimentVariant `json:"variants" binding:"required,min=2,dive"`
+	StartsAt    time.Time           `json:"starts_at"`
+	EndsAt      *time.Time          `json:"ends_at,omitempty"`
+	Active      bool                `json:"active"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// ExperimentVariant replaces some of a rate card's rates for the users
+// assigned to it; rates left out keep the rate card's. A variant without
+// any, such as a control, is priced as everyone else.
+type ExperimentVariant struct {
+	Name               string   `json:"name" binding:"required,max=32"`
+	Weight             int      `json:"weight" binding:"gte=1"`
+	BasePrice          *float64 `json:"base_price,omitempty" binding:"omitempty,gte=0"`
+	PricePerKm         *float64 `json:"price_per_km,omitempty" binding:"omitempty,gte=0"`
+	PricePerMinute     *float64 `json:"price_per_minute,omitempty" binding:"omitempty,gte=0"`
+	PricePerKg         *float64 `json:"price_per_kg,omitempty" binding:"omitempty,gte=0"`
+	PricePerCubicMetre *float64 `json:"price_per_cubic_metre,omitempty" binding:"omitempty,gte=0"`
+}
+
+// Apply returns the rate card with the variant's rates.
+func (v ExperimentVariant) Apply(vp VehiclePricing) VehiclePricing {
+	for _, rate := range []struct {
+		override *float64
+		field    *float64
+	}{
+		{v.BasePrice, &vp.BasePrice},
+		{v.PricePerKm, &vp.PricePerKm},
+		{v.PricePerMinute, &vp.PricePerMinute},
+		{v.PricePerKg, &vp.PricePerKg},
+		{v.PricePerCubicMetre, &vp.PricePerCubicMetre},
+	} {
+		if rate.override != nil {
+			*rate.field = *rate.override
+		}
+	}
+	return vp
+}
+
+// ExperimentVariantReport compares a variant's conversion and revenue. Users
+// are counted once however many quotes they asked for; Conversion is the
+// share of users quoted who booked. Revenue and AverageFare are per
+// currency, over bookings that were not cancelled.
+type ExperimentVariantReport struct {
+	Variant     string                  `json:"variant"`
+	UsersQuoted int                     `json:"users_quoted"`
+	Quotes      int                     `json:"quotes"`
+	UsersBooked int                     `json:"users_booked"`
+	Bookings    int                     `json:"bookings"`
+	Cancelled   int                     `json:"cancelled"`
+	Conversion  float64                 `json:"conversion"`
+	Revenue     map[string]money.Amount `json:"revenue"`
+	AverageFare map[string]money.Amount `json:"average_fare"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// Invoice line item kinds, in the order they appear on an invoice.
+const (
+	LineItemBase       = "base"
+	LineItemDistance   = "distance"
+	LineItemTime       = "time"
+	LineItemWeight     = "weight"
+	LineItemVolume     = "volume"
+	LineItemMinimum    = "minimum_charge"
+	LineItemSurge      = "surge"
+	LineItemSurcharge  = "surcharge"
+	LineItemFee        = "fee"
+	LineItemTip        = "tip"
+	LineItemAdjustment = "adjustment"
+	LineItemDiscount   = "discount"
+	LineItemTax        = "tax"
+	// LineItemTaxIncluded is tax already included in the other items' amounts,
+	// shown for the record; the subtotal is net of it.
+	LineItemTaxIncluded = "tax_included"
+)
+
+type InvoiceLineItem struct {
+	Kind        string       `json:"kind"`
+	Description string       `json:"description"`
+	Quantity    float64      `json:"quantity,omitempty"`
+	Unit        string       `json:"unit,omitempty"`
+	Amount      money.Amount `json:"amount"`
+}
+
+type Invoice struct {
+	ID            int32  `json:"id"`
+	InvoiceNumber string `json:"invoice_number"`
+	// OriginalInvoiceNumber is set on supplementary invoices, which bill the
+	// adjustments approved after the booking was invoiced. Issued invoices
+	// never change.
+	OriginalInvoiceNumber string            `json:"original_invoice_number,omitempty"`
+	BookingID             int32             `json:"booking_id"`
+	UserID                int32             `json:"user_id"`
+	UserName              string            `json:"user_name,omitempty"`
+	DriverID              int32             `json:"driver_id"`
+	DriverName            string            `json:"driver_name,omitempty"`
+	VehicleType           string            `json:"vehicle_type"`
+	Pickup                GeoPoint          `json:"pickup"`
+	Dropoff               GeoPoint          `json:"dropoff"`
+	Currency              string            `json:"currency,omitempty"`
+	LineItems             []InvoiceLineItem `json:"line_items"`
+	Subtotal              money.Amount      `json:"subtotal"`
+	Tax                   money.Amount      `json:"tax"`
+	Total                 money.Amount      `json:"total"`
+	IssuedAt              time.Time         `json:"issued_at"`
+}
@@ -1,18 +1,43 @@
 package models
 
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
 type BookingNotification struct {
-	UserID   string   `json:"user_id" bson:"user_id"`
-	DriverID string   `json:"driver_id" bson:"driver_id"`
-	Price    float64  `json:"price" bson:"price"`
-	Pickup   GeoPoint `json:"pickup" bson:"pickup"`
-	Dropoff  GeoPoint `json:"dropoff" bson:"dropoff"`
-	UserName string   `json:"user_name" bson:"user_name"`
-	MongoID  string   `json:"mongo_id" bson:"mongo_id"`
+	UserID   string       `json:"user_id" bson:"user_id"`
+	DriverID string       `json:"driver_id" bson:"driver_id"`
+	Price    money.Amount `json:"price" bson:"price"`
+	Currency string       `json:"currency,omitempty" bson:"currency,omitempty"`
+	Pickup   GeoPoint     `json:"pickup" bson:"pickup"`
+	Dropoff  GeoPoint     `json:"dropoff" bson:"dropoff"`
+	UserName string       `json:"user_name" bson:"user_name"`
+	MongoID  string       `json:"mongo_id" bson:"mongo_id"`
+	PickupAt *time.Time   `json:"pickup_at,omitempty" bson:"pickup_at,omitempty"`
+	Stops    []Stop       `json:"stops,omitempty" bson:"stops,omitempty"`
 }
 
 type BookedNotification struct {
-	UserID     string `json:"user_id"`
-	DriverID   string `json:"driver_id"`
-	DriverName string `json:"driver_name"`
-	Status     string `json:"status"`
+	UserID        string    `json:"user_id"`
+	DriverID      string    `json:"driver_id"`
+	DriverName    string    `json:"driver_name"`
+	Status        string    `json:"status"`
+	BookingID     int32     `json:"booking_id,omitempty"`
+	InvoiceNumber string    `json:"invoice_number,omitempty"`
+	Pickup        *GeoPoint `json:"pickup,omitempty"`
+	Dropoff       *GeoPoint `json:"dropoff,omitempty"`
+}
+
+// ETAUpdate is pushed to the user while a driver is on the way. Phase is
+// "pickup" until the goods are picked up and "dropoff" afterwards.
+type ETAUpdate struct {
+	Type       string    `json:"type"`
+	DriverID   string    `json:"driver_id"`
+	Phase      string    `json:"phase"`
+	ETAMinutes float64   `json:"eta_minutes"`
+	DistanceKm float64   `json:"distance_km"`
+	Provider   string    `json:"provider"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
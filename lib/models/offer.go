@@ -0,0 +1,44 @@
+package models
+
+// Messages a driver can send on the driver WebSocket in reply to a
+// BookingNotification. Any other message on the socket is a location update.
+const (
+	OfferActionAccept  = "accept_offer"
+	OfferActionDecline = "decline_offer"
+)
+
+// Reasons a driver can give for declining an offer.
+const (
+	DeclineReasonTooFar       = "too_far"
+	DeclineReasonPriceTooLow  = "price_too_low"
+	DeclineReasonWrongVehicle = "wrong_vehicle"
+	DeclineReasonBusy         = "busy"
+	DeclineReasonOther        = "other"
+)
+
+// OfferAction is a driver's reply to an offer, relayed from the notification
+// service to the booking service. RequestID is chosen by the driver's client
+// and echoed back on the acknowledgement.
+type OfferAction struct {
+	Type       string `json:"type"`
+	RequestID  string `json:"request_id,omitempty"`
+	MongoID    string `json:"mongo_id"`
+	Reason     string `json:"reason,omitempty"`
+	Note       string `json:"note,omitempty"`
+	DriverID   string `json:"driver_id"`
+	DriverName string `json:"driver_name"`
+}
+
+// OfferActionResult acknowledges an OfferAction back on the driver's socket.
+// Type is always "offer_ack".
+type OfferActionResult struct {
+	Type      string   `json:"type"`
+	RequestID string   `json:"request_id,omitempty"`
+	Action    string   `json:"action"`
+	MongoID   string   `json:"mongo_id"`
+	DriverID  string   `json:"driver_id"`
+	Success   bool     `json:"success"`
+	Error     string   `json:"error,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	Stops     []Stop   `json:"stops,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// Organisation member roles. Every member may book on the organisation's
+// account; approvers additionally review bookings above the approval
+// threshold and finance manages members, cost centres, limits and statements.
+const (
+	OrgRoleBooker   = "booker"
+	OrgRoleApprover = "approver"
+	OrgRoleFinance  = "finance"
+)
+
+type Organisation struct {
+	ID                int32         `json:"id"`
+	Name              string        `json:"name" binding:"required"`
+	MonthlySpendLimit *money.Amount `json:"monthly_spend_limit,omitempty"`
+	ApprovalThreshold *money.Amount `json:"approval_threshold,omitempty"`
+	// Currency is what the limit and threshold are set in; only bookings
+	// priced in it count towards them.
+	Currency  string    `json:"currency,omitempty" binding:"omitempty,len=3"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrganisationMember struct {
+	OrganisationID int32  `json:"organisation_id"`
+	UserID         int32  `json:"user_id" binding:"required"`
+	Role           string `json:"role" binding:"required,oneof=booker approver finance"`
+}
+
+type CostCentre struct {
+	ID             int32  `json:"id"`
+	OrganisationID int32  `json:"organisation_id"`
+	Code           string `json:"code" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+}
+
+type StatementLine struct {
+	BookingID     int32        `json:"booking_id"`
+	UserID        int32        `json:"user_id"`
+	UserName      string       `json:"user_name"`
+	CostCentre    string       `json:"cost_centre,omitempty"`
+	VehicleType   string       `json:"vehicle_type"`
+	Pickup        string       `json:"pickup"`
+	Dropoff       string       `json:"dropoff"`
+	Price         money.Amount `json:"price"`
+	InvoiceNumber string       `json:"invoice_number,omitempty"`
+	Status        string       `json:"status"`
+	BookedAt      time.Time    `json:"created_at"`
+}
+
+type OrganisationStatement struct {
+	OrganisationID   int32                   `json:"organisation_id"`
+	Month            string                  `json:"month"`
+	Lines            []StatementLine         `json:"lines"`
+	CostCentreTotals map[string]money.Amount `json:"cost_centre_totals"`
+	Total            money.Amount            `json:"total"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// Payment statuses, also mirrored on the booking's payment_status column.
+// Organisation bookings are paid through the monthly statement and are
+// marked PaymentOnAccount instead.
+const (
+	PaymentAuthorized        = "authorized"
+	PaymentCaptured          = "captured"
+	PaymentVoided            = "voided"
+	PaymentRefunded          = "refunded"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentFailed            = "failed"
+	PaymentExpired           = "expired"
+	PaymentOnAccount         = "on_account"
+)
+
+type Payment struct {
+	ID              int32        `json:"id"`
+	MongoID         string       `json:"mongo_id"`
+	BookingID       int32        `json:"booking_id,omitempty"`
+	UserID          int32        `json:"user_id"`
+	Provider        string       `json:"provider"`
+	AuthorizationID string       `json:"authorization_id"`
+	Amount          money.Amount `json:"amount"`
+	CapturedAmount  money.Amount `json:"captured_amount"`
+	RefundedAmount  money.Amount `json:"refunded_amount"`
+	Currency        string       `json:"currency"`
+	Status          string       `json:"status"`
+	FailureReason   string       `json:"failure_reason,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// RefundRequest refunds part of a captured payment, or all of what is left
+// of it when Amount is zero.
+type RefundRequest struct {
+	Amount money.Amount `json:"amount" binding:"gte=0"`
+	Reason string       `json:"reason" binding:"required"`
+}
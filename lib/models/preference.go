@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// A shipper can mark a driver as a favourite, who is offered their requests
+// first, or block them so they are never offered their requests.
+const (
+	DriverPreferenceFavourite = "favourite"
+	DriverPreferenceBlocked   = "blocked"
+)
+
+type DriverPreference struct {
+	UserID     int32     `json:"user_id"`
+	DriverID   int32     `json:"driver_id"`
+	DriverName string    `json:"driver_name,omitempty"`
+	Preference string    `json:"preference" binding:"required,oneof=favourite blocked"`
+	CreatedAt  time.Time `json:"created_at"`
+}
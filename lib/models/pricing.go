@@ -1,16 +1,115 @@
 package models
 
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+// InternalQuoteHeader marks an estimate the booking service requests to price
+// a booking, as opposed to one a user is shown.
+const InternalQuoteHeader = "X-Internal-Quote"
+
+// PriceEstimate is a quote with the working that led to it. Amounts are in
+// Currency, rounded to its minor unit, and add up: BaseFare, DistanceCharge,
+// TimeCharge, the cargo's WeightCharge and VolumeCharge and any
+// MinimumChargeAdjustment make BasePrice, to which SurgeAmount and the
+// unsurged handling surcharges are added and the promo code Discount is
+// taken off to give TotalPrice, the fare booked. PlatformFee and, unless
+// TaxInclusive, Tax are added on the invoice, making Payable.
+// SharedDiscount is what a request allowing shared loads has taken off its
+// fare if it is pooled with other requests; it is not in TotalPrice.
 type PriceEstimate struct {
-	BasePrice  float64
-	Distance   float64
-	Duration   float64
-	Surge      float64
-	TotalPrice float64
+	VehicleType string `json:"vehicle_type"`
+	Currency    string `json:"currency"`
+	// RegionID is the pricing region of the pickup, zero outside every
+	// region. RuleVersion is the version of its rate card applied.
+	RegionID    int32  `json:"region_id,omitempty"`
+	Region      string `json:"region,omitempty"`
+	RuleVersion int    `json:"rule_version"`
+	// ExperimentID and ExperimentVariant are the pricing experiment variant
+	// the user was assigned, whose rates replace the rate card's.
+	ExperimentID      int32  `json:"experiment_id,omitempty"`
+	ExperimentVariant string `json:"experiment_variant,omitempty"`
+	// Distance (km) and Duration (minutes) are of the routed trip;
+	// RoutingProvider names the provider that routed it.
+	Distance        float64 `json:"distance"`
+	Duration        float64 `json:"duration"`
+	RoutingProvider string  `json:"routing_provider"`
+
+	BaseFare       money.Amount `json:"base_fare"`
+	DistanceCharge money.Amount `json:"distance_charge"`
+	TimeCharge     money.Amount `json:"time_charge"`
+	WeightCharge   money.Amount `json:"weight_charge,omitempty"`
+	VolumeCharge   money.Amount `json:"volume_charge,omitempty"`
+	// MinimumChargeAdjustment tops a light load up to the minimum charge of
+	// its weight tier.
+	MinimumChargeAdjustment money.Amount `json:"minimum_charge_adjustment,omitempty"`
+	BasePrice               money.Amount `json:"base_price"`
+	Surge                   float64      `json:"surge_multiplier"`
+	// PricingRule names the peak, weekend or holiday rule included in Surge.
+	PricingRule          string       `json:"pricing_rule,omitempty"`
+	SurgeAmount          money.Amount `json:"surge_amount"`
+	FragileSurcharge     money.Amount `json:"fragile_surcharge,omitempty"`
+	HazardousSurcharge   money.Amount `json:"hazardous_surcharge,omitempty"`
+	LoadingAssistanceFee money.Amount `json:"loading_assistance_fee,omitempty"`
+	SharedDiscount       money.Amount `json:"shared_discount,omitempty"`
+	// Discount is the promo code applied to TotalPrice; PromoError says why
+	// a requested code was not applied.
+	Discount   *PromotionDiscount `json:"discount,omitempty"`
+	PromoError string             `json:"promo_error,omitempty"`
+	TotalPrice money.Amount       `json:"total_price"`
+
+	PlatformFee  money.Amount `json:"platform_fee,omitempty"`
+	TaxName      string       `json:"tax_name"`
+	TaxRate      float64      `json:"tax_rate"`
+	TaxInclusive bool         `json:"tax_inclusive"`
+	Tax          money.Amount `json:"tax"`
+	Payable      money.Amount `json:"payable"`
 }
 
+// VehiclePricing is one version of a vehicle type's rate card in a region.
+// Rate cards with no RegionID are in the default currency and also apply in
+// regions priced in it that have no card of their own. Versions are numbered
+// per region and type and take effect at EffectiveFrom; a Retired version
+// stops the type being offered from then on.
 type VehiclePricing struct {
-	Type           string
-	BasePrice      float64
-	PricePerKm     float64
-	PricePerMinute float64
+	ID             int32   `json:"id,omitempty"`
+	RegionID       int32   `json:"region_id,omitempty"`
+	Type           string  `json:"type" binding:"required"`
+	Version        int     `json:"version"`
+	BasePrice      float64 `json:"base_price" binding:"gte=0"`
+	PricePerKm     float64 `json:"price_per_km" binding:"gte=0"`
+	PricePerMinute float64 `json:"price_per_minute" binding:"gte=0"`
+	// PricePerKg and PricePerCubicMetre charge for the cargo carried.
+	PricePerKg         float64 `json:"price_per_kg" binding:"gte=0"`
+	PricePerCubicMetre float64 `json:"price_per_cubic_metre" binding:"gte=0"`
+	// MinimumCharges raise light loads to the minimum of the heaviest tier
+	// they reach, so a short trip still covers the vehicle.
+	MinimumCharges []MinimumCharge `json:"minimum_charges" binding:"dive"`
+	// FragileSurcharge and HazardousSurcharge are percentages of the fare;
+	// LoadingAssistanceFee is a flat fee for the driver helping to load.
+	FragileSurcharge     float64   `json:"fragile_surcharge" binding:"gte=0"`
+	HazardousSurcharge   float64   `json:"hazardous_surcharge" binding:"gte=0"`
+	LoadingAssistanceFee float64   `json:"loading_assistance_fee" binding:"gte=0"`
+	Retired              bool      `json:"retired,omitempty"`
+	EffectiveFrom        time.Time `json:"effective_from"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// MinimumCharge is the least a trip carrying at least FromWeight kg is
+// charged before surge and surcharges.
+type MinimumCharge struct {
+	FromWeight float64 `json:"from_weight_kg" binding:"gte=0"`
+	Amount     float64 `json:"amount" binding:"gte=0"`
+}
+
+// Cargo is what a shipper says they are sending, which cargo-based rate card
+// components are charged on.
+type Cargo struct {
+	Weight            float64 `json:"cargo_weight,omitempty" bson:"cargo_weight,omitempty" binding:"gte=0"`
+	Volume            float64 `json:"cargo_volume,omitempty" bson:"cargo_volume,omitempty" binding:"gte=0"`
+	Fragile           bool    `json:"fragile,omitempty" bson:"fragile,omitempty"`
+	Hazardous         bool    `json:"hazardous,omitempty" bson:"hazardous,omitempty"`
+	LoadingAssistance bool    `json:"loading_assistance,omitempty" bson:"loading_assistance,omitempty"`
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Kinds of pricing rule.
+const (
+	PricingRulePeak    = "peak"
+	PricingRuleWeekend = "weekend"
+	PricingRuleHoliday = "holiday"
+)
+
+// PricingRule multiplies the surge of trips picked up at certain local times
+// in a region, or in every region when RegionID is zero. A rule applies on
+// its Days of the week (0 is Sunday; empty is every day) or, for holidays,
+// on its Dates (YYYY-MM-DD), between StartTime and EndTime (HH:MM, end
+// exclusive; empty is the whole day). Times are in the pickup region's time
+// zone. Where rules overlap the one with the highest Priority applies, then
+// a region's own rule over a rule for every region, then the lowest ID.
+type PricingRule struct {
+	ID         int32     `json:"id"`
+	RegionID   int32     `json:"region_id,omitempty"`
+	Name       string    `json:"name" binding:"required,max=64"`
+	Kind       string    `json:"kind" binding:"required,oneof=peak weekend holiday"`
+	Days       []int     `json:"days" binding:"dive,gte=0,lte=6"`
+	Dates      []string  `json:"dates" binding:"dive,datetime=2006-01-02"`
+	StartTime  string    `json:"start_time,omitempty" binding:"omitempty,datetime=15:04"`
+	EndTime    string    `json:"end_time,omitempty" binding:"omitempty,datetime=15:04"`
+	Multiplier float64   `json:"multiplier" binding:"gte=1"`
+	Priority   int       `json:"priority"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Matches reports whether the rule applies at local, a time already in the
+// region's time zone.
+func (r PricingRule) Matches(local time.Time) bool {
+	if len(r.Dates) > 0 && !slices.Contains(r.Dates, local.Format("2006-01-02")) {
+		return false
+	}
+	if len(r.Days) > 0 && !slices.Contains(r.Days, int(local.Weekday())) {
+		return false
+	}
+	// HH:MM strings compare in time order
+	clock := local.Format("15:04")
+	if r.StartTime != "" && clock < r.StartTime {
+		return false
+	}
+	if r.EndTime != "" && clock >= r.EndTime {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"logistics-platform/lib/money"
+)
+
+const (
+	DiscountPercentage = "percentage"
+	DiscountFlat       = "flat"
+)
+
+// Promotion is a promo code, grouped under a campaign for reporting. Zero
+// values of the limits mean unlimited; an empty VehicleTypes applies to every
+// vehicle, a zero ZoneRadius to every pickup location and an empty Currency
+// to fares in any currency. Flat discounts and MaxDiscount are in Currency.
+type Promotion struct {
+	ID               int32      `json:"id"`
+	Code             string     `json:"code" binding:"required"`
+	Campaign         string     `json:"campaign"`
+	Description      string     `json:"description"`
+	DiscountType     string     `json:"discount_type" binding:"required,oneof=percentage flat"`
+	DiscountValue    float64    `json:"discount_value" binding:"required,gt=0"`
+	MaxDiscount      float64    `json:"max_discount" binding:"gte=0"`
+	FirstBookingOnly bool       `json:"first_booking_only"`
+	PerUserLimit     int        `json:"per_user_limit" binding:"gte=0"`
+	MaxRedemptions   int        `json:"max_redemptions" binding:"gte=0"`
+	RedemptionCount  int        `json:"redemption_count"`
+	VehicleTypes     []string   `json:"vehicle_types"`
+	Zone             GeoPoint   `json:"zone"`
+	ZoneRadius       float64    `json:"zone_radius_km" binding:"gte=0"`
+	Currency         string     `json:"currency,omitempty" binding:"omitempty,len=3"`
+	StartsAt         time.Time  `json:"starts_at"`
+	EndsAt           *time.Time `json:"ends_at,omitempty"`
+	Active           bool       `json:"active"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// PromotionDiscount is a promotion applied to a fare.
+type PromotionDiscount struct {
+	PromotionID int32        `json:"promotion_id"`
+	Code        string       `json:"code"`
+	Description string       `json:"description"`
+	Amount      money.Amount `json:"amount"`
+}
+
+type PromotionRedemption struct {
+	ID          int32        `json:"id"`
+	PromotionID int32        `json:"promotion_id"`
+	UserID      int32        `json:"user_id"`
+	BookingID   int32        `json:"booking_id"`
+	Discount    money.Amount `json:"discount"`
+	RedeemedAt  time.Time    `json:"redeemed_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Rater roles stored alongside each rating. A "user" rating is given by the
+// shipper to the driver, a "driver" rating by the driver to the shipper.
+const (
+	RaterRoleUser   = "user"
+	RaterRoleDriver = "driver"
+)
+
+type Rating struct {
+	ID        int32     `json:"id"`
+	BookingID int32     `json:"booking_id"`
+	RaterRole string    `json:"rater_role"`
+	RaterID   int32     `json:"rater_id"`
+	RateeID   int32     `json:"ratee_id"`
+	Rating    int       `json:"rating"`
+	Tags      []string  `json:"tags"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RatingRequest struct {
+	Rating  int      `json:"rating" binding:"required,min=1,max=5"`
+	Tags    []string `json:"tags"`
+	Comment string   `json:"comment"`
+}
+
+type RatingSummary struct {
+	AverageRating float64 `json:"average_rating"`
+	RatingCount   int     `json:"rating_count"`
+}
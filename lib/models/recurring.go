@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Recurrence frequencies. Weekly recurrences run on the days listed in ByDays
+// using RRULE day codes (MO, TU, WE, TH, FR, SA, SU).
+const (
+	FrequencyDaily    = "daily"
+	FrequencyWeekdays = "weekdays"
+	FrequencyWeekly   = "weekly"
+)
+
+// RecurringBooking is a standing route that is booked automatically. Dates are
+// formatted as YYYY-MM-DD and TimeOfDay as HH:MM, both in Timezone.
+type RecurringBooking struct {
+	ID               int32     `json:"id"`
+	UserID           int32     `json:"user_id"`
+	Pickup           GeoPoint  `json:"pickup" binding:"required"`
+	Dropoff          GeoPoint  `json:"dropoff" binding:"required"`
+	VehicleType      string    `json:"vehicle_type" binding:"required"`
+	Frequency        string    `json:"frequency" binding:"required,oneof=daily weekdays weekly"`
+	ByDays           []string  `json:"by_days"`
+	TimeOfDay        string    `json:"time_of_day" binding:"required"`
+	Timezone         string    `json:"timezone" binding:"required"`
+	StartsOn         string    `json:"starts_on" binding:"required"`
+	EndsOn           string    `json:"ends_on,omitempty"`
+	ExceptionDates   []string  `json:"exception_dates"`
+	PreferSameDriver bool      `json:"prefer_same_driver"`
+	LastDriverID     int32     `json:"last_driver_id,omitempty"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ScheduledBooking is a booking request waiting to be offered to drivers
+// shortly before pickup: an occurrence of a recurring booking, or a one-off
+// BookingRequest, such as a bulk import row, when RecurringBookingID is zero.
+type ScheduledBooking struct {
+	ID                 int32           `json:"id"`
+	RecurringBookingID int32           `json:"recurring_booking_id,omitempty"`
+	UserID             int32           `json:"user_id"`
+	PickupAt           time.Time       `json:"pickup_at"`
+	Status             string          `json:"status"`
+	Error              string          `json:"error,omitempty"`
+	BookingRequest     *BookingRequest `json:"-"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Region is an area priced in its own currency and taxed under its own rule.
+// A pickup outside every region is priced in the default currency. TaxRate
+// is a percentage; an inclusive tax is already part of the fare, an
+// exclusive one is added on top of it. Timezone is the IANA time zone its
+// pricing rules are evaluated in; empty uses the default time zone.
+type Region struct {
+	ID           int32      `json:"id"`
+	Name         string     `json:"name" binding:"required"`
+	Country      string     `json:"country" binding:"required,len=2"`
+	Currency     string     `json:"currency" binding:"required,len=3"`
+	Polygon      []GeoPoint `json:"polygon" binding:"required,min=3"`
+	TaxName      string     `json:"tax_name"`
+	TaxRate      float64    `json:"tax_rate" binding:"gte=0,lte=100"`
+	TaxInclusive bool       `json:"tax_inclusive"`
+	Timezone     string     `json:"timezone,omitempty"`
+	Active       bool       `json:"active"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// SurgeZone is an admin-drawn area whose surge multiplier is computed from its
+// own open requests and idle drivers. Where zones overlap the one with the
+// lowest ID applies. MaxMultiplier lowers SURGE_MAX_MULTIPLIER for the zone;
+// zero, or anything above it, leaves the configured maximum.
+type SurgeZone struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name" binding:"required"`
+	Polygon       []GeoPoint `json:"polygon" binding:"required,min=3"`
+	MaxMultiplier float64    `json:"max_multiplier" binding:"gte=0"`
+	Active        bool       `json:"active"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ZoneSurge is the surge multiplier last published for a zone, with the
+// supply and demand it was computed from.
+type ZoneSurge struct {
+	ZoneID       int32     `json:"zone_id"`
+	ZoneName     string    `json:"zone_name"`
+	Multiplier   float64   `json:"multiplier"`
+	OpenRequests int       `json:"open_requests"`
+	IdleDrivers  int       `json:"idle_drivers"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SurgeAtLocation is the surge a trip picked up at Location would be quoted
+// now. Zone is the state of the surge zone containing it, if any; Multiplier
+// is what the fare is multiplied by, including the pricing rule named by
+// PricingRule.
+type SurgeAtLocation struct {
+	Location    GeoPoint   `json:"location"`
+	Zone        *ZoneSurge `json:"zone,omitempty"`
+	Multiplier  float64    `json:"multiplier"`
+	PricingRule string     `json:"pricing_rule,omitempty"`
+}
+
+// SurgeHeatmap is pushed to every connected driver each time the surge
+// engine runs, so drivers can head for zones in demand. Type is always
+// "surge_heatmap".
+type SurgeHeatmap struct {
+	Type        string        `json:"type"`
+	Zones       []HeatmapZone `json:"zones"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// HeatmapZone is a surge zone's outline and its multiplier.
+type HeatmapZone struct {
+	ZoneID       int32      `json:"zone_id"`
+	ZoneName     string     `json:"zone_name"`
+	Polygon      []GeoPoint `json:"polygon"`
+	Centre       GeoPoint   `json:"centre"`
+	Multiplier   float64    `json:"multiplier"`
+	OpenRequests int        `json:"open_requests"`
+	IdleDrivers  int        `json:"idle_drivers"`
+}
@@ -0,0 +1,241 @@
+// Package money does exact arithmetic on prices. Amounts are fixed point, so
+// adding up line items never drifts by a fraction of a cent the way float64
+// sums do, and each currency is rounded to its own minor unit.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scale is how many fractional digits an Amount holds, enough for every
+// currency's minor unit and for rates applied to it.
+const scale = 4
+
+var unit = int64(math.Pow10(scale))
+
+// Amount is a sum of money in ten-thousandths of a currency unit. It is
+// written to JSON as a plain decimal number, to MongoDB as a Decimal128 and
+// to PostgreSQL as a number.
+type Amount int64
+
+// minorUnits are the currencies whose smallest unit is not a hundredth.
+var minorUnits = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// MinorUnits is how many decimal places currency is charged in.
+func MinorUnits(currency string) int {
+	if digits, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return digits
+	}
+	return 2
+}
+
+// FromFloat converts a float price, rounding to the nearest ten-thousandth.
+func FromFloat(value float64) Amount {
+	return Amount(math.Round(value * float64(unit)))
+}
+
+// Parse reads a decimal string such as "12.5" or "-0.0125" exactly.
+func Parse(value string) (Amount, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(fraction) > scale {
+		return 0, fmt.Errorf("invalid amount %q: more than %d decimal places", value, scale)
+	}
+
+	var units, fractionUnits int64
+	var err error
+	if whole != "" {
+		if units, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", value, err)
+		}
+	}
+	if fraction != "" {
+		if fractionUnits, err = strconv.ParseInt(fraction+strings.Repeat("0", scale-len(fraction)), 10, 64); err != nil || fractionUnits < 0 {
+			return 0, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+
+	amount := Amount(units*unit + fractionUnits)
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func (a Amount) Float64() float64 {
+	return float64(a) / float64(unit)
+}
+
+func (a Amount) String() string {
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	whole, fraction := int64(a)/unit, int64(a)%unit
+	if fraction == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	return sign + strconv.FormatInt(whole, 10) + "." + strings.TrimRight(fmt.Sprintf("%0*d", scale, fraction), "0")
+}
+
+// Format writes the amount rounded to currency with all of its decimal
+// places, such as "12.50", for documents rather than JSON.
+func (a Amount) Format(currency string) string {
+	digits := MinorUnits(currency)
+	a = a.Round(currency)
+	sign := ""
+	if a < 0 {
+		sign, a = "-", -a
+	}
+	whole, fraction := int64(a)/unit, int64(a)%unit
+	if digits == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	return sign + strconv.FormatInt(whole, 10) + "." + fmt.Sprintf("%0*d", scale, fraction)[:digits]
+}
+
+// Mul scales the amount by factor, such as a surge multiplier, rounding half
+// away from zero.
+func (a Amount) Mul(factor float64) Amount {
+	return Amount(math.Round(float64(a) * factor))
+}
+
+// Percent is rate percent of the amount.
+func (a Amount) Percent(rate float64) Amount {
+	return a.Mul(rate / 100)
+}
+
+// Round rounds to the minor unit of currency, half away from zero.
+func (a Amount) Round(currency string) Amount {
+	step := int64(math.Pow10(scale - MinorUnits(currency)))
+	value := int64(a)
+	if value < 0 {
+		return -Amount((-value + step/2) / step * step)
+	}
+	return Amount((value + step/2) / step * step)
+}
+
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+	amount, err := Parse(value)
+	if err != nil {
+		// clients may send floats with more places than an Amount holds
+		f, ferr := strconv.ParseFloat(value, 64)
+		if ferr != nil {
+			return err
+		}
+		amount = FromFloat(f)
+	}
+	*a = amount
+	return nil
+}
+
+func (a Amount) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(a.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(d)
+}
+
+// UnmarshalBSONValue also reads the doubles prices were stored as before
+// they were decimals.
+func (a *Amount) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Decimal128:
+		amount, err := fromDecimal128(raw.Decimal128())
+		if err != nil {
+			return err
+		}
+		*a = amount
+	case bsontype.Double:
+		*a = FromFloat(raw.Double())
+	case bsontype.Int32:
+		*a = Amount(int64(raw.Int32()) * unit)
+	case bsontype.Int64:
+		*a = Amount(raw.Int64() * unit)
+	case bsontype.Null, bsontype.Undefined:
+		*a = 0
+	default:
+		return fmt.Errorf("cannot read %s as an amount", t)
+	}
+	return nil
+}
+
+func fromDecimal128(d primitive.Decimal128) (Amount, error) {
+	coefficient, exponent, err := d.BigInt()
+	if err != nil {
+		return 0, err
+	}
+
+	value := new(big.Float).SetInt(coefficient)
+	value.Mul(value, new(big.Float).SetFloat64(math.Pow10(exponent+scale)))
+	units, _ := value.Float64()
+	return Amount(math.Round(units)), nil
+}
+
+// Value stores the amount in a numeric column as its exact decimal string.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*a = 0
+	case float64:
+		*a = FromFloat(value)
+	case float32:
+		*a = FromFloat(float64(value))
+	case int64:
+		*a = Amount(value * unit)
+	case string:
+		amount, err := Parse(value)
+		if err != nil {
+			return err
+		}
+		*a = amount
+	case []byte:
+		amount, err := Parse(string(value))
+		if err != nil {
+			return err
+		}
+		*a = amount
+	default:
+		return fmt.Errorf("cannot scan %T into an amount", src)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Amount
+		wantErr bool
+	}{
+		{value: "12", want: 120000},
+		{value: "12.5", want: 125000},
+		{value: " 12.50 ", want: 125000},
+		{value: "+3.25", want: 32500},
+		{value: "-0.0125", want: -125},
+		{value: ".5", want: 5000},
+		{value: "7.", want: 70000},
+		{value: "0", want: 0},
+		{value: "", wantErr: true},
+		{value: ".", wantErr: true},
+		{value: "1.23456", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "1.-5", wantErr: true},
+		{value: "1.2e3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{"12.344", "EUR", "12.34"},
+		{"12.345", "EUR", "12.35"},
+		{"-12.345", "EUR", "-12.35"},
+		{"12.3449", "usd", "12.34"},
+		{"1234.5", "JPY", "1235"},
+		{"1234.4999", "JPY", "1234"},
+		{"-1234.5", "JPY", "-1235"},
+		{"1.2345", "KWD", "1.235"},
+		{"1.2344", "BHD", "1.234"},
+		{"0.005", "EUR", "0.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount+" "+tt.currency, func(t *testing.T) {
+			amount, err := Parse(tt.amount)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.amount, err)
+			}
+			if got := amount.Round(tt.currency).String(); got != tt.want {
+				t.Errorf("Round(%s, %s) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloatSumsDoNotDrift(t *testing.T) {
+	var total Amount
+	for i := 0; i < 10; i++ {
+		total += FromFloat(0.1)
+	}
+	if total != FromFloat(1) {
+		t.Errorf("ten times 0.1 = %s, want 1", total)
+	}
+}
+
+func TestValueScanRoundTrip(t *testing.T) {
+	for _, value := range []string{"0.0001", "-12.5", "123456789.1234"} {
+		amount, err := Parse(value)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", value, err)
+		}
+
+		stored, err := amount.Value()
+		if err != nil {
+			t.Fatalf("Value(%s) error = %v", amount, err)
+		}
+		if stored != value {
+			t.Errorf("Value(%s) = %v, want the exact decimal %q", amount, stored, value)
+		}
+
+		var scanned Amount
+		if err := scanned.Scan(stored); err != nil {
+			t.Fatalf("Scan(%v) error = %v", stored, err)
+		}
+		if scanned != amount {
+			t.Errorf("Scan(%v) = %s, want %s", stored, scanned, amount)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"logistics-platform/lib/money"
+)
+
+// DeclinedCents is the cents part of amounts the fake gateway declines, so
+// failures can be produced on purpose: a fare of 12.13 is always declined.
+const DeclinedCents = 13
+
+// FakeGateway is a deterministic, stateless gateway for development and
+// tests. Ids are derived from the inputs, so retrying an operation returns
+// the same result, and nothing leaves the process. Callbacks for it can be
+// produced with SignWebhook.
+type FakeGateway struct {
+	webhookSecret []byte
+}
+
+func NewFakeGateway(webhookSecret string) PaymentGateway {
+	return &FakeGateway{webhookSecret: []byte(webhookSecret)}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	if err := checkAmount(req.Amount); err != nil {
+		return Result{}, err
+	}
+	if cents(req.Amount) == DeclinedCents {
+		return Result{}, fmt.Errorf("%w: card_declined", ErrDeclined)
+	}
+
+	authorizationID := fakeID("auth", req.IdempotencyKey)
+	return Result{ID: authorizationID, AuthorizationID: authorizationID, Amount: req.Amount}, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, authorizationID string, amount money.Amount) (Result, error) {
+	if err := checkAmount(amount); err != nil {
+		return Result{}, err
+	}
+	return Result{ID: fakeID("cap", authorizationID), AuthorizationID: authorizationID, Amount: amount}, nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, authorizationID string) (Result, error) {
+	return Result{ID: fakeID("void", authorizationID), AuthorizationID: authorizationID}, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, authorizationID string, amount money.Amount) (Result, error) {
+	if err := checkAmount(amount); err != nil {
+		return Result{}, err
+	}
+	return Result{ID: fakeID("ref", fmt.Sprintf("%s:%s", authorizationID, amount)), AuthorizationID: authorizationID, Amount: amount}, nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.sign(payload)) {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return WebhookEvent{}, fmt.Errorf("error decoding webhook: %w", err)
+	}
+	return event, nil
+}
+
+// SignWebhook returns the signature the fake gateway expects on a callback.
+func (g *FakeGateway) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(g.sign(payload))
+}
+
+func (g *FakeGateway) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.webhookSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// cents is the hundredths digit pair of an amount.
+func cents(amount money.Amount) int {
+	return int(amount.Round("USD")/100) % 100
+}
+
+func checkAmount(amount money.Amount) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: invalid_amount", ErrDeclined)
+	}
+	return nil
+}
+
+func fakeID(prefix, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("fake_%s_%s", prefix, hex.EncodeToString(sum[:8]))
+}
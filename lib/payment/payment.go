@@ -0,0 +1,97 @@
+// Package payment abstracts the card payment provider. Bookings authorize the
+// payable total when they are requested, capture what their invoice bills
+// once the trip is invoiced and void it when the trip is cancelled or the
+// request expires; refunds are issued by admins.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"logistics-platform/lib/money"
+)
+
+// ErrDeclined is returned, wrapped with the provider's reason, when the
+// provider refuses an operation. Any other error means the provider could not
+// be reached or answered unexpectedly, and the operation may be retried.
+var ErrDeclined = errors.New("payment declined")
+
+// ErrInvalidSignature is returned by VerifyWebhook for callbacks that were not
+// sent by the provider.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Webhook event types, as normalised by VerifyWebhook.
+const (
+	EventCaptured = "payment.captured"
+	EventFailed   = "payment.failed"
+	EventVoided   = "payment.voided"
+	EventRefunded = "payment.refunded"
+	EventExpired  = "authorization.expired"
+)
+
+type AuthorizeRequest struct {
+	// IdempotencyKey makes retried authorizations return the original one.
+	IdempotencyKey string
+	CustomerID     string
+	Amount         money.Amount
+	Currency       string
+}
+
+// Result is the provider's answer to an operation. ID identifies the
+// operation itself; AuthorizationID the payment it belongs to.
+type Result struct {
+	ID              string
+	AuthorizationID string
+	Amount          money.Amount
+}
+
+// WebhookEvent is a callback from the provider about a payment. For refunds,
+// Amount is the total refunded so far.
+type WebhookEvent struct {
+	ID              string       `json:"id"`
+	Type            string       `json:"type"`
+	AuthorizationID string       `json:"authorization_id"`
+	Amount          money.Amount `json:"amount"`
+	Reason          string       `json:"reason,omitempty"`
+}
+
+type PaymentGateway interface {
+	// Name identifies the provider in stored payments.
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, authorizationID string, amount money.Amount) (Result, error)
+	Void(ctx context.Context, authorizationID string) (Result, error)
+	Refund(ctx context.Context, authorizationID string, amount money.Amount) (Result, error)
+	// VerifyWebhook checks a callback's signature and decodes it.
+	VerifyWebhook(payload []byte, signature string) (WebhookEvent, error)
+}
+
+// developmentWebhookSecret signs the fake gateway's webhooks in development
+// when no secret is configured.
+const developmentWebhookSecret = "fake-webhook-secret"
+
+// NewGateway returns the gateway for a provider name. Outside development
+// both the provider and its webhook secret must be configured; in
+// development an empty name selects the fake gateway with a fixed secret.
+func NewGateway(provider, webhookSecret string, development bool) (PaymentGateway, error) {
+	if provider == "" {
+		if !development {
+			return nil, errors.New("PAYMENT_PROVIDER is required")
+		}
+		provider = "fake"
+	}
+	if webhookSecret == "" {
+		if !development || provider != "fake" {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET is required")
+		}
+		webhookSecret = developmentWebhookSecret
+	}
+
+	switch provider {
+	case "fake":
+		return NewFakeGateway(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", provider)
+	}
+}
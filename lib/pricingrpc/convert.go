@@ -0,0 +1,292 @@
+package pricingrpc
+
+import (
+	"fmt"
+	"time"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Conversions between the messages and lib/models, shared by the server in
+// services/pricing and its clients. Amounts are sent as money.Amount strings,
+// so parsing one only fails on a malformed message.
+
+func FromGeoPoint(point models.GeoPoint) *GeoPoint {
+	return &GeoPoint{Latitude: point.Latitude, Longitude: point.Longitude, Name: point.Name}
+}
+
+func ToGeoPoint(point *GeoPoint) models.GeoPoint {
+	return models.GeoPoint{Latitude: point.GetLatitude(), Longitude: point.GetLongitude(), Name: point.GetName()}
+}
+
+// FromBookingRequest is the estimate request of a booking request.
+func FromBookingRequest(req models.BookingRequest) *EstimateRequest {
+	estimateReq := &EstimateRequest{
+		Pickup:      FromGeoPoint(req.Pickup),
+		Dropoff:     FromGeoPoint(req.Dropoff),
+		VehicleType: req.VehicleType,
+		AllowShared: req.AllowShared,
+		Cargo: &Cargo{
+			Weight:            req.Cargo.Weight,
+			Volume:            req.Cargo.Volume,
+			Fragile:           req.Cargo.Fragile,
+			Hazardous:         req.Cargo.Hazardous,
+			LoadingAssistance: req.Cargo.LoadingAssistance,
+		},
+		PromoCode: req.PromoCode,
+	}
+	if req.PickupAt != nil {
+		estimateReq.PickupAt = timestamppb.New(*req.PickupAt)
+	}
+	return estimateReq
+}
+
+// ToBookingRequest is the booking request an estimate request prices, without
+// a user.
+func ToBookingRequest(req *EstimateRequest) models.BookingRequest {
+	bookingReq := models.BookingRequest{
+		Pickup:      ToGeoPoint(req.GetPickup()),
+		Dropoff:     ToGeoPoint(req.GetDropoff()),
+		VehicleType: req.GetVehicleType(),
+		AllowShared: req.GetAllowShared(),
+		Cargo: models.Cargo{
+			Weight:            req.GetCargo().GetWeight(),
+			Volume:            req.GetCargo().GetVolume(),
+			Fragile:           req.GetCargo().GetFragile(),
+			Hazardous:         req.GetCargo().GetHazardous(),
+			LoadingAssistance: req.GetCargo().GetLoadingAssistance(),
+		},
+		PromoCode: req.GetPromoCode(),
+	}
+	if req.PickupAt != nil {
+		pickupAt := req.PickupAt.AsTime()
+		bookingReq.PickupAt = &pickupAt
+	}
+	return bookingReq
+}
+
+func FromPriceEstimate(estimate models.PriceEstimate) *PriceEstimate {
+	msg := &PriceEstimate{
+		VehicleType:             estimate.VehicleType,
+		Currency:                estimate.Currency,
+		RegionId:                estimate.RegionID,
+		Region:                  estimate.Region,
+		RuleVersion:             int32(estimate.RuleVersion),
+		ExperimentId:            estimate.ExperimentID,
+		ExperimentVariant:       estimate.ExperimentVariant,
+		Distance:                estimate.Distance,
+		Duration:                estimate.Duration,
+		RoutingProvider:         estimate.RoutingProvider,
+		BaseFare:                estimate.BaseFare.String(),
+		DistanceCharge:          estimate.DistanceCharge.String(),
+		TimeCharge:              estimate.TimeCharge.String(),
+		WeightCharge:            estimate.WeightCharge.String(),
+		VolumeCharge:            estimate.VolumeCharge.String(),
+		MinimumChargeAdjustment: estimate.MinimumChargeAdjustment.String(),
+		BasePrice:               estimate.BasePrice.String(),
+		SurgeMultiplier:         estimate.Surge,
+		SurgeAmount:             estimate.SurgeAmount.String(),
+		FragileSurcharge:        estimate.FragileSurcharge.String(),
+		HazardousSurcharge:      estimate.HazardousSurcharge.String(),
+		LoadingAssistanceFee:    estimate.LoadingAssistanceFee.String(),
+		SharedDiscount:          estimate.SharedDiscount.String(),
+		PromoError:              estimate.PromoError,
+		TotalPrice:              estimate.TotalPrice.String(),
+		PlatformFee:             estimate.PlatformFee.String(),
+		TaxName:                 estimate.TaxName,
+		TaxRate:                 estimate.TaxRate,
+		TaxInclusive:            estimate.TaxInclusive,
+		Tax:                     estimate.Tax.String(),
+		Payable:                 estimate.Payable.String(),
+		PricingRule:             estimate.PricingRule,
+	}
+	if estimate.Discount != nil {
+		msg.Discount = &PromotionDiscount{
+			PromotionId: estimate.Discount.PromotionID,
+			Code:        estimate.Discount.Code,
+			Description: estimate.Discount.Description,
+			Amount:      estimate.Discount.Amount.String(),
+		}
+	}
+	return msg
+}
+
+func ToPriceEstimate(msg *PriceEstimate) (models.PriceEstimate, error) {
+	estimate := models.PriceEstimate{
+		VehicleType:       msg.GetVehicleType(),
+		Currency:          msg.GetCurrency(),
+		RegionID:          msg.GetRegionId(),
+		Region:            msg.GetRegion(),
+		RuleVersion:       int(msg.GetRuleVersion()),
+		ExperimentID:      msg.GetExperimentId(),
+		ExperimentVariant: msg.GetExperimentVariant(),
+		Distance:          msg.GetDistance(),
+		Duration:          msg.GetDuration(),
+		RoutingProvider:   msg.GetRoutingProvider(),
+		Surge:             msg.GetSurgeMultiplier(),
+		PromoError:        msg.GetPromoError(),
+		TaxName:           msg.GetTaxName(),
+		TaxRate:           msg.GetTaxRate(),
+		TaxInclusive:      msg.GetTaxInclusive(),
+		PricingRule:       msg.GetPricingRule(),
+	}
+
+	amounts := []struct {
+		value string
+		to    *money.Amount
+	}{
+		{msg.GetBaseFare(), &estimate.BaseFare},
+		{msg.GetDistanceCharge(), &estimate.DistanceCharge},
+		{msg.GetTimeCharge(), &estimate.TimeCharge},
+		{msg.GetWeightCharge(), &estimate.WeightCharge},
+		{msg.GetVolumeCharge(), &estimate.VolumeCharge},
+		{msg.GetMinimumChargeAdjustment(), &estimate.MinimumChargeAdjustment},
+		{msg.GetBasePrice(), &estimate.BasePrice},
+		{msg.GetSurgeAmount(), &estimate.SurgeAmount},
+		{msg.GetFragileSurcharge(), &estimate.FragileSurcharge},
+		{msg.GetHazardousSurcharge(), &estimate.HazardousSurcharge},
+		{msg.GetLoadingAssistanceFee(), &estimate.LoadingAssistanceFee},
+		{msg.GetSharedDiscount(), &estimate.SharedDiscount},
+		{msg.GetTotalPrice(), &estimate.TotalPrice},
+		{msg.GetPlatformFee(), &estimate.PlatformFee},
+		{msg.GetTax(), &estimate.Tax},
+		{msg.GetPayable(), &estimate.Payable},
+	}
+	for _, amount := range amounts {
+		if err := parseAmount(amount.value, amount.to); err != nil {
+			return models.PriceEstimate{}, err
+		}
+	}
+
+	if discount := msg.GetDiscount(); discount != nil {
+		estimate.Discount = &models.PromotionDiscount{
+			PromotionID: discount.GetPromotionId(),
+			Code:        discount.GetCode(),
+			Description: discount.GetDescription(),
+		}
+		if err := parseAmount(discount.GetAmount(), &estimate.Discount.Amount); err != nil {
+			return models.PriceEstimate{}, err
+		}
+	}
+	return estimate, nil
+}
+
+// parseAmount reads an amount field, where an empty string is zero.
+func parseAmount(value string, to *money.Amount) error {
+	if value == "" {
+		*to = 0
+		return nil
+	}
+	amount, err := money.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid amount in price estimate: %w", err)
+	}
+	*to = amount
+	return nil
+}
+
+func FromVehiclePricing(vp models.VehiclePricing) *VehiclePricing {
+	msg := &VehiclePricing{
+		Id:                   vp.ID,
+		RegionId:             vp.RegionID,
+		Type:                 vp.Type,
+		Version:              int32(vp.Version),
+		BasePrice:            vp.BasePrice,
+		PricePerKm:           vp.PricePerKm,
+		PricePerMinute:       vp.PricePerMinute,
+		PricePerKg:           vp.PricePerKg,
+		PricePerCubicMetre:   vp.PricePerCubicMetre,
+		FragileSurcharge:     vp.FragileSurcharge,
+		HazardousSurcharge:   vp.HazardousSurcharge,
+		LoadingAssistanceFee: vp.LoadingAssistanceFee,
+		Retired:              vp.Retired,
+		EffectiveFrom:        FromTime(vp.EffectiveFrom),
+		CreatedAt:            FromTime(vp.CreatedAt),
+	}
+	for _, minimum := range vp.MinimumCharges {
+		msg.MinimumCharges = append(msg.MinimumCharges, &MinimumCharge{FromWeight: minimum.FromWeight, Amount: minimum.Amount})
+	}
+	return msg
+}
+
+func ToVehiclePricing(msg *VehiclePricing) models.VehiclePricing {
+	vp := models.VehiclePricing{
+		ID:                   msg.GetId(),
+		RegionID:             msg.GetRegionId(),
+		Type:                 msg.GetType(),
+		Version:              int(msg.GetVersion()),
+		BasePrice:            msg.GetBasePrice(),
+		PricePerKm:           msg.GetPricePerKm(),
+		PricePerMinute:       msg.GetPricePerMinute(),
+		PricePerKg:           msg.GetPricePerKg(),
+		PricePerCubicMetre:   msg.GetPricePerCubicMetre(),
+		FragileSurcharge:     msg.GetFragileSurcharge(),
+		HazardousSurcharge:   msg.GetHazardousSurcharge(),
+		LoadingAssistanceFee: msg.GetLoadingAssistanceFee(),
+		Retired:              msg.GetRetired(),
+		EffectiveFrom:        ToTime(msg.GetEffectiveFrom()),
+		CreatedAt:            ToTime(msg.GetCreatedAt()),
+	}
+	for _, minimum := range msg.GetMinimumCharges() {
+		vp.MinimumCharges = append(vp.MinimumCharges, models.MinimumCharge{FromWeight: minimum.GetFromWeight(), Amount: minimum.GetAmount()})
+	}
+	return vp
+}
+
+func FromSurgeAtLocation(current models.SurgeAtLocation) *SurgeAtLocation {
+	msg := &SurgeAtLocation{
+		Location:    FromGeoPoint(current.Location),
+		Multiplier:  current.Multiplier,
+		PricingRule: current.PricingRule,
+	}
+	if current.Zone != nil {
+		msg.Zone = &ZoneSurge{
+			ZoneId:       current.Zone.ZoneID,
+			ZoneName:     current.Zone.ZoneName,
+			Multiplier:   current.Zone.Multiplier,
+			OpenRequests: int32(current.Zone.OpenRequests),
+			IdleDrivers:  int32(current.Zone.IdleDrivers),
+			UpdatedAt:    FromTime(current.Zone.UpdatedAt),
+		}
+	}
+	return msg
+}
+
+func ToSurgeAtLocation(msg *SurgeAtLocation) models.SurgeAtLocation {
+	current := models.SurgeAtLocation{
+		Location:    ToGeoPoint(msg.GetLocation()),
+		Multiplier:  msg.GetMultiplier(),
+		PricingRule: msg.GetPricingRule(),
+	}
+	if zone := msg.GetZone(); zone != nil {
+		current.Zone = &models.ZoneSurge{
+			ZoneID:       zone.GetZoneId(),
+			ZoneName:     zone.GetZoneName(),
+			Multiplier:   zone.GetMultiplier(),
+			OpenRequests: int(zone.GetOpenRequests()),
+			IdleDrivers:  int(zone.GetIdleDrivers()),
+			UpdatedAt:    ToTime(zone.GetUpdatedAt()),
+		}
+	}
+	return current
+}
+
+// FromTime leaves the zero time unset, so optional times such as at default
+// on the other side.
+func FromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// ToTime is the zero time for an unset timestamp.
+func ToTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
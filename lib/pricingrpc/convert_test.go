@@ -0,0 +1,106 @@
+package pricingrpc
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPriceEstimateRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		estimate models.PriceEstimate
+	}{
+		{
+			name: "full breakdown",
+			estimate: models.PriceEstimate{
+				VehicleType: "van", Currency: "EUR", RegionID: 2, Region: "Berlin", RuleVersion: 3,
+				ExperimentID: 4, ExperimentVariant: "treatment", Distance: 26.7, Duration: 39, RoutingProvider: "osrm",
+				BaseFare: money.FromFloat(10), DistanceCharge: money.FromFloat(33.38), TimeCharge: money.FromFloat(11.7),
+				WeightCharge: money.FromFloat(2), VolumeCharge: money.FromFloat(3), MinimumChargeAdjustment: money.FromFloat(0.05),
+				BasePrice: money.FromFloat(60.13), Surge: 1.25, PricingRule: "Evening peak", SurgeAmount: money.FromFloat(15.03),
+				FragileSurcharge: money.FromFloat(6.01), HazardousSurcharge: money.FromFloat(15.03), LoadingAssistanceFee: money.FromFloat(15),
+				SharedDiscount: money.FromFloat(15.03),
+				Discount:       &models.PromotionDiscount{PromotionID: 5, Code: "SPRING", Description: "10% off", Amount: money.FromFloat(11.12)},
+				TotalPrice:     money.FromFloat(100.08), PlatformFee: money.FromFloat(1.5), TaxName: "VAT", TaxRate: 19, TaxInclusive: true,
+				Tax: money.FromFloat(16.22), Payable: money.FromFloat(101.58),
+			},
+		},
+		{
+			name: "promo code not applied",
+			estimate: models.PriceEstimate{
+				VehicleType: "truck", Currency: "JPY", BaseFare: money.FromFloat(1500), BasePrice: money.FromFloat(1500), Surge: 1,
+				PromoError: "promo code not found", TotalPrice: money.FromFloat(1500), Payable: money.FromFloat(1500),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wire, err := proto.Marshal(FromPriceEstimate(tt.estimate))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var msg PriceEstimate
+			if err := proto.Unmarshal(wire, &msg); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			got, err := ToPriceEstimate(&msg)
+			if err != nil {
+				t.Fatalf("ToPriceEstimate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.estimate) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.estimate)
+			}
+		})
+	}
+}
+
+func TestToPriceEstimateInvalidAmount(t *testing.T) {
+	if _, err := ToPriceEstimate(&PriceEstimate{TotalPrice: "12,50"}); err == nil {
+		t.Error("ToPriceEstimate() error = nil, want an error for a malformed amount")
+	}
+}
+
+func TestBookingRequestRoundTrip(t *testing.T) {
+	pickupAt := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	req := models.BookingRequest{
+		Pickup:      models.GeoPoint{Latitude: 52.52, Longitude: 13.405, Name: "Warehouse"},
+		Dropoff:     models.GeoPoint{Latitude: 52.3906, Longitude: 13.0645},
+		VehicleType: "van",
+		PickupAt:    &pickupAt,
+		AllowShared: true,
+		Cargo:       models.Cargo{Weight: 120, Volume: 1.5, Fragile: true, LoadingAssistance: true},
+		PromoCode:   "SPRING",
+	}
+
+	got := ToBookingRequest(FromBookingRequest(req))
+	if !reflect.DeepEqual(got, req) {
+		t.Errorf("round trip = %+v, want %+v", got, req)
+	}
+
+	req.PickupAt = nil
+	if got := ToBookingRequest(FromBookingRequest(req)); got.PickupAt != nil {
+		t.Errorf("PickupAt = %v, want nil for an immediate booking", got.PickupAt)
+	}
+}
+
+func TestVehiclePricingRoundTrip(t *testing.T) {
+	vp := models.VehiclePricing{
+		ID: 7, RegionID: 2, Type: "van", Version: 3, BasePrice: 10, PricePerKm: 1.25, PricePerMinute: 0.3,
+		PricePerKg: 0.02, PricePerCubicMetre: 2,
+		MinimumCharges:   []models.MinimumCharge{{FromWeight: 0, Amount: 25}, {FromWeight: 500, Amount: 60}},
+		FragileSurcharge: 10, HazardousSurcharge: 25, LoadingAssistanceFee: 15,
+		EffectiveFrom: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:     time.Date(2024, 4, 20, 8, 0, 0, 0, time.UTC),
+	}
+
+	if got := ToVehiclePricing(FromVehiclePricing(vp)); !reflect.DeepEqual(got, vp) {
+		t.Errorf("round trip = %+v, want %+v", got, vp)
+	}
+}
@@ -0,0 +1,1461 @@
+// Package pricingrpc is the gRPC contract of the pricing service, for callers
+// such as the booking service that price every booking synchronously. It
+// mirrors the REST API in services/pricing/router and is served from the same
+// PricingInterface implementation, next to the grpc.health.v1 health service.
+//
+// pricing.pb.go and pricing_grpc.pb.go are generated from this file with
+// protoc-gen-go and protoc-gen-go-grpc (make proto).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        (unknown)
+// source: lib/pricingrpc/pricing.proto
+
+package pricingrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GeoPoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Name      string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *GeoPoint) Reset() {
+	*x = GeoPoint{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoPoint) ProtoMessage() {}
+
+func (x *GeoPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoPoint.ProtoReflect.Descriptor instead.
+func (*GeoPoint) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GeoPoint) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GeoPoint) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GeoPoint) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Cargo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Weight            float64 `protobuf:"fixed64,1,opt,name=weight,proto3" json:"weight,omitempty"`
+	Volume            float64 `protobuf:"fixed64,2,opt,name=volume,proto3" json:"volume,omitempty"`
+	Fragile           bool    `protobuf:"varint,3,opt,name=fragile,proto3" json:"fragile,omitempty"`
+	Hazardous         bool    `protobuf:"varint,4,opt,name=hazardous,proto3" json:"hazardous,omitempty"`
+	LoadingAssistance bool    `protobuf:"varint,5,opt,name=loading_assistance,json=loadingAssistance,proto3" json:"loading_assistance,omitempty"`
+}
+
+func (x *Cargo) Reset() {
+	*x = Cargo{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cargo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cargo) ProtoMessage() {}
+
+func (x *Cargo) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cargo.ProtoReflect.Descriptor instead.
+func (*Cargo) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Cargo) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Cargo) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Cargo) GetFragile() bool {
+	if x != nil {
+		return x.Fragile
+	}
+	return false
+}
+
+func (x *Cargo) GetHazardous() bool {
+	if x != nil {
+		return x.Hazardous
+	}
+	return false
+}
+
+func (x *Cargo) GetLoadingAssistance() bool {
+	if x != nil {
+		return x.LoadingAssistance
+	}
+	return false
+}
+
+// EstimateRequest is quoted for the user of the bearer token in the
+// "authorization" metadata, never a user named by the caller, as the REST
+// estimate.
+type EstimateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Pickup      *GeoPoint              `protobuf:"bytes,2,opt,name=pickup,proto3" json:"pickup,omitempty"`
+	Dropoff     *GeoPoint              `protobuf:"bytes,3,opt,name=dropoff,proto3" json:"dropoff,omitempty"`
+	VehicleType string                 `protobuf:"bytes,4,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	PickupAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=pickup_at,json=pickupAt,proto3" json:"pickup_at,omitempty"`
+	AllowShared bool                   `protobuf:"varint,6,opt,name=allow_shared,json=allowShared,proto3" json:"allow_shared,omitempty"`
+	Cargo       *Cargo                 `protobuf:"bytes,7,opt,name=cargo,proto3" json:"cargo,omitempty"`
+	PromoCode   string                 `protobuf:"bytes,8,opt,name=promo_code,json=promoCode,proto3" json:"promo_code,omitempty"`
+}
+
+func (x *EstimateRequest) Reset() {
+	*x = EstimateRequest{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateRequest) ProtoMessage() {}
+
+func (x *EstimateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateRequest.ProtoReflect.Descriptor instead.
+func (*EstimateRequest) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EstimateRequest) GetPickup() *GeoPoint {
+	if x != nil {
+		return x.Pickup
+	}
+	return nil
+}
+
+func (x *EstimateRequest) GetDropoff() *GeoPoint {
+	if x != nil {
+		return x.Dropoff
+	}
+	return nil
+}
+
+func (x *EstimateRequest) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *EstimateRequest) GetPickupAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PickupAt
+	}
+	return nil
+}
+
+func (x *EstimateRequest) GetAllowShared() bool {
+	if x != nil {
+		return x.AllowShared
+	}
+	return false
+}
+
+func (x *EstimateRequest) GetCargo() *Cargo {
+	if x != nil {
+		return x.Cargo
+	}
+	return nil
+}
+
+func (x *EstimateRequest) GetPromoCode() string {
+	if x != nil {
+		return x.PromoCode
+	}
+	return ""
+}
+
+type PromotionDiscount struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PromotionId int32  `protobuf:"varint,1,opt,name=promotion_id,json=promotionId,proto3" json:"promotion_id,omitempty"`
+	Code        string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Amount      string `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (x *PromotionDiscount) Reset() {
+	*x = PromotionDiscount{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromotionDiscount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromotionDiscount) ProtoMessage() {}
+
+func (x *PromotionDiscount) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromotionDiscount.ProtoReflect.Descriptor instead.
+func (*PromotionDiscount) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PromotionDiscount) GetPromotionId() int32 {
+	if x != nil {
+		return x.PromotionId
+	}
+	return 0
+}
+
+func (x *PromotionDiscount) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *PromotionDiscount) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PromotionDiscount) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+type PriceEstimate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VehicleType             string             `protobuf:"bytes,1,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Currency                string             `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	RegionId                int32              `protobuf:"varint,3,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Region                  string             `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	RuleVersion             int32              `protobuf:"varint,5,opt,name=rule_version,json=ruleVersion,proto3" json:"rule_version,omitempty"`
+	ExperimentId            int32              `protobuf:"varint,6,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	ExperimentVariant       string             `protobuf:"bytes,7,opt,name=experiment_variant,json=experimentVariant,proto3" json:"experiment_variant,omitempty"`
+	Distance                float64            `protobuf:"fixed64,8,opt,name=distance,proto3" json:"distance,omitempty"`
+	Duration                float64            `protobuf:"fixed64,9,opt,name=duration,proto3" json:"duration,omitempty"`
+	RoutingProvider         string             `protobuf:"bytes,10,opt,name=routing_provider,json=routingProvider,proto3" json:"routing_provider,omitempty"`
+	BaseFare                string             `protobuf:"bytes,11,opt,name=base_fare,json=baseFare,proto3" json:"base_fare,omitempty"`
+	DistanceCharge          string             `protobuf:"bytes,12,opt,name=distance_charge,json=distanceCharge,proto3" json:"distance_charge,omitempty"`
+	TimeCharge              string             `protobuf:"bytes,13,opt,name=time_charge,json=timeCharge,proto3" json:"time_charge,omitempty"`
+	WeightCharge            string             `protobuf:"bytes,14,opt,name=weight_charge,json=weightCharge,proto3" json:"weight_charge,omitempty"`
+	VolumeCharge            string             `protobuf:"bytes,15,opt,name=volume_charge,json=volumeCharge,proto3" json:"volume_charge,omitempty"`
+	MinimumChargeAdjustment string             `protobuf:"bytes,16,opt,name=minimum_charge_adjustment,json=minimumChargeAdjustment,proto3" json:"minimum_charge_adjustment,omitempty"`
+	BasePrice               string             `protobuf:"bytes,17,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
+	SurgeMultiplier         float64            `protobuf:"fixed64,18,opt,name=surge_multiplier,json=surgeMultiplier,proto3" json:"surge_multiplier,omitempty"`
+	SurgeAmount             string             `protobuf:"bytes,19,opt,name=surge_amount,json=surgeAmount,proto3" json:"surge_amount,omitempty"`
+	FragileSurcharge        string             `protobuf:"bytes,20,opt,name=fragile_surcharge,json=fragileSurcharge,proto3" json:"fragile_surcharge,omitempty"`
+	HazardousSurcharge      string             `protobuf:"bytes,21,opt,name=hazardous_surcharge,json=hazardousSurcharge,proto3" json:"hazardous_surcharge,omitempty"`
+	LoadingAssistanceFee    string             `protobuf:"bytes,22,opt,name=loading_assistance_fee,json=loadingAssistanceFee,proto3" json:"loading_assistance_fee,omitempty"`
+	SharedDiscount          string             `protobuf:"bytes,23,opt,name=shared_discount,json=sharedDiscount,proto3" json:"shared_discount,omitempty"`
+	Discount                *PromotionDiscount `protobuf:"bytes,24,opt,name=discount,proto3" json:"discount,omitempty"`
+	PromoError              string             `protobuf:"bytes,25,opt,name=promo_error,json=promoError,proto3" json:"promo_error,omitempty"`
+	TotalPrice              string             `protobuf:"bytes,26,opt,name=total_price,json=totalPrice,proto3" json:"total_price,omitempty"`
+	PlatformFee             string             `protobuf:"bytes,27,opt,name=platform_fee,json=platformFee,proto3" json:"platform_fee,omitempty"`
+	TaxName                 string             `protobuf:"bytes,28,opt,name=tax_name,json=taxName,proto3" json:"tax_name,omitempty"`
+	TaxRate                 float64            `protobuf:"fixed64,29,opt,name=tax_rate,json=taxRate,proto3" json:"tax_rate,omitempty"`
+	TaxInclusive            bool               `protobuf:"varint,30,opt,name=tax_inclusive,json=taxInclusive,proto3" json:"tax_inclusive,omitempty"`
+	Tax                     string             `protobuf:"bytes,31,opt,name=tax,proto3" json:"tax,omitempty"`
+	Payable                 string             `protobuf:"bytes,32,opt,name=payable,proto3" json:"payable,omitempty"`
+	PricingRule             string             `protobuf:"bytes,33,opt,name=pricing_rule,json=pricingRule,proto3" json:"pricing_rule,omitempty"`
+}
+
+func (x *PriceEstimate) Reset() {
+	*x = PriceEstimate{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceEstimate) ProtoMessage() {}
+
+func (x *PriceEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceEstimate.ProtoReflect.Descriptor instead.
+func (*PriceEstimate) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PriceEstimate) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetRuleVersion() int32 {
+	if x != nil {
+		return x.RuleVersion
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetExperimentId() int32 {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetExperimentVariant() string {
+	if x != nil {
+		return x.ExperimentVariant
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetRoutingProvider() string {
+	if x != nil {
+		return x.RoutingProvider
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetBaseFare() string {
+	if x != nil {
+		return x.BaseFare
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetDistanceCharge() string {
+	if x != nil {
+		return x.DistanceCharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetTimeCharge() string {
+	if x != nil {
+		return x.TimeCharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetWeightCharge() string {
+	if x != nil {
+		return x.WeightCharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetVolumeCharge() string {
+	if x != nil {
+		return x.VolumeCharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetMinimumChargeAdjustment() string {
+	if x != nil {
+		return x.MinimumChargeAdjustment
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetBasePrice() string {
+	if x != nil {
+		return x.BasePrice
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetSurgeMultiplier() float64 {
+	if x != nil {
+		return x.SurgeMultiplier
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetSurgeAmount() string {
+	if x != nil {
+		return x.SurgeAmount
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetFragileSurcharge() string {
+	if x != nil {
+		return x.FragileSurcharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetHazardousSurcharge() string {
+	if x != nil {
+		return x.HazardousSurcharge
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetLoadingAssistanceFee() string {
+	if x != nil {
+		return x.LoadingAssistanceFee
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetSharedDiscount() string {
+	if x != nil {
+		return x.SharedDiscount
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetDiscount() *PromotionDiscount {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *PriceEstimate) GetPromoError() string {
+	if x != nil {
+		return x.PromoError
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetTotalPrice() string {
+	if x != nil {
+		return x.TotalPrice
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetPlatformFee() string {
+	if x != nil {
+		return x.PlatformFee
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetTaxName() string {
+	if x != nil {
+		return x.TaxName
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetTaxRate() float64 {
+	if x != nil {
+		return x.TaxRate
+	}
+	return 0
+}
+
+func (x *PriceEstimate) GetTaxInclusive() bool {
+	if x != nil {
+		return x.TaxInclusive
+	}
+	return false
+}
+
+func (x *PriceEstimate) GetTax() string {
+	if x != nil {
+		return x.Tax
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetPayable() string {
+	if x != nil {
+		return x.Payable
+	}
+	return ""
+}
+
+func (x *PriceEstimate) GetPricingRule() string {
+	if x != nil {
+		return x.PricingRule
+	}
+	return ""
+}
+
+type GetVehiclePricingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VehicleType string `protobuf:"bytes,1,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	// at defaults to now; region, experiment and variant are optional, as the
+	// query parameters of the REST endpoint.
+	At           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+	RegionId     int32                  `protobuf:"varint,3,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	ExperimentId int32                  `protobuf:"varint,4,opt,name=experiment_id,json=experimentId,proto3" json:"experiment_id,omitempty"`
+	Variant      string                 `protobuf:"bytes,5,opt,name=variant,proto3" json:"variant,omitempty"`
+}
+
+func (x *GetVehiclePricingRequest) Reset() {
+	*x = GetVehiclePricingRequest{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVehiclePricingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVehiclePricingRequest) ProtoMessage() {}
+
+func (x *GetVehiclePricingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVehiclePricingRequest.ProtoReflect.Descriptor instead.
+func (*GetVehiclePricingRequest) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetVehiclePricingRequest) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *GetVehiclePricingRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+func (x *GetVehiclePricingRequest) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *GetVehiclePricingRequest) GetExperimentId() int32 {
+	if x != nil {
+		return x.ExperimentId
+	}
+	return 0
+}
+
+func (x *GetVehiclePricingRequest) GetVariant() string {
+	if x != nil {
+		return x.Variant
+	}
+	return ""
+}
+
+type MinimumCharge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromWeight float64 `protobuf:"fixed64,1,opt,name=from_weight,json=fromWeight,proto3" json:"from_weight,omitempty"`
+	Amount     float64 `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+}
+
+func (x *MinimumCharge) Reset() {
+	*x = MinimumCharge{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MinimumCharge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MinimumCharge) ProtoMessage() {}
+
+func (x *MinimumCharge) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MinimumCharge.ProtoReflect.Descriptor instead.
+func (*MinimumCharge) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MinimumCharge) GetFromWeight() float64 {
+	if x != nil {
+		return x.FromWeight
+	}
+	return 0
+}
+
+func (x *MinimumCharge) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type VehiclePricing struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id                   int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RegionId             int32                  `protobuf:"varint,2,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Type                 string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Version              int32                  `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	BasePrice            float64                `protobuf:"fixed64,5,opt,name=base_price,json=basePrice,proto3" json:"base_price,omitempty"`
+	PricePerKm           float64                `protobuf:"fixed64,6,opt,name=price_per_km,json=pricePerKm,proto3" json:"price_per_km,omitempty"`
+	PricePerMinute       float64                `protobuf:"fixed64,7,opt,name=price_per_minute,json=pricePerMinute,proto3" json:"price_per_minute,omitempty"`
+	PricePerKg           float64                `protobuf:"fixed64,8,opt,name=price_per_kg,json=pricePerKg,proto3" json:"price_per_kg,omitempty"`
+	PricePerCubicMetre   float64                `protobuf:"fixed64,9,opt,name=price_per_cubic_metre,json=pricePerCubicMetre,proto3" json:"price_per_cubic_metre,omitempty"`
+	MinimumCharges       []*MinimumCharge       `protobuf:"bytes,10,rep,name=minimum_charges,json=minimumCharges,proto3" json:"minimum_charges,omitempty"`
+	FragileSurcharge     float64                `protobuf:"fixed64,11,opt,name=fragile_surcharge,json=fragileSurcharge,proto3" json:"fragile_surcharge,omitempty"`
+	HazardousSurcharge   float64                `protobuf:"fixed64,12,opt,name=hazardous_surcharge,json=hazardousSurcharge,proto3" json:"hazardous_surcharge,omitempty"`
+	LoadingAssistanceFee float64                `protobuf:"fixed64,13,opt,name=loading_assistance_fee,json=loadingAssistanceFee,proto3" json:"loading_assistance_fee,omitempty"`
+	Retired              bool                   `protobuf:"varint,14,opt,name=retired,proto3" json:"retired,omitempty"`
+	EffectiveFrom        *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=effective_from,json=effectiveFrom,proto3" json:"effective_from,omitempty"`
+	CreatedAt            *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+}
+
+func (x *VehiclePricing) Reset() {
+	*x = VehiclePricing{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VehiclePricing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehiclePricing) ProtoMessage() {}
+
+func (x *VehiclePricing) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehiclePricing.ProtoReflect.Descriptor instead.
+func (*VehiclePricing) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VehiclePricing) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *VehiclePricing) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetBasePrice() float64 {
+	if x != nil {
+		return x.BasePrice
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetPricePerKm() float64 {
+	if x != nil {
+		return x.PricePerKm
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetPricePerMinute() float64 {
+	if x != nil {
+		return x.PricePerMinute
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetPricePerKg() float64 {
+	if x != nil {
+		return x.PricePerKg
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetPricePerCubicMetre() float64 {
+	if x != nil {
+		return x.PricePerCubicMetre
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetMinimumCharges() []*MinimumCharge {
+	if x != nil {
+		return x.MinimumCharges
+	}
+	return nil
+}
+
+func (x *VehiclePricing) GetFragileSurcharge() float64 {
+	if x != nil {
+		return x.FragileSurcharge
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetHazardousSurcharge() float64 {
+	if x != nil {
+		return x.HazardousSurcharge
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetLoadingAssistanceFee() float64 {
+	if x != nil {
+		return x.LoadingAssistanceFee
+	}
+	return 0
+}
+
+func (x *VehiclePricing) GetRetired() bool {
+	if x != nil {
+		return x.Retired
+	}
+	return false
+}
+
+func (x *VehiclePricing) GetEffectiveFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveFrom
+	}
+	return nil
+}
+
+func (x *VehiclePricing) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetSurgeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Location *GeoPoint `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// at is the pickup time the pricing rules are evaluated at, now by default.
+	At *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=at,proto3" json:"at,omitempty"`
+}
+
+func (x *GetSurgeRequest) Reset() {
+	*x = GetSurgeRequest{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSurgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSurgeRequest) ProtoMessage() {}
+
+func (x *GetSurgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSurgeRequest.ProtoReflect.Descriptor instead.
+func (*GetSurgeRequest) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetSurgeRequest) GetLocation() *GeoPoint {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *GetSurgeRequest) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+type ZoneSurge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ZoneId       int32                  `protobuf:"varint,1,opt,name=zone_id,json=zoneId,proto3" json:"zone_id,omitempty"`
+	ZoneName     string                 `protobuf:"bytes,2,opt,name=zone_name,json=zoneName,proto3" json:"zone_name,omitempty"`
+	Multiplier   float64                `protobuf:"fixed64,3,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	OpenRequests int32                  `protobuf:"varint,4,opt,name=open_requests,json=openRequests,proto3" json:"open_requests,omitempty"`
+	IdleDrivers  int32                  `protobuf:"varint,5,opt,name=idle_drivers,json=idleDrivers,proto3" json:"idle_drivers,omitempty"`
+	UpdatedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *ZoneSurge) Reset() {
+	*x = ZoneSurge{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ZoneSurge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneSurge) ProtoMessage() {}
+
+func (x *ZoneSurge) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneSurge.ProtoReflect.Descriptor instead.
+func (*ZoneSurge) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ZoneSurge) GetZoneId() int32 {
+	if x != nil {
+		return x.ZoneId
+	}
+	return 0
+}
+
+func (x *ZoneSurge) GetZoneName() string {
+	if x != nil {
+		return x.ZoneName
+	}
+	return ""
+}
+
+func (x *ZoneSurge) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *ZoneSurge) GetOpenRequests() int32 {
+	if x != nil {
+		return x.OpenRequests
+	}
+	return 0
+}
+
+func (x *ZoneSurge) GetIdleDrivers() int32 {
+	if x != nil {
+		return x.IdleDrivers
+	}
+	return 0
+}
+
+func (x *ZoneSurge) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type SurgeAtLocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Location *GeoPoint `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// zone is unset outside every surge zone.
+	Zone        *ZoneSurge `protobuf:"bytes,2,opt,name=zone,proto3" json:"zone,omitempty"`
+	Multiplier  float64    `protobuf:"fixed64,3,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	PricingRule string     `protobuf:"bytes,4,opt,name=pricing_rule,json=pricingRule,proto3" json:"pricing_rule,omitempty"`
+}
+
+func (x *SurgeAtLocation) Reset() {
+	*x = SurgeAtLocation{}
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SurgeAtLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SurgeAtLocation) ProtoMessage() {}
+
+func (x *SurgeAtLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_lib_pricingrpc_pricing_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SurgeAtLocation.ProtoReflect.Descriptor instead.
+func (*SurgeAtLocation) Descriptor() ([]byte, []int) {
+	return file_lib_pricingrpc_pricing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SurgeAtLocation) GetLocation() *GeoPoint {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *SurgeAtLocation) GetZone() *ZoneSurge {
+	if x != nil {
+		return x.Zone
+	}
+	return nil
+}
+
+func (x *SurgeAtLocation) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *SurgeAtLocation) GetPricingRule() string {
+	if x != nil {
+		return x.PricingRule
+	}
+	return ""
+}
+
+var File_lib_pricingrpc_pricing_proto protoreflect.FileDescriptor
+
+var file_lib_pricingrpc_pricing_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x6c, 0x69, 0x62, 0x2f, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x72, 0x70, 0x63,
+	0x2f, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a,
+	0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x58, 0x0a, 0x08, 0x47,
+	0x65, 0x6f, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x9e, 0x01, 0x0a, 0x05, 0x43, 0x61, 0x72, 0x67, 0x6f, 0x12,
+	0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x66, 0x72, 0x61, 0x67, 0x69, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x66, 0x72, 0x61, 0x67, 0x69, 0x6c, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x68, 0x61, 0x7a,
+	0x61, 0x72, 0x64, 0x6f, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x68, 0x61,
+	0x7a, 0x61, 0x72, 0x64, 0x6f, 0x75, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x6c, 0x6f, 0x61, 0x64, 0x69,
+	0x6e, 0x67, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x11, 0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x73, 0x73, 0x69,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0xc5, 0x02, 0x0a, 0x0f, 0x45, 0x73, 0x74, 0x69, 0x6d,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2c, 0x0a, 0x06, 0x70, 0x69,
+	0x63, 0x6b, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x69,
+	0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x50, 0x6f, 0x69, 0x6e, 0x74,
+	0x52, 0x06, 0x70, 0x69, 0x63, 0x6b, 0x75, 0x70, 0x12, 0x2e, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70,
+	0x6f, 0x66, 0x66, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x69, 0x63,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52,
+	0x07, 0x64, 0x72, 0x6f, 0x70, 0x6f, 0x66, 0x66, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x65, 0x68, 0x69,
+	0x63, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x70,
+	0x69, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x70, 0x69, 0x63, 0x6b,
+	0x75, 0x70, 0x41, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x05, 0x63, 0x61, 0x72, 0x67, 0x6f,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x72, 0x67, 0x6f, 0x52, 0x05, 0x63, 0x61, 0x72, 0x67, 0x6f,
+	0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x6d, 0x6f, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x6d, 0x6f, 0x43, 0x6f, 0x64, 0x65, 0x4a,
+	0x04, 0x08, 0x01, 0x10, 0x02, 0x52, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x22, 0x84,
+	0x01, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x73, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x70, 0x72, 0x6f, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a,
+	0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0xbe, 0x09, 0x0a, 0x0d, 0x50, 0x72, 0x69, 0x63, 0x65, 0x45,
+	0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x65, 0x68, 0x69, 0x63,
+	0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76,
+	0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x67, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x72,
+	0x75, 0x6c, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0b, 0x72, 0x75, 0x6c, 0x65, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23,
+	0x0a, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e,
+	0x74, 0x49, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e,
+	0x74, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x11, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x56, 0x61, 0x72, 0x69, 0x61,
+	0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x6f,
+	0x75, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x72, 0x6f,
+	0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x66, 0x61,
+	0x72, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x61, 0x73, 0x65, 0x46, 0x61,
+	0x72, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x63,
+	0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x69, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x23, 0x0a, 0x0d,
+	0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x68, 0x61, 0x72, 0x67,
+	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x63, 0x68, 0x61, 0x72,
+	0x67, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x3a, 0x0a, 0x19, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x5f, 0x61, 0x64, 0x6a, 0x75, 0x73, 0x74, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x17, 0x6d, 0x69, 0x6e, 0x69, 0x6d,
+	0x75, 0x6d, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x41, 0x64, 0x6a, 0x75, 0x73, 0x74, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x61, 0x73, 0x65, 0x50, 0x72, 0x69, 0x63,
+	0x65, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x75, 0x72, 0x67, 0x65, 0x5f, 0x6d, 0x75, 0x6c, 0x74, 0x69,
+	0x70, 0x6c, 0x69, 0x65, 0x72, 0x18, 0x12, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x73, 0x75, 0x72,
+	0x67, 0x65, 0x4d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x75, 0x72, 0x67, 0x65, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x73, 0x75, 0x72, 0x67, 0x65, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x2b, 0x0a, 0x11, 0x66, 0x72, 0x61, 0x67, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x75, 0x72, 0x63, 0x68,
+	0x61, 0x72, 0x67, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x66, 0x72, 0x61, 0x67,
+	0x69, 0x6c, 0x65, 0x53, 0x75, 0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x2f, 0x0a, 0x13,
+	0x68, 0x61, 0x7a, 0x61, 0x72, 0x64, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x75, 0x72, 0x63, 0x68, 0x61,
+	0x72, 0x67, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x68, 0x61, 0x7a, 0x61, 0x72,
+	0x64, 0x6f, 0x75, 0x73, 0x53, 0x75, 0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x34, 0x0a,
+	0x16, 0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x6c,
+	0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x46, 0x65, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x64, 0x69,
+	0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x68,
+	0x61, 0x72, 0x65, 0x64, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x08,
+	0x64, 0x69, 0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x08, 0x64,
+	0x69, 0x73, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x6d, 0x6f,
+	0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x19, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72,
+	0x6f, 0x6d, 0x6f, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x6c, 0x61,
+	0x74, 0x66, 0x6f, 0x72, 0x6d, 0x5f, 0x66, 0x65, 0x65, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x46, 0x65, 0x65, 0x12, 0x19, 0x0a, 0x08,
+	0x74, 0x61, 0x78, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x74, 0x61, 0x78, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x61, 0x78, 0x5f, 0x72,
+	0x61, 0x74, 0x65, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x74, 0x61, 0x78, 0x52, 0x61,
+	0x74, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x61, 0x78, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x73,
+	0x69, 0x76, 0x65, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x74, 0x61, 0x78, 0x49, 0x6e,
+	0x63, 0x6c, 0x75, 0x73, 0x69, 0x76, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x78, 0x18, 0x1f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x20, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x61,
+	0x62, 0x6c, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x5f, 0x72,
+	0x75, 0x6c, 0x65, 0x18, 0x21, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x69, 0x63, 0x69,
+	0x6e, 0x67, 0x52, 0x75, 0x6c, 0x65, 0x22, 0xc5, 0x01, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x56, 0x65,
+	0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x65, 0x68, 0x69, 0x63,
+	0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02,
+	0x61, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x23, 0x0a, 0x0d, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x72, 0x69, 0x6d, 0x65,
+	0x6e, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x22, 0x48,
+	0x0a, 0x0d, 0x4d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12,
+	0x1f, 0x0a, 0x0b, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x66, 0x72, 0x6f, 0x6d, 0x57, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x9b, 0x05, 0x0a, 0x0e, 0x56, 0x65, 0x68,
+	0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x72,
+	0x65, 0x67, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x70,
+	0x72, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x62, 0x61, 0x73, 0x65,
+	0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x70,
+	0x65, 0x72, 0x5f, 0x6b, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x50, 0x65, 0x72, 0x4b, 0x6d, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0e, 0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x4d, 0x69, 0x6e, 0x75, 0x74,
+	0x65, 0x12, 0x20, 0x0a, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x6b,
+	0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65,
+	0x72, 0x4b, 0x67, 0x12, 0x31, 0x0a, 0x15, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72,
+	0x5f, 0x63, 0x75, 0x62, 0x69, 0x63, 0x5f, 0x6d, 0x65, 0x74, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x12, 0x70, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x43, 0x75, 0x62, 0x69,
+	0x63, 0x4d, 0x65, 0x74, 0x72, 0x65, 0x12, 0x42, 0x0a, 0x0f, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x5f, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x6e,
+	0x69, 0x6d, 0x75, 0x6d, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x52, 0x0e, 0x6d, 0x69, 0x6e, 0x69,
+	0x6d, 0x75, 0x6d, 0x43, 0x68, 0x61, 0x72, 0x67, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x66, 0x72,
+	0x61, 0x67, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x75, 0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x66, 0x72, 0x61, 0x67, 0x69, 0x6c, 0x65, 0x53, 0x75,
+	0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x2f, 0x0a, 0x13, 0x68, 0x61, 0x7a, 0x61, 0x72,
+	0x64, 0x6f, 0x75, 0x73, 0x5f, 0x73, 0x75, 0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x68, 0x61, 0x7a, 0x61, 0x72, 0x64, 0x6f, 0x75, 0x73, 0x53,
+	0x75, 0x72, 0x63, 0x68, 0x61, 0x72, 0x67, 0x65, 0x12, 0x34, 0x0a, 0x16, 0x6c, 0x6f, 0x61, 0x64,
+	0x69, 0x6e, 0x67, 0x5f, 0x61, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x66,
+	0x65, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x14, 0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e,
+	0x67, 0x41, 0x73, 0x73, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x46, 0x65, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x72, 0x65, 0x74, 0x69, 0x72, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x72, 0x65, 0x74, 0x69, 0x72, 0x65, 0x64, 0x12, 0x41, 0x0a, 0x0e, 0x65, 0x66, 0x66, 0x65,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x65, 0x66,
+	0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x39, 0x0a, 0x0a, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x6f, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x75, 0x72,
+	0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x08, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72,
+	0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6f, 0x50, 0x6f, 0x69, 0x6e,
+	0x74, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x02, 0x61,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x02, 0x61, 0x74, 0x22, 0xe4, 0x01, 0x0a, 0x09, 0x5a, 0x6f, 0x6e, 0x65,
+	0x53, 0x75, 0x72, 0x67, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x7a, 0x6f, 0x6e, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x7a, 0x6f, 0x6e, 0x65, 0x49, 0x64, 0x12, 0x1b,
+	0x0a, 0x09, 0x7a, 0x6f, 0x6e, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x7a, 0x6f, 0x6e, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x6d,
+	0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x6f,
+	0x70, 0x65, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x6f, 0x70, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x64, 0x72, 0x69, 0x76, 0x65, 0x72, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x69, 0x64, 0x6c, 0x65, 0x44, 0x72, 0x69, 0x76,
+	0x65, 0x72, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xb1,
+	0x01, 0x0a, 0x0f, 0x53, 0x75, 0x72, 0x67, 0x65, 0x41, 0x74, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x6f, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x5a, 0x6f, 0x6e, 0x65, 0x53, 0x75, 0x72, 0x67, 0x65, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x12,
+	0x21, 0x0a, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x75, 0x6c, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x75,
+	0x6c, 0x65, 0x32, 0xf1, 0x01, 0x0a, 0x0e, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x42, 0x0a, 0x08, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x12, 0x1b, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
+	0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x69, 0x63,
+	0x65, 0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x12, 0x55, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x12, 0x24,
+	0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x56,
+	0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67,
+	0x12, 0x44, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x53, 0x75, 0x72, 0x67, 0x65, 0x12, 0x1b, 0x2e, 0x70,
+	0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x75, 0x72,
+	0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x72, 0x69, 0x63,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x72, 0x67, 0x65, 0x41, 0x74, 0x4c, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x23, 0x5a, 0x21, 0x6c, 0x6f, 0x67, 0x69, 0x73, 0x74,
+	0x69, 0x63, 0x73, 0x2d, 0x70, 0x6c, 0x61, 0x74, 0x66, 0x6f, 0x72, 0x6d, 0x2f, 0x6c, 0x69, 0x62,
+	0x2f, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_lib_pricingrpc_pricing_proto_rawDescOnce sync.Once
+	file_lib_pricingrpc_pricing_proto_rawDescData = file_lib_pricingrpc_pricing_proto_rawDesc
+)
+
+func file_lib_pricingrpc_pricing_proto_rawDescGZIP() []byte {
+	file_lib_pricingrpc_pricing_proto_rawDescOnce.Do(func() {
+		file_lib_pricingrpc_pricing_proto_rawDescData = protoimpl.X.CompressGZIP(file_lib_pricingrpc_pricing_proto_rawDescData)
+	})
+	return file_lib_pricingrpc_pricing_proto_rawDescData
+}
+
+var file_lib_pricingrpc_pricing_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_lib_pricingrpc_pricing_proto_goTypes = []any{
+	(*GeoPoint)(nil),                 // 0: pricing.v1.GeoPoint
+	(*Cargo)(nil),                    // 1: pricing.v1.Cargo
+	(*EstimateRequest)(nil),          // 2: pricing.v1.EstimateRequest
+	(*PromotionDiscount)(nil),        // 3: pricing.v1.PromotionDiscount
+	(*PriceEstimate)(nil),            // 4: pricing.v1.PriceEstimate
+	(*GetVehiclePricingRequest)(nil), // 5: pricing.v1.GetVehiclePricingRequest
+	(*MinimumCharge)(nil),            // 6: pricing.v1.MinimumCharge
+	(*VehiclePricing)(nil),           // 7: pricing.v1.VehiclePricing
+	(*GetSurgeRequest)(nil),          // 8: pricing.v1.GetSurgeRequest
+	(*ZoneSurge)(nil),                // 9: pricing.v1.ZoneSurge
+	(*SurgeAtLocation)(nil),          // 10: pricing.v1.SurgeAtLocation
+	(*timestamppb.Timestamp)(nil),    // 11: google.protobuf.Timestamp
+}
+var file_lib_pricingrpc_pricing_proto_depIdxs = []int32{
+	0,  // 0: pricing.v1.EstimateRequest.pickup:type_name -> pricing.v1.GeoPoint
+	0,  // 1: pricing.v1.EstimateRequest.dropoff:type_name -> pricing.v1.GeoPoint
+	11, // 2: pricing.v1.EstimateRequest.pickup_at:type_name -> google.protobuf.Timestamp
+	1,  // 3: pricing.v1.EstimateRequest.cargo:type_name -> pricing.v1.Cargo
+	3,  // 4: pricing.v1.PriceEstimate.discount:type_name -> pricing.v1.PromotionDiscount
+	11, // 5: pricing.v1.GetVehiclePricingRequest.at:type_name -> google.protobuf.Timestamp
+	6,  // 6: pricing.v1.VehiclePricing.minimum_charges:type_name -> pricing.v1.MinimumCharge
+	11, // 7: pricing.v1.VehiclePricing.effective_from:type_name -> google.protobuf.Timestamp
+	11, // 8: pricing.v1.VehiclePricing.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: pricing.v1.GetSurgeRequest.location:type_name -> pricing.v1.GeoPoint
+	11, // 10: pricing.v1.GetSurgeRequest.at:type_name -> google.protobuf.Timestamp
+	11, // 11: pricing.v1.ZoneSurge.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 12: pricing.v1.SurgeAtLocation.location:type_name -> pricing.v1.GeoPoint
+	9,  // 13: pricing.v1.SurgeAtLocation.zone:type_name -> pricing.v1.ZoneSurge
+	2,  // 14: pricing.v1.PricingService.Estimate:input_type -> pricing.v1.EstimateRequest
+	5,  // 15: pricing.v1.PricingService.GetVehiclePricing:input_type -> pricing.v1.GetVehiclePricingRequest
+	8,  // 16: pricing.v1.PricingService.GetSurge:input_type -> pricing.v1.GetSurgeRequest
+	4,  // 17: pricing.v1.PricingService.Estimate:output_type -> pricing.v1.PriceEstimate
+	7,  // 18: pricing.v1.PricingService.GetVehiclePricing:output_type -> pricing.v1.VehiclePricing
+	10, // 19: pricing.v1.PricingService.GetSurge:output_type -> pricing.v1.SurgeAtLocation
+	17, // [17:20] is the sub-list for method output_type
+	14, // [14:17] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_lib_pricingrpc_pricing_proto_init() }
+func file_lib_pricingrpc_pricing_proto_init() {
+	if File_lib_pricingrpc_pricing_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_lib_pricingrpc_pricing_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lib_pricingrpc_pricing_proto_goTypes,
+		DependencyIndexes: file_lib_pricingrpc_pricing_proto_depIdxs,
+		MessageInfos:      file_lib_pricingrpc_pricing_proto_msgTypes,
+	}.Build()
+	File_lib_pricingrpc_pricing_proto = out.File
+	file_lib_pricingrpc_pricing_proto_rawDesc = nil
+	file_lib_pricingrpc_pricing_proto_goTypes = nil
+	file_lib_pricingrpc_pricing_proto_depIdxs = nil
+}
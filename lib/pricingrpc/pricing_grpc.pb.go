@@ -0,0 +1,213 @@
+// Package pricingrpc is the gRPC contract of the pricing service, for callers
+// such as the booking service that price every booking synchronously. It
+// mirrors the REST API in services/pricing/router and is served from the same
+// PricingInterface implementation, next to the grpc.health.v1 health service.
+//
+// pricing.pb.go and pricing_grpc.pb.go are generated from this file with
+// protoc-gen-go and protoc-gen-go-grpc (make proto).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: lib/pricingrpc/pricing.proto
+
+package pricingrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PricingService_Estimate_FullMethodName          = "/pricing.v1.PricingService/Estimate"
+	PricingService_GetVehiclePricing_FullMethodName = "/pricing.v1.PricingService/GetVehiclePricing"
+	PricingService_GetSurge_FullMethodName          = "/pricing.v1.PricingService/GetSurge"
+)
+
+// PricingServiceClient is the client API for PricingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PricingServiceClient interface {
+	// Estimate prices a booking request, as POST /pricing/estimate.
+	Estimate(ctx context.Context, in *EstimateRequest, opts ...grpc.CallOption) (*PriceEstimate, error)
+	// GetVehiclePricing returns a vehicle type's rate card, as
+	// GET /pricing/vehicles/:type.
+	GetVehiclePricing(ctx context.Context, in *GetVehiclePricingRequest, opts ...grpc.CallOption) (*VehiclePricing, error)
+	// GetSurge returns the surge at a pickup location, as GET /pricing/surge.
+	GetSurge(ctx context.Context, in *GetSurgeRequest, opts ...grpc.CallOption) (*SurgeAtLocation, error)
+}
+
+type pricingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPricingServiceClient(cc grpc.ClientConnInterface) PricingServiceClient {
+	return &pricingServiceClient{cc}
+}
+
+func (c *pricingServiceClient) Estimate(ctx context.Context, in *EstimateRequest, opts ...grpc.CallOption) (*PriceEstimate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PriceEstimate)
+	err := c.cc.Invoke(ctx, PricingService_Estimate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pricingServiceClient) GetVehiclePricing(ctx context.Context, in *GetVehiclePricingRequest, opts ...grpc.CallOption) (*VehiclePricing, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VehiclePricing)
+	err := c.cc.Invoke(ctx, PricingService_GetVehiclePricing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pricingServiceClient) GetSurge(ctx context.Context, in *GetSurgeRequest, opts ...grpc.CallOption) (*SurgeAtLocation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SurgeAtLocation)
+	err := c.cc.Invoke(ctx, PricingService_GetSurge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PricingServiceServer is the server API for PricingService service.
+// All implementations must embed UnimplementedPricingServiceServer
+// for forward compatibility.
+type PricingServiceServer interface {
+	// Estimate prices a booking request, as POST /pricing/estimate.
+	Estimate(context.Context, *EstimateRequest) (*PriceEstimate, error)
+	// GetVehiclePricing returns a vehicle type's rate card, as
+	// GET /pricing/vehicles/:type.
+	GetVehiclePricing(context.Context, *GetVehiclePricingRequest) (*VehiclePricing, error)
+	// GetSurge returns the surge at a pickup location, as GET /pricing/surge.
+	GetSurge(context.Context, *GetSurgeRequest) (*SurgeAtLocation, error)
+	mustEmbedUnimplementedPricingServiceServer()
+}
+
+// UnimplementedPricingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPricingServiceServer struct{}
+
+func (UnimplementedPricingServiceServer) Estimate(context.Context, *EstimateRequest) (*PriceEstimate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Estimate not implemented")
+}
+func (UnimplementedPricingServiceServer) GetVehiclePricing(context.Context, *GetVehiclePricingRequest) (*VehiclePricing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVehiclePricing not implemented")
+}
+func (UnimplementedPricingServiceServer) GetSurge(context.Context, *GetSurgeRequest) (*SurgeAtLocation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSurge not implemented")
+}
+func (UnimplementedPricingServiceServer) mustEmbedUnimplementedPricingServiceServer() {}
+func (UnimplementedPricingServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafePricingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PricingServiceServer will
+// result in compilation errors.
+type UnsafePricingServiceServer interface {
+	mustEmbedUnimplementedPricingServiceServer()
+}
+
+func RegisterPricingServiceServer(s grpc.ServiceRegistrar, srv PricingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPricingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PricingService_ServiceDesc, srv)
+}
+
+func _PricingService_Estimate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricingServiceServer).Estimate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricingService_Estimate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricingServiceServer).Estimate(ctx, req.(*EstimateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PricingService_GetVehiclePricing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVehiclePricingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricingServiceServer).GetVehiclePricing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricingService_GetVehiclePricing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricingServiceServer).GetVehiclePricing(ctx, req.(*GetVehiclePricingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PricingService_GetSurge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PricingServiceServer).GetSurge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PricingService_GetSurge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PricingServiceServer).GetSurge(ctx, req.(*GetSurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PricingService_ServiceDesc is the grpc.ServiceDesc for PricingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PricingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pricing.v1.PricingService",
+	HandlerType: (*PricingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Estimate",
+			Handler:    _PricingService_Estimate_Handler,
+		},
+		{
+			MethodName: "GetVehiclePricing",
+			Handler:    _PricingService_GetVehiclePricing_Handler,
+		},
+		{
+			MethodName: "GetSurge",
+			Handler:    _PricingService_GetSurge_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "lib/pricingrpc/pricing.proto",
+}
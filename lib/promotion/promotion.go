@@ -0,0 +1,201 @@
+// Package promotion applies promo codes to fares. The pricing service quotes
+// discounts with Quote and the booking service redeems them with Redeem when
+// a driver accepts the booking, so limits hold however many estimates were
+// shown.
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+
+	"github.com/jackc/pgx/v4"
+)
+
+var ErrNotFound = errors.New("promo code not found")
+
+// ErrNotApplicable is returned, wrapped with the reason, for promo codes that
+// exist but cannot be used on the fare.
+var ErrNotApplicable = errors.New("promo code cannot be applied")
+
+const Columns = `id, code, COALESCE(campaign, ''), COALESCE(description, ''), discount_type, discount_value, max_discount, first_booking_only,
+	per_user_limit, max_redemptions, redemption_count, vehicle_types, zone_latitude, zone_longitude, zone_radius_km, COALESCE(currency, ''),
+	starts_at, ends_at, active, created_at`
+
+// Querier is satisfied by both pools and transactions.
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// Fare is what a promotion is applied to.
+type Fare struct {
+	UserID      string
+	VehicleType string
+	Pickup      models.GeoPoint
+	Amount      money.Amount
+	Currency    string
+}
+
+// Usage is how much the fare's user has used a promotion, and the platform.
+type Usage struct {
+	UserRedemptions int
+	UserBookings    int
+}
+
+// NormaliseCode makes codes case-insensitive.
+func NormaliseCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func Scan(row pgx.Row) (models.Promotion, error) {
+	var p models.Promotion
+	err := row.Scan(&p.ID, &p.Code, &p.Campaign, &p.Description, &p.DiscountType, &p.DiscountValue, &p.MaxDiscount, &p.FirstBookingOnly,
+		&p.PerUserLimit, &p.MaxRedemptions, &p.RedemptionCount, &p.VehicleTypes, &p.Zone.Latitude, &p.Zone.Longitude, &p.ZoneRadius,
+		&p.Currency, &p.StartsAt, &p.EndsAt, &p.Active, &p.CreatedAt)
+	return p, err
+}
+
+// Quote works out the discount a promo code gives on a fare.
+func Quote(ctx context.Context, q Querier, code string, fare Fare) (models.PromotionDiscount, error) {
+	p, err := Scan(q.QueryRow(ctx, "SELECT "+Columns+" FROM promotions WHERE code = $1", NormaliseCode(code)))
+	if err == pgx.ErrNoRows {
+		return models.PromotionDiscount{}, ErrNotFound
+	} else if err != nil {
+		return models.PromotionDiscount{}, fmt.Errorf("error fetching promotion: %w", err)
+	}
+
+	usage, err := userUsage(ctx, q, p, fare.UserID, 0)
+	if err != nil {
+		return models.PromotionDiscount{}, err
+	}
+
+	amount, err := Evaluate(p, fare, usage, time.Now())
+	if err != nil {
+		return models.PromotionDiscount{}, err
+	}
+
+	return models.PromotionDiscount{PromotionID: p.ID, Code: p.Code, Description: p.Description, Amount: amount}, nil
+}
+
+// Evaluate checks every restriction of a promotion and returns the discount
+// it gives on the fare.
+func Evaluate(p models.Promotion, fare Fare, usage Usage, now time.Time) (money.Amount, error) {
+	switch {
+	case !p.Active:
+		return 0, fmt.Errorf("%w: promotion is not active", ErrNotApplicable)
+	case now.Before(p.StartsAt):
+		return 0, fmt.Errorf("%w: promotion has not started", ErrNotApplicable)
+	case p.EndsAt != nil && !now.Before(*p.EndsAt):
+		return 0, fmt.Errorf("%w: promotion has ended", ErrNotApplicable)
+	case p.MaxRedemptions > 0 && p.RedemptionCount >= p.MaxRedemptions:
+		return 0, fmt.Errorf("%w: promotion has been fully redeemed", ErrNotApplicable)
+	case len(p.VehicleTypes) > 0 && !contains(p.VehicleTypes, fare.VehicleType):
+		return 0, fmt.Errorf("%w: not valid for %s", ErrNotApplicable, fare.VehicleType)
+	case p.ZoneRadius > 0 && geo.Distance(p.Zone, fare.Pickup) > p.ZoneRadius:
+		return 0, fmt.Errorf("%w: not valid for this pickup location", ErrNotApplicable)
+	case p.Currency != "" && p.Currency != fare.Currency:
+		return 0, fmt.Errorf("%w: only valid on fares in %s", ErrNotApplicable, p.Currency)
+	}
+
+	if p.FirstBookingOnly || p.PerUserLimit > 0 {
+		switch {
+		case fare.UserID == "":
+			return 0, fmt.Errorf("%w: sign in to use this promo code", ErrNotApplicable)
+		case p.FirstBookingOnly && usage.UserBookings > 0:
+			return 0, fmt.Errorf("%w: only valid on a first booking", ErrNotApplicable)
+		case p.PerUserLimit > 0 && usage.UserRedemptions >= p.PerUserLimit:
+			return 0, fmt.Errorf("%w: already used the maximum number of times", ErrNotApplicable)
+		}
+	}
+
+	discount := money.FromFloat(p.DiscountValue)
+	if p.DiscountType == models.DiscountPercentage {
+		discount = fare.Amount.Percent(p.DiscountValue)
+	}
+	if p.MaxDiscount > 0 {
+		discount = money.Min(discount, money.FromFloat(p.MaxDiscount))
+	}
+	discount = money.Min(discount, fare.Amount)
+
+	return discount.Round(fare.Currency), nil
+}
+
+// Redeem records a promotion's use on a booking inside tx. The promotion row
+// is locked, so concurrent redemptions cannot go over its limits; the
+// discount itself was fixed when the booking was quoted.
+func Redeem(ctx context.Context, tx pgx.Tx, promotionID int32, userID string, bookingID int32, discount money.Amount) error {
+	p, err := Scan(tx.QueryRow(ctx, "SELECT "+Columns+" FROM promotions WHERE id = $1 FOR UPDATE", promotionID))
+	if err == pgx.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("error fetching promotion: %w", err)
+	}
+
+	usage, err := userUsage(ctx, tx, p, userID, bookingID)
+	if err != nil {
+		return err
+	}
+
+	// the quote already passed the fare restrictions; only usage can have
+	// changed since
+	if p.MaxRedemptions > 0 && p.RedemptionCount >= p.MaxRedemptions {
+		return fmt.Errorf("%w: promotion has been fully redeemed", ErrNotApplicable)
+	}
+	if p.FirstBookingOnly && usage.UserBookings > 0 {
+		return fmt.Errorf("%w: only valid on a first booking", ErrNotApplicable)
+	}
+	if p.PerUserLimit > 0 && usage.UserRedemptions >= p.PerUserLimit {
+		return fmt.Errorf("%w: already used the maximum number of times", ErrNotApplicable)
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO promotion_redemptions (promotion_id, user_id, booking_id, discount) VALUES ($1, $2, $3, $4)",
+		promotionID, userID, bookingID, discount); err != nil {
+		return fmt.Errorf("error storing redemption: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE promotions SET redemption_count = redemption_count + 1 WHERE id = $1", promotionID); err != nil {
+		return fmt.Errorf("error counting redemption: %w", err)
+	}
+
+	return nil
+}
+
+// userUsage counts the user's earlier redemptions of the promotion and their
+// bookings other than excludeBookingID. Only what the promotion restricts on
+// is counted.
+func userUsage(ctx context.Context, q Querier, p models.Promotion, userID string, excludeBookingID int32) (Usage, error) {
+	var usage Usage
+	if userID == "" {
+		return usage, nil
+	}
+
+	if p.PerUserLimit > 0 {
+		if err := q.QueryRow(ctx, "SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = $1 AND user_id = $2", p.ID, userID).
+			Scan(&usage.UserRedemptions); err != nil {
+			return usage, fmt.Errorf("error counting redemptions: %w", err)
+		}
+	}
+
+	if p.FirstBookingOnly {
+		if err := q.QueryRow(ctx, "SELECT COUNT(*) FROM booking WHERE user_id = $1 AND id != $2 AND status != 'cancelled'", userID, excludeBookingID).
+			Scan(&usage.UserBookings); err != nil {
+			return usage, fmt.Errorf("error counting bookings: %w", err)
+		}
+	}
+
+	return usage, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
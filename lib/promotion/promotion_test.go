@@ -0,0 +1,85 @@
+package promotion
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+	berlin := models.GeoPoint{Latitude: 52.52, Longitude: 13.405}
+	potsdam := models.GeoPoint{Latitude: 52.3906, Longitude: 13.0645}
+
+	base := models.Promotion{
+		Code:          "SPRING",
+		DiscountType:  models.DiscountPercentage,
+		DiscountValue: 10,
+		StartsAt:      now.Add(-24 * time.Hour),
+		EndsAt:        &later,
+		Active:        true,
+	}
+	fare := Fare{UserID: "user-1", VehicleType: "van", Pickup: berlin, Amount: money.FromFloat(80), Currency: "EUR"}
+
+	tests := []struct {
+		name    string
+		promo   func(p *models.Promotion)
+		fare    func(f *Fare)
+		usage   Usage
+		want    float64
+		wantErr bool
+	}{
+		{name: "percentage", want: 8},
+		{name: "percentage capped", promo: func(p *models.Promotion) { p.MaxDiscount = 5 }, want: 5},
+		{name: "percentage rounded to the currency", fare: func(f *Fare) { f.Amount = money.FromFloat(12.35) }, want: 1.24},
+		{name: "flat", promo: func(p *models.Promotion) { p.DiscountType, p.DiscountValue = models.DiscountFlat, 15 }, want: 15},
+		{name: "flat never exceeds the fare", promo: func(p *models.Promotion) { p.DiscountType, p.DiscountValue = models.DiscountFlat, 100 }, want: 80},
+		{name: "open ended", promo: func(p *models.Promotion) { p.EndsAt = nil }, want: 8},
+		{name: "inactive", promo: func(p *models.Promotion) { p.Active = false }, wantErr: true},
+		{name: "not started", promo: func(p *models.Promotion) { p.StartsAt = later }, wantErr: true},
+		{name: "ended", promo: func(p *models.Promotion) { p.EndsAt = &ended }, wantErr: true},
+		{name: "ends now", promo: func(p *models.Promotion) { p.EndsAt = &now }, wantErr: true},
+		{name: "fully redeemed", promo: func(p *models.Promotion) { p.MaxRedemptions, p.RedemptionCount = 100, 100 }, wantErr: true},
+		{name: "redemptions left", promo: func(p *models.Promotion) { p.MaxRedemptions, p.RedemptionCount = 100, 99 }, want: 8},
+		{name: "vehicle type", promo: func(p *models.Promotion) { p.VehicleTypes = []string{"van", "truck"} }, want: 8},
+		{name: "other vehicle type", promo: func(p *models.Promotion) { p.VehicleTypes = []string{"truck"} }, wantErr: true},
+		{name: "inside the zone", promo: func(p *models.Promotion) { p.Zone, p.ZoneRadius = berlin, 5 }, want: 8},
+		{name: "outside the zone", promo: func(p *models.Promotion) { p.Zone, p.ZoneRadius = potsdam, 5 }, wantErr: true},
+		{name: "other currency", promo: func(p *models.Promotion) { p.Currency = "GBP" }, wantErr: true},
+		{name: "first booking", promo: func(p *models.Promotion) { p.FirstBookingOnly = true }, want: 8},
+		{name: "not a first booking", promo: func(p *models.Promotion) { p.FirstBookingOnly = true }, usage: Usage{UserBookings: 1}, wantErr: true},
+		{name: "anonymous first booking", promo: func(p *models.Promotion) { p.FirstBookingOnly = true }, fare: func(f *Fare) { f.UserID = "" }, wantErr: true},
+		{name: "under the user limit", promo: func(p *models.Promotion) { p.PerUserLimit = 2 }, usage: Usage{UserRedemptions: 1}, want: 8},
+		{name: "user limit reached", promo: func(p *models.Promotion) { p.PerUserLimit = 2 }, usage: Usage{UserRedemptions: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, f := base, fare
+			if tt.promo != nil {
+				tt.promo(&p)
+			}
+			if tt.fare != nil {
+				tt.fare(&f)
+			}
+
+			got, err := Evaluate(p, f, tt.usage, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNotApplicable) {
+					t.Errorf("Evaluate() error = %v, want ErrNotApplicable", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if want := money.FromFloat(tt.want); got != want {
+				t.Errorf("Evaluate() = %s, want %s", got, want)
+			}
+		})
+	}
+}
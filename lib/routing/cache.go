@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"logistics-platform/lib/config"
+	"logistics-platform/lib/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// defaultCacheTTL is how long a routed trip is reused, unless
+// ROUTE_CACHE_TTL (seconds) is set. Road networks change slowly, so a day
+// of reuse costs little accuracy.
+const defaultCacheTTL = 24 * time.Hour
+
+type cachedProvider struct {
+	provider Provider
+	rdb      *redis.Client
+	ttl      time.Duration
+}
+
+// WithCache returns a provider that keeps provider's routes in Redis for ttl.
+// Points are rounded to about 10 m, so repeated quotes for the same trip hit
+// the cache. A Redis failure only costs the cache, never the route.
+func WithCache(provider Provider, rdb *redis.Client, ttl time.Duration) Provider {
+	return &cachedProvider{provider: provider, rdb: rdb, ttl: ttl}
+}
+
+func (p *cachedProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	key := fmt.Sprintf("route:%.4f,%.4f:%.4f,%.4f", from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+
+	if cached, err := p.rdb.Get(ctx, key).Bytes(); err == nil {
+		var route Route
+		if err := json.Unmarshal(cached, &route); err == nil {
+			return route, nil
+		}
+	} else if err != redis.Nil {
+		log.Printf("Failed to read cached route: %v", err)
+	}
+
+	route, err := p.provider.Route(ctx, from, to)
+	if err != nil {
+		return Route{}, err
+	}
+
+	if routeJSON, err := json.Marshal(route); err == nil {
+		if err := p.rdb.Set(ctx, key, routeJSON, p.ttl).Err(); err != nil {
+			log.Printf("Failed to cache route: %v", err)
+		}
+	}
+	return route, nil
+}
+
+// NewProvider builds the provider named by ROUTING_PROVIDER ("osrm" or
+// "valhalla", served at ROUTING_SERVICE_URL), cached in rdb when it is not nil
+// and falling back to haversine. Anything else is plain haversine.
+func NewProvider(rdb *redis.Client) Provider {
+	var provider Provider
+	switch config.GetRoutingProvider() {
+	case "osrm":
+		provider = NewOSRMProvider(config.GetRoutingServiceURL(), viper.GetString("ROUTING_PROFILE"))
+	case "valhalla":
+		provider = NewValhallaProvider(config.GetRoutingServiceURL(), viper.GetString("ROUTING_PROFILE"))
+	default:
+		return NewHaversineProvider()
+	}
+
+	if rdb != nil {
+		ttl := defaultCacheTTL
+		if viper.IsSet("ROUTE_CACHE_TTL") {
+			ttl = time.Duration(viper.GetInt("ROUTE_CACHE_TTL")) * time.Second
+		}
+		provider = WithCache(provider, rdb, ttl)
+	}
+
+	// haversine routes are cheap and not cached, so an outage of the routing
+	// server is not remembered past its end
+	return WithFallback(provider, NewHaversineProvider())
+}
@@ -0,0 +1,145 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"logistics-platform/lib/models"
+)
+
+// requestTimeout bounds a single call to a routing server, so a slow one
+// falls back to haversine instead of stalling a price estimate.
+const requestTimeout = 3 * time.Second
+
+// OSRMProvider asks an OSRM server, or anything serving its /route/v1 API,
+// for the road route between two points.
+type OSRMProvider struct {
+	baseURL string
+	profile string
+	client  *http.Client
+}
+
+func NewOSRMProvider(baseURL, profile string) Provider {
+	if profile == "" {
+		profile = "driving"
+	}
+	return &OSRMProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		profile: profile,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (p *OSRMProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	url := fmt.Sprintf("%s/route/v1/%s/%f,%f;%f,%f?overview=false",
+		p.baseURL, p.profile, from.Longitude, from.Latitude, to.Longitude, to.Latitude)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Route{}, err
+	}
+
+	var result struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Distance float64 `json:"distance"` // metres
+			Duration float64 `json:"duration"` // seconds
+		} `json:"routes"`
+	}
+	if err := doJSON(p.client, req, &result); err != nil {
+		return Route{}, fmt.Errorf("osrm: %w", err)
+	}
+	if result.Code != "Ok" || len(result.Routes) == 0 {
+		return Route{}, fmt.Errorf("osrm: no route: %s %s", result.Code, result.Message)
+	}
+
+	return Route{
+		Distance: result.Routes[0].Distance / 1000,
+		Duration: result.Routes[0].Duration / 60,
+		Provider: "osrm",
+	}, nil
+}
+
+// ValhallaProvider asks a Valhalla server for the road route between two
+// points, costed for the given mode of travel.
+type ValhallaProvider struct {
+	baseURL string
+	costing string
+	client  *http.Client
+}
+
+func NewValhallaProvider(baseURL, costing string) Provider {
+	if costing == "" {
+		costing = "truck"
+	}
+	return &ValhallaProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		costing: costing,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (p *ValhallaProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	type location struct {
+		Lat float64 `json:"lat"`
+		Lon float64 `json:"lon"`
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"locations": []location{
+			{Lat: from.Latitude, Lon: from.Longitude},
+			{Lat: to.Latitude, Lon: to.Longitude},
+		},
+		"costing":         p.costing,
+		"units":           "kilometers",
+		"directions_type": "none",
+	})
+	if err != nil {
+		return Route{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/route", bytes.NewReader(body))
+	if err != nil {
+		return Route{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		Trip struct {
+			Summary struct {
+				Length float64 `json:"length"` // kilometres
+				Time   float64 `json:"time"`   // seconds
+			} `json:"summary"`
+		} `json:"trip"`
+	}
+	if err := doJSON(p.client, req, &result); err != nil {
+		return Route{}, fmt.Errorf("valhalla: %w", err)
+	}
+
+	return Route{
+		Distance: result.Trip.Summary.Length,
+		Duration: result.Trip.Summary.Time / 60,
+		Provider: "valhalla",
+	}, nil
+}
+
+func doJSON(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
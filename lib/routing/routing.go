@@ -0,0 +1,57 @@
+package routing
+
+import (
+	"context"
+
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+)
+
+// Route is the travel distance (km) and duration (minutes) between two
+// points, along with the name of the provider that produced it.
+type Route struct {
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	Provider string  `json:"provider"`
+}
+
+type Provider interface {
+	Route(ctx context.Context, from, to models.GeoPoint) (Route, error)
+}
+
+// HaversineProvider estimates routes as straight lines driven at
+// geo.AverageSpeed. It never fails and is the fallback for every other
+// provider.
+type HaversineProvider struct{}
+
+func NewHaversineProvider() Provider {
+	return HaversineProvider{}
+}
+
+func (HaversineProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	distance := geo.Distance(from, to)
+	return Route{
+		Distance: distance,
+		Duration: geo.EstimateDuration(distance),
+		Provider: "haversine",
+	}, nil
+}
+
+type fallbackProvider struct {
+	primary  Provider
+	fallback Provider
+}
+
+// WithFallback returns a provider that asks primary first and uses fallback
+// whenever primary fails.
+func WithFallback(primary, fallback Provider) Provider {
+	return &fallbackProvider{primary: primary, fallback: fallback}
+}
+
+func (p *fallbackProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	route, err := p.primary.Route(ctx, from, to)
+	if err == nil {
+		return route, nil
+	}
+	return p.fallback.Route(ctx, from, to)
+}
@@ -0,0 +1,207 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"logistics-platform/lib/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	pickup  = models.GeoPoint{Latitude: 52.5200, Longitude: 13.4050}
+	dropoff = models.GeoPoint{Latitude: 52.3906, Longitude: 13.0645}
+)
+
+func TestOSRMProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "/route/v1/truck/13.405000,52.520000;13.064500,52.390600"
+		if r.URL.Path != want {
+			t.Errorf("path = %q, want %q", r.URL.Path, want)
+		}
+		w.Write([]byte(`{"code":"Ok","routes":[{"distance":31500,"duration":2340}]}`))
+	}))
+	defer server.Close()
+
+	route, err := NewOSRMProvider(server.URL+"/", "truck").Route(context.Background(), pickup, dropoff)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if want := (Route{Distance: 31.5, Duration: 39, Provider: "osrm"}); route != want {
+		t.Errorf("Route() = %+v, want %+v", route, want)
+	}
+}
+
+func TestOSRMProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"no route", http.StatusOK, `{"code":"NoRoute","message":"Impossible route","routes":[]}`},
+		{"server error", http.StatusInternalServerError, `{}`},
+		{"invalid json", http.StatusOK, `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			if _, err := NewOSRMProvider(server.URL, "").Route(context.Background(), pickup, dropoff); err == nil {
+				t.Error("Route() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestValhallaProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/route" {
+			t.Errorf("request = %s %s, want POST /route", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Locations []struct {
+				Lat float64 `json:"lat"`
+				Lon float64 `json:"lon"`
+			} `json:"locations"`
+			Costing string `json:"costing"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if body.Costing != "truck" {
+			t.Errorf("costing = %q, want truck", body.Costing)
+		}
+		if len(body.Locations) != 2 || body.Locations[0].Lat != pickup.Latitude || body.Locations[1].Lon != dropoff.Longitude {
+			t.Errorf("locations = %+v", body.Locations)
+		}
+		w.Write([]byte(`{"trip":{"summary":{"length":31.5,"time":2340}}}`))
+	}))
+	defer server.Close()
+
+	route, err := NewValhallaProvider(server.URL, "").Route(context.Background(), pickup, dropoff)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if want := (Route{Distance: 31.5, Duration: 39, Provider: "valhalla"}); route != want {
+		t.Errorf("Route() = %+v, want %+v", route, want)
+	}
+}
+
+func TestValhallaProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"No path could be found"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	if _, err := NewValhallaProvider(server.URL, "auto").Route(context.Background(), pickup, dropoff); err == nil {
+		t.Error("Route() error = nil, want an error")
+	}
+}
+
+// countingProvider returns route, or err when it is set, and counts calls.
+type countingProvider struct {
+	route Route
+	err   error
+	calls int
+}
+
+func (p *countingProvider) Route(ctx context.Context, from, to models.GeoPoint) (Route, error) {
+	p.calls++
+	return p.route, p.err
+}
+
+func TestWithFallback(t *testing.T) {
+	primary := &countingProvider{route: Route{Distance: 31.5, Duration: 39, Provider: "osrm"}}
+	route, err := WithFallback(primary, NewHaversineProvider()).Route(context.Background(), pickup, dropoff)
+	if err != nil || route != primary.route {
+		t.Errorf("Route() = %+v, %v, want the primary route", route, err)
+	}
+
+	primary.err = errors.New("connection refused")
+	route, err = WithFallback(primary, NewHaversineProvider()).Route(context.Background(), pickup, dropoff)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if route.Provider != "haversine" || math.Abs(route.Distance-26.7) > 0.5 {
+		t.Errorf("Route() = %+v, want a haversine route of about 26.7 km", route)
+	}
+}
+
+func TestWithFallbackOnTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	route, err := WithFallback(NewOSRMProvider(server.URL, ""), NewHaversineProvider()).Route(ctx, pickup, dropoff)
+	if err != nil || route.Provider != "haversine" {
+		t.Errorf("Route() = %+v, %v, want a haversine route", route, err)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	primary := &countingProvider{route: Route{Distance: 31.5, Duration: 39, Provider: "osrm"}}
+	provider := WithCache(primary, rdb, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		route, err := provider.Route(context.Background(), pickup, dropoff)
+		if err != nil || route != primary.route {
+			t.Fatalf("Route() = %+v, %v, want %+v", route, err, primary.route)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("provider called %d times, want 1", primary.calls)
+	}
+
+	// points within about 10 m share the cached route
+	nearby := models.GeoPoint{Latitude: pickup.Latitude + 0.00001, Longitude: pickup.Longitude}
+	if _, err := provider.Route(context.Background(), nearby, dropoff); err != nil || primary.calls != 1 {
+		t.Errorf("nearby pickup called the provider %d times, err %v, want a cache hit", primary.calls, err)
+	}
+
+	mr.FastForward(time.Hour)
+	if _, err := provider.Route(context.Background(), pickup, dropoff); err != nil || primary.calls != 2 {
+		t.Errorf("expired route called the provider %d times, err %v, want 2", primary.calls, err)
+	}
+}
+
+func TestWithCacheErrors(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	failing := &countingProvider{err: errors.New("no route")}
+	if _, err := WithCache(failing, rdb, time.Hour).Route(context.Background(), pickup, dropoff); err == nil {
+		t.Error("Route() error = nil, want the provider's error")
+	}
+	if keys := mr.Keys(); len(keys) != 0 {
+		t.Errorf("cached %v after a failed route", keys)
+	}
+
+	// a Redis outage only costs the cache
+	mr.Close()
+	primary := &countingProvider{route: Route{Distance: 31.5, Duration: 39, Provider: "osrm"}}
+	route, err := WithCache(primary, rdb, time.Hour).Route(context.Background(), pickup, dropoff)
+	if err != nil || route != primary.route {
+		t.Errorf("Route() = %+v, %v, want %+v", route, err, primary.route)
+	}
+}
@@ -0,0 +1,165 @@
+// Package surge holds the supply and demand signals surge pricing is computed
+// from and the rules that turn them into multipliers. The booking service
+// records open requests here; the pricing service computes and publishes a
+// multiplier per zone.
+package surge
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+const (
+	// OpenRequestsKey is a geo index of booking requests waiting for a
+	// driver, keyed by their mongo id. DriversKey is the geo index of idle
+	// drivers kept by the driver location service.
+	OpenRequestsKey = "open_requests"
+	DriversKey      = "driver_locations"
+	// MultipliersKey is a hash of zone id to the zone's published ZoneSurge.
+	MultipliersKey = "surge_multipliers"
+
+	// openRequestTimesKey scores open requests by when they were made, so
+	// ones that expired without being accepted can be pruned.
+	openRequestTimesKey = "open_request_times"
+)
+
+// Defaults for the engine, each overridable by the environment variable
+// named alongside.
+const (
+	defaultSensitivity   = 0.5 // SURGE_SENSITIVITY
+	defaultMaxMultiplier = 2.5 // SURGE_MAX_MULTIPLIER
+	defaultSmoothing     = 0.5 // SURGE_SMOOTHING
+	defaultHysteresis    = 0.1 // SURGE_HYSTERESIS
+)
+
+// Config tunes how multipliers follow supply and demand. Sensitivity is how
+// much each extra request per idle driver adds, Smoothing the share of the
+// gap to the target closed per run and Hysteresis the smallest change worth
+// publishing, so prices do not flicker.
+type Config struct {
+	Sensitivity   float64
+	MaxMultiplier float64
+	Smoothing     float64
+	Hysteresis    float64
+}
+
+func LoadConfig() Config {
+	return Config{
+		Sensitivity:   setting("SURGE_SENSITIVITY", defaultSensitivity),
+		MaxMultiplier: setting("SURGE_MAX_MULTIPLIER", defaultMaxMultiplier),
+		Smoothing:     setting("SURGE_SMOOTHING", defaultSmoothing),
+		Hysteresis:    setting("SURGE_HYSTERESIS", defaultHysteresis),
+	}
+}
+
+func setting(key string, fallback float64) float64 {
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+	return fallback
+}
+
+// TrackOpenRequest records a booking request as waiting for a driver.
+func TrackOpenRequest(ctx context.Context, rdb *redis.Client, requestID string, pickup models.GeoPoint) error {
+	pipe := rdb.TxPipeline()
+	pipe.GeoAdd(ctx, OpenRequestsKey, &redis.GeoLocation{Name: requestID, Longitude: pickup.Longitude, Latitude: pickup.Latitude})
+	pipe.ZAdd(ctx, openRequestTimesKey, redis.Z{Score: float64(time.Now().Unix()), Member: requestID})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// CloseOpenRequest stops counting a booking request once a driver has it.
+func CloseOpenRequest(ctx context.Context, rdb *redis.Client, requestID string) error {
+	pipe := rdb.TxPipeline()
+	pipe.ZRem(ctx, OpenRequestsKey, requestID)
+	pipe.ZRem(ctx, openRequestTimesKey, requestID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// PruneOpenRequests forgets requests made before cutoff, which have expired
+// without a driver.
+func PruneOpenRequests(ctx context.Context, rdb *redis.Client, cutoff time.Time) error {
+	expired, err := rdb.ZRangeByScore(ctx, openRequestTimesKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "(" + strconv.FormatInt(cutoff.Unix(), 10),
+	}).Result()
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	members := make([]interface{}, len(expired))
+	for i, id := range expired {
+		members[i] = id
+	}
+
+	pipe := rdb.TxPipeline()
+	pipe.ZRem(ctx, OpenRequestsKey, members...)
+	pipe.ZRem(ctx, openRequestTimesKey, members...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// CountInZone counts the members of a geo index inside a zone's polygon.
+func CountInZone(ctx context.Context, rdb *redis.Client, key string, polygon []models.GeoPoint) (int, error) {
+	centre, radius := geo.BoundingCircle(polygon)
+	members, err := rdb.GeoRadius(ctx, key, centre.Longitude, centre.Latitude, &redis.GeoRadiusQuery{
+		Radius:    radius,
+		Unit:      "km",
+		WithCoord: true,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, member := range members {
+		if geo.InPolygon(models.GeoPoint{Latitude: member.Latitude, Longitude: member.Longitude}, polygon) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Target is the multiplier a zone's current supply and demand call for,
+// capped by the configured maximum, or by zoneMax when that is lower. A zone
+// can only tighten the cap, so what is published is what quotes charge.
+func Target(openRequests, idleDrivers int, zoneMax float64, cfg Config) float64 {
+	ratio := float64(openRequests) / math.Max(float64(idleDrivers), 1)
+	target := 1.0
+	if ratio > 1 {
+		target = 1 + cfg.Sensitivity*(ratio-1)
+	}
+
+	limit := cfg.MaxMultiplier
+	if zoneMax > 0 && zoneMax < limit {
+		limit = zoneMax
+	}
+	return math.Max(1, math.Min(target, limit))
+}
+
+// Next moves a zone's multiplier from previous towards target. Changes
+// smaller than the hysteresis are not published, except that a zone whose
+// demand has gone settles back to no surge.
+func Next(previous, target float64, cfg Config) float64 {
+	if previous < 1 {
+		previous = 1
+	}
+
+	next := previous + cfg.Smoothing*(target-previous)
+	if math.Abs(next-previous) < cfg.Hysteresis {
+		if target == 1 && next-1 < cfg.Hysteresis {
+			return 1
+		}
+		return previous
+	}
+	return math.Round(next*100) / 100
+}
@@ -0,0 +1,56 @@
+package surge
+
+import "testing"
+
+var testConfig = Config{Sensitivity: 0.5, MaxMultiplier: 2.5, Smoothing: 0.5, Hysteresis: 0.1}
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		openRequests int
+		idleDrivers  int
+		zoneMax      float64
+		want         float64
+	}{
+		{"no demand", 0, 5, 0, 1},
+		{"more drivers than requests", 3, 5, 0, 1},
+		{"balanced", 5, 5, 0, 1},
+		{"twice the requests", 10, 5, 0, 1.5},
+		{"no idle drivers counts as one", 3, 0, 0, 2},
+		{"capped at the configured maximum", 50, 5, 0, 2.5},
+		{"capped at the zone maximum", 50, 5, 1.8, 1.8},
+		{"zone maximum cannot raise the configured one", 50, 5, 4, 2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Target(tt.openRequests, tt.idleDrivers, tt.zoneMax, testConfig); got != tt.want {
+				t.Errorf("Target(%d, %d, %v) = %v, want %v", tt.openRequests, tt.idleDrivers, tt.zoneMax, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous float64
+		target   float64
+		want     float64
+	}{
+		{"closes half the gap", 1, 2, 1.5},
+		{"falls towards the target", 2, 1.2, 1.6},
+		{"unset previous starts from one", 0, 2, 1.5},
+		{"small rise is not published", 1.5, 1.6, 1.5},
+		{"small fall is not published", 1.5, 1.4, 1.5},
+		{"settles back to no surge", 1.15, 1, 1},
+		{"stays at no surge", 1, 1, 1},
+		{"rounds to two decimals", 1, 1.333, 1.17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Next(tt.previous, tt.target, testConfig); got != tt.want {
+				t.Errorf("Next(%v, %v) = %v, want %v", tt.previous, tt.target, got, tt.want)
+			}
+		})
+	}
+}
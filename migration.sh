@@ -15,6 +15,32 @@ docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "SELECT master_add_n
 docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS users (id SERIAL PRIMARY KEY,   name VARCHAR(255) NOT NULL,   email VARCHAR(255) UNIQUE NOT NULL,   password VARCHAR(255) NOT NULL,   created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,   updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP);"
 docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS vehicle_drivers (id SERIAL PRIMARY KEY, name VARCHAR(255) NOT NULL, vehicle_id VARCHAR(255) NOT NULL, email VARCHAR(255) NOT NULL, password VARCHAR(255) NOT NULL, vehicle_type VARCHAR(255) NOT NULL, vehicle_volume VARCHAR(255) NOT NULL);"
 docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS booking(id serial, user_id INTEGER NOT NULL,driver_id INTEGER NOT NULL,pickup_latitude FLOAT NOT NULL,pickup_longitude FLOAT NOT NULL,pickup_name VARCHAR(255) NOT NULL,dropoff_latitude FLOAT NOT NULL,dropoff_longitude FLOAT NOT NULL,dropoff_name VARCHAR(255) NOT NULL,vehicle_type VARCHAR(255) NOT NULL,price FLOAT NOT NULL,created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,completed_at TIMESTAMP WITH TIME ZONE,updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,status VARCHAR(255) NOT NULL, PRIMARY KEY(id, pickup_latitude));"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS booking_ratings (id SERIAL PRIMARY KEY, booking_id INTEGER NOT NULL, rater_role VARCHAR(16) NOT NULL, rater_id INTEGER NOT NULL, ratee_id INTEGER NOT NULL, rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5), tags TEXT[] NOT NULL DEFAULT '{}', comment TEXT NOT NULL DEFAULT '', created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, UNIQUE (booking_id, rater_role));"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS invoice_sequence (id SMALLINT PRIMARY KEY, last_number BIGINT NOT NULL); INSERT INTO invoice_sequence (id, last_number) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS invoices (id SERIAL PRIMARY KEY, invoice_number VARCHAR(32) UNIQUE NOT NULL, booking_id INTEGER UNIQUE NOT NULL, user_id INTEGER NOT NULL, driver_id INTEGER NOT NULL, line_items JSONB NOT NULL, subtotal FLOAT NOT NULL, tax FLOAT NOT NULL, total FLOAT NOT NULL, issued_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS organisations (id SERIAL PRIMARY KEY, name VARCHAR(255) NOT NULL, monthly_spend_limit FLOAT, approval_threshold FLOAT, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS organisation_members (organisation_id INTEGER NOT NULL REFERENCES organisations (id), user_id INTEGER UNIQUE NOT NULL, role VARCHAR(16) NOT NULL, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (organisation_id, user_id));"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS cost_centres (id SERIAL PRIMARY KEY, organisation_id INTEGER NOT NULL REFERENCES organisations (id), code VARCHAR(64) NOT NULL, name VARCHAR(255) NOT NULL, UNIQUE (organisation_id, code));"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE booking ADD COLUMN IF NOT EXISTS organisation_id INTEGER; ALTER TABLE booking ADD COLUMN IF NOT EXISTS cost_centre_id INTEGER;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE booking ADD COLUMN IF NOT EXISTS shared_trip_id VARCHAR(64); ALTER TABLE booking ADD COLUMN IF NOT EXISTS shared_discount FLOAT NOT NULL DEFAULT 0;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS recurring_bookings (id SERIAL PRIMARY KEY, user_id INTEGER NOT NULL, pickup_latitude FLOAT NOT NULL, pickup_longitude FLOAT NOT NULL, pickup_name VARCHAR(255) NOT NULL, dropoff_latitude FLOAT NOT NULL, dropoff_longitude FLOAT NOT NULL, dropoff_name VARCHAR(255) NOT NULL, vehicle_type VARCHAR(255) NOT NULL, frequency VARCHAR(16) NOT NULL, by_days TEXT[] NOT NULL DEFAULT '{}', time_of_day VARCHAR(5) NOT NULL, timezone VARCHAR(64) NOT NULL, starts_on DATE NOT NULL, ends_on DATE, exception_dates TEXT[] NOT NULL DEFAULT '{}', prefer_same_driver BOOLEAN NOT NULL DEFAULT FALSE, last_driver_id INTEGER, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS scheduled_bookings (id SERIAL PRIMARY KEY, recurring_booking_id INTEGER REFERENCES recurring_bookings (id), user_id INTEGER NOT NULL, pickup_at TIMESTAMP WITH TIME ZONE NOT NULL, booking_request JSONB, status VARCHAR(16) NOT NULL DEFAULT 'scheduled', error TEXT NOT NULL DEFAULT '', created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, UNIQUE (recurring_booking_id, pickup_at)); CREATE INDEX IF NOT EXISTS scheduled_bookings_due_idx ON scheduled_bookings (status, pickup_at);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS driver_preferences (user_id INTEGER NOT NULL, driver_id INTEGER NOT NULL, preference VARCHAR(16) NOT NULL CHECK (preference IN ('favourite', 'blocked')), created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (user_id, driver_id)); CREATE INDEX IF NOT EXISTS driver_preferences_driver_idx ON driver_preferences (driver_id, preference);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS driver_offers (id SERIAL PRIMARY KEY, mongo_id VARCHAR(64) NOT NULL, driver_id INTEGER NOT NULL, offered_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, response VARCHAR(16), reason VARCHAR(32), note TEXT, responded_at TIMESTAMP WITH TIME ZONE, UNIQUE (mongo_id, driver_id)); CREATE INDEX IF NOT EXISTS driver_offers_driver_idx ON driver_offers (driver_id, offered_at);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS chat_messages (id SERIAL PRIMARY KEY, booking_id INTEGER NOT NULL, sender_role VARCHAR(16) NOT NULL, sender_id INTEGER NOT NULL, recipient_id INTEGER NOT NULL, body TEXT NOT NULL, client_id VARCHAR(64), sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, read_at TIMESTAMP WITH TIME ZONE); CREATE INDEX IF NOT EXISTS chat_messages_booking_idx ON chat_messages (booking_id, sent_at);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS booking_adjustments (id SERIAL PRIMARY KEY, booking_id INTEGER NOT NULL, kind VARCHAR(16) NOT NULL, description VARCHAR(255) NOT NULL, quantity FLOAT NOT NULL DEFAULT 0, unit VARCHAR(16) NOT NULL DEFAULT '', amount FLOAT NOT NULL, status VARCHAR(16) NOT NULL, requested_by VARCHAR(16) NOT NULL, invoice_id INTEGER REFERENCES invoices(id), created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, decided_at TIMESTAMP WITH TIME ZONE); CREATE INDEX IF NOT EXISTS booking_adjustments_booking_idx ON booking_adjustments (booking_id); CREATE UNIQUE INDEX IF NOT EXISTS booking_adjustments_once_idx ON booking_adjustments (booking_id, kind) WHERE kind IN ('tip', 'loading', 'unloading') AND status != 'rejected'; ALTER TABLE booking ADD COLUMN IF NOT EXISTS adjustments_total FLOAT NOT NULL DEFAULT 0; ALTER TABLE invoices ADD COLUMN IF NOT EXISTS original_invoice_id INTEGER REFERENCES invoices(id); ALTER TABLE invoices DROP CONSTRAINT IF EXISTS invoices_booking_id_key; CREATE UNIQUE INDEX IF NOT EXISTS invoices_original_booking_idx ON invoices (booking_id) WHERE original_invoice_id IS NULL; CREATE INDEX IF NOT EXISTS invoices_booking_idx ON invoices (booking_id);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS ledger_transactions (id BIGSERIAL PRIMARY KEY, reference VARCHAR(64) NOT NULL UNIQUE, booking_id INTEGER, description VARCHAR(255) NOT NULL, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE TABLE IF NOT EXISTS payout_statements (id SERIAL PRIMARY KEY, driver_id INTEGER NOT NULL, period_start DATE NOT NULL, period_end DATE NOT NULL, trip_fares FLOAT NOT NULL DEFAULT 0, commission FLOAT NOT NULL DEFAULT 0, tips FLOAT NOT NULL DEFAULT 0, adjustments FLOAT NOT NULL DEFAULT 0, bonuses FLOAT NOT NULL DEFAULT 0, penalties FLOAT NOT NULL DEFAULT 0, amount FLOAT NOT NULL DEFAULT 0, status VARCHAR(16) NOT NULL DEFAULT 'pending', settled_at TIMESTAMP WITH TIME ZONE, settlement_reference VARCHAR(128), created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, UNIQUE (driver_id, period_start)); CREATE TABLE IF NOT EXISTS ledger_entries (id BIGSERIAL PRIMARY KEY, transaction_id BIGINT NOT NULL REFERENCES ledger_transactions(id), account VARCHAR(16) NOT NULL, driver_id INTEGER, kind VARCHAR(16) NOT NULL, amount FLOAT NOT NULL, statement_id INTEGER REFERENCES payout_statements(id), created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS ledger_entries_driver_idx ON ledger_entries (driver_id, created_at) WHERE account = 'driver'; CREATE INDEX IF NOT EXISTS ledger_entries_statement_idx ON ledger_entries (statement_id);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS payments (id SERIAL PRIMARY KEY, mongo_id VARCHAR(24) UNIQUE, booking_id INTEGER, invoice_id INTEGER UNIQUE REFERENCES invoices(id), user_id INTEGER NOT NULL, provider VARCHAR(32) NOT NULL, authorization_id VARCHAR(64) UNIQUE, amount FLOAT NOT NULL, captured_amount FLOAT NOT NULL DEFAULT 0, refunded_amount FLOAT NOT NULL DEFAULT 0, currency VARCHAR(3) NOT NULL, status VARCHAR(24) NOT NULL, failure_reason VARCHAR(255), created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS payments_booking_idx ON payments (booking_id); CREATE INDEX IF NOT EXISTS payments_status_idx ON payments (status); CREATE TABLE IF NOT EXISTS payment_refunds (id SERIAL PRIMARY KEY, payment_id INTEGER NOT NULL REFERENCES payments(id), refund_id VARCHAR(64) NOT NULL, amount FLOAT NOT NULL, reason VARCHAR(255) NOT NULL, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE TABLE IF NOT EXISTS payment_events (id VARCHAR(64) PRIMARY KEY, authorization_id VARCHAR(64) NOT NULL, type VARCHAR(32) NOT NULL, received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); ALTER TABLE booking ADD COLUMN IF NOT EXISTS payment_status VARCHAR(24);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS promotions (id SERIAL PRIMARY KEY, code VARCHAR(32) NOT NULL UNIQUE, campaign VARCHAR(64), description VARCHAR(255), discount_type VARCHAR(16) NOT NULL, discount_value FLOAT NOT NULL, max_discount FLOAT NOT NULL DEFAULT 0, first_booking_only BOOLEAN NOT NULL DEFAULT FALSE, per_user_limit INTEGER NOT NULL DEFAULT 0, max_redemptions INTEGER NOT NULL DEFAULT 0, redemption_count INTEGER NOT NULL DEFAULT 0, vehicle_types TEXT[] NOT NULL DEFAULT '{}', zone_latitude FLOAT NOT NULL DEFAULT 0, zone_longitude FLOAT NOT NULL DEFAULT 0, zone_radius_km FLOAT NOT NULL DEFAULT 0, starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP, ends_at TIMESTAMP WITH TIME ZONE, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS promotions_campaign_idx ON promotions (campaign); CREATE TABLE IF NOT EXISTS promotion_redemptions (id SERIAL PRIMARY KEY, promotion_id INTEGER NOT NULL REFERENCES promotions(id), user_id INTEGER NOT NULL, booking_id INTEGER NOT NULL UNIQUE, discount FLOAT NOT NULL, redeemed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS promotion_redemptions_user_idx ON promotion_redemptions (promotion_id, user_id); ALTER TABLE booking ADD COLUMN IF NOT EXISTS promotion_id INTEGER; ALTER TABLE booking ADD COLUMN IF NOT EXISTS discount FLOAT NOT NULL DEFAULT 0;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS vehicle_pricing (id SERIAL PRIMARY KEY, vehicle_type VARCHAR(32) NOT NULL, version INTEGER NOT NULL, base_price FLOAT NOT NULL, price_per_km FLOAT NOT NULL, price_per_minute FLOAT NOT NULL, retired BOOLEAN NOT NULL DEFAULT FALSE, effective_from TIMESTAMP WITH TIME ZONE NOT NULL, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, UNIQUE (vehicle_type, version)); INSERT INTO vehicle_pricing (vehicle_type, version, base_price, price_per_km, price_per_minute, effective_from) VALUES ('light_truck', 1, 20.0, 0.1117, 0.34, 'epoch'), ('van', 1, 20.0, 0.1791, 0.34, 'epoch'), ('truck', 1, 50.0, 0.2924, 0.5, 'epoch'), ('heavy_truck', 1, 100.0, 0.3488, 0.6, 'epoch'), ('trailer', 1, 200.0, 0.7859, 0.8, 'epoch') ON CONFLICT DO NOTHING;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS surge_zones (id SERIAL PRIMARY KEY, name VARCHAR(64) NOT NULL, polygon JSONB NOT NULL, max_multiplier FLOAT NOT NULL DEFAULT 0, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE booking ADD COLUMN IF NOT EXISTS quoted_distance FLOAT; ALTER TABLE booking ADD COLUMN IF NOT EXISTS quoted_duration FLOAT; ALTER TABLE booking ADD COLUMN IF NOT EXISTS surge_amount FLOAT;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS pricing_regions (id SERIAL PRIMARY KEY, name VARCHAR(64) NOT NULL, country VARCHAR(2) NOT NULL, currency VARCHAR(3) NOT NULL, polygon JSONB NOT NULL, tax_name VARCHAR(16) NOT NULL DEFAULT 'Tax', tax_rate FLOAT NOT NULL DEFAULT 0, tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS region_id INTEGER REFERENCES pricing_regions(id); ALTER TABLE vehicle_pricing DROP CONSTRAINT IF EXISTS vehicle_pricing_vehicle_type_version_key; CREATE UNIQUE INDEX IF NOT EXISTS vehicle_pricing_region_version_idx ON vehicle_pricing (COALESCE(region_id, 0), vehicle_type, version); ALTER TABLE booking ADD COLUMN IF NOT EXISTS currency VARCHAR(3); ALTER TABLE booking ADD COLUMN IF NOT EXISTS region_id INTEGER; ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_name VARCHAR(16); ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_rate FLOAT; ALTER TABLE booking ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE; ALTER TABLE promotions ADD COLUMN IF NOT EXISTS currency VARCHAR(3);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE booking ALTER COLUMN price TYPE NUMERIC(19, 4); ALTER TABLE booking ALTER COLUMN discount TYPE NUMERIC(19, 4); ALTER TABLE booking ALTER COLUMN adjustments_total TYPE NUMERIC(19, 4); ALTER TABLE booking ALTER COLUMN shared_discount TYPE NUMERIC(19, 4); ALTER TABLE booking ALTER COLUMN surge_amount TYPE NUMERIC(19, 4); ALTER TABLE booking_adjustments ALTER COLUMN amount TYPE NUMERIC(19, 4); ALTER TABLE invoices ALTER COLUMN subtotal TYPE NUMERIC(19, 4); ALTER TABLE invoices ALTER COLUMN tax TYPE NUMERIC(19, 4); ALTER TABLE invoices ALTER COLUMN total TYPE NUMERIC(19, 4); ALTER TABLE ledger_entries ALTER COLUMN amount TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN trip_fares TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN commission TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN tips TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN adjustments TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN bonuses TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN penalties TYPE NUMERIC(19, 4); ALTER TABLE payout_statements ALTER COLUMN amount TYPE NUMERIC(19, 4); ALTER TABLE payments ALTER COLUMN amount TYPE NUMERIC(19, 4); ALTER TABLE payments ALTER COLUMN captured_amount TYPE NUMERIC(19, 4); ALTER TABLE payments ALTER COLUMN refunded_amount TYPE NUMERIC(19, 4); ALTER TABLE payment_refunds ALTER COLUMN amount TYPE NUMERIC(19, 4); ALTER TABLE promotion_redemptions ALTER COLUMN discount TYPE NUMERIC(19, 4); ALTER TABLE organisations ALTER COLUMN monthly_spend_limit TYPE NUMERIC(19, 4); ALTER TABLE organisations ALTER COLUMN approval_threshold TYPE NUMERIC(19, 4); ALTER TABLE organisations ADD COLUMN IF NOT EXISTS currency VARCHAR(3);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS price_per_kg FLOAT NOT NULL DEFAULT 0; ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS price_per_cubic_metre FLOAT NOT NULL DEFAULT 0; ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS minimum_charges JSONB NOT NULL DEFAULT '[]'; ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS fragile_surcharge FLOAT NOT NULL DEFAULT 0; ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS hazardous_surcharge FLOAT NOT NULL DEFAULT 0; ALTER TABLE vehicle_pricing ADD COLUMN IF NOT EXISTS loading_assistance_fee FLOAT NOT NULL DEFAULT 0; ALTER TABLE booking ADD COLUMN IF NOT EXISTS cargo_weight FLOAT NOT NULL DEFAULT 0; ALTER TABLE booking ADD COLUMN IF NOT EXISTS cargo_volume FLOAT NOT NULL DEFAULT 0; ALTER TABLE booking ADD COLUMN IF NOT EXISTS fragile BOOLEAN NOT NULL DEFAULT FALSE; ALTER TABLE booking ADD COLUMN IF NOT EXISTS hazardous BOOLEAN NOT NULL DEFAULT FALSE; ALTER TABLE booking ADD COLUMN IF NOT EXISTS loading_assistance BOOLEAN NOT NULL DEFAULT FALSE;"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS pricing_experiments (id SERIAL PRIMARY KEY, name VARCHAR(64) NOT NULL, description VARCHAR(255) NOT NULL DEFAULT '', vehicle_type VARCHAR(32), region_id INTEGER REFERENCES pricing_regions(id), variants JSONB NOT NULL, starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP, ends_at TIMESTAMP WITH TIME ZONE, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); CREATE TABLE IF NOT EXISTS pricing_experiment_exposures (experiment_id INTEGER NOT NULL REFERENCES pricing_experiments(id), user_id INTEGER NOT NULL, variant VARCHAR(32) NOT NULL, quotes INTEGER NOT NULL DEFAULT 1, first_quoted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, last_quoted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (experiment_id, user_id)); ALTER TABLE booking ADD COLUMN IF NOT EXISTS experiment_id INTEGER; ALTER TABLE booking ADD COLUMN IF NOT EXISTS experiment_variant VARCHAR(32);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "CREATE TABLE IF NOT EXISTS surge_history (id BIGSERIAL PRIMARY KEY, zone_id INTEGER NOT NULL REFERENCES surge_zones(id), multiplier FLOAT NOT NULL, open_requests INTEGER NOT NULL, idle_drivers INTEGER NOT NULL, computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP); CREATE INDEX IF NOT EXISTS surge_history_zone_computed_at_idx ON surge_history (zone_id, computed_at); CREATE INDEX IF NOT EXISTS surge_history_computed_at_idx ON surge_history (computed_at);"
+docker-compose exec $MASTER psql -U $DB_USER -d $DB_NAME -c "ALTER TABLE pricing_regions ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT ''; CREATE TABLE IF NOT EXISTS pricing_rules (id SERIAL PRIMARY KEY, region_id INTEGER REFERENCES pricing_regions(id), name VARCHAR(64) NOT NULL, kind VARCHAR(16) NOT NULL, days JSONB NOT NULL DEFAULT '[]', dates JSONB NOT NULL DEFAULT '[]', start_time VARCHAR(5) NOT NULL DEFAULT '', end_time VARCHAR(5) NOT NULL DEFAULT '', multiplier FLOAT NOT NULL, priority INTEGER NOT NULL DEFAULT 0, active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP); INSERT INTO pricing_rules (name, kind, start_time, end_time, multiplier) SELECT * FROM (VALUES ('Morning peak', 'peak', '07:00', '10:00', 1.2), ('Evening peak', 'peak', '17:00', '20:00', 1.2)) AS peaks WHERE NOT EXISTS (SELECT 1 FROM pricing_rules);"
 
 
 # Distributed table
@@ -10,4 +10,35 @@ type AdminInterface interface {
 	GetBookingAnalytics(c *gin.Context)
 	GetVehicleLocations(c *gin.Context)
 	UpdateVehicle(c *gin.Context)
+	ResendInvoice(c *gin.Context)
+	GetDriverPreferences(c *gin.Context)
+	GetDriverAcceptance(c *gin.Context)
+	GetBookingChat(c *gin.Context)
+	GetPayoutStatements(c *gin.Context)
+	SettlePayout(c *gin.Context)
+	AddDriverEarning(c *gin.Context)
+	GetBookingPayment(c *gin.Context)
+	RefundBooking(c *gin.Context)
+	GetPromotions(c *gin.Context)
+	CreatePromotion(c *gin.Context)
+	UpdatePromotion(c *gin.Context)
+	DeactivatePromotion(c *gin.Context)
+	GetPromotionRedemptions(c *gin.Context)
+	GetVehiclePricing(c *gin.Context)
+	CreateVehiclePricing(c *gin.Context)
+	UpdateVehiclePricing(c *gin.Context)
+	DeleteVehiclePricing(c *gin.Context)
+	GetSurgeZones(c *gin.Context)
+	CreateSurgeZone(c *gin.Context)
+	UpdateSurgeZone(c *gin.Context)
+	GetRegions(c *gin.Context)
+	CreateRegion(c *gin.Context)
+	UpdateRegion(c *gin.Context)
+	GetPricingRules(c *gin.Context)
+	CreatePricingRule(c *gin.Context)
+	UpdatePricingRule(c *gin.Context)
+	GetExperiments(c *gin.Context)
+	CreateExperiment(c *gin.Context)
+	UpdateExperiment(c *gin.Context)
+	GetExperimentReport(c *gin.Context)
 }
@@ -9,6 +9,7 @@ import (
 	"logistics-platform/lib/config"
 	"logistics-platform/lib/database"
 	"logistics-platform/lib/middlewares/cors"
+	"logistics-platform/lib/payment"
 	"logistics-platform/lib/utils"
 	"logistics-platform/services/admin/router"
 	"logistics-platform/services/admin/service"
@@ -43,7 +44,12 @@ func main() {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 
-	service := service.NewAdminService(redisClient, pool, service.NewCache())
+	paymentGateway, err := payment.NewGateway(config.GetPaymentProvider(), config.GetPaymentWebhookSecret(), config.IsDevelopment())
+	if err != nil {
+		log.Fatalf("Failed to set up payment gateway: %v", err)
+	}
+
+	service := service.NewAdminService(redisClient, pool, service.NewCache(), paymentGateway)
 
 	r := gin.Default()
 	r.Use(cors.CORSMiddleware())
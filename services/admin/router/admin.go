@@ -18,5 +18,36 @@ func SetupRouter(router *gin.Engine, service interfaces.AdminInterface) {
 	adminGroup.GET("/booking-analytics", service.GetBookingAnalytics)
 	adminGroup.GET("/vehicle-locations", service.GetVehicleLocations)
 	adminGroup.POST("/update-vehicle", service.UpdateVehicle)
+	adminGroup.POST("/invoices/:bookingId/resend", service.ResendInvoice)
+	adminGroup.GET("/driver-preferences", service.GetDriverPreferences)
+	adminGroup.GET("/driver-acceptance", service.GetDriverAcceptance)
+	adminGroup.GET("/bookings/:bookingId/chat", service.GetBookingChat)
+	adminGroup.GET("/payouts", service.GetPayoutStatements)
+	adminGroup.POST("/payouts/:statementId/settle", service.SettlePayout)
+	adminGroup.POST("/drivers/:driverId/earnings", service.AddDriverEarning)
+	adminGroup.GET("/bookings/:bookingId/payment", service.GetBookingPayment)
+	adminGroup.POST("/bookings/:bookingId/refund", service.RefundBooking)
+	adminGroup.GET("/promotions", service.GetPromotions)
+	adminGroup.POST("/promotions", service.CreatePromotion)
+	adminGroup.PUT("/promotions/:promotionId", service.UpdatePromotion)
+	adminGroup.DELETE("/promotions/:promotionId", service.DeactivatePromotion)
+	adminGroup.GET("/promotions/:promotionId/redemptions", service.GetPromotionRedemptions)
+	adminGroup.GET("/vehicle-pricing", service.GetVehiclePricing)
+	adminGroup.POST("/vehicle-pricing", service.CreateVehiclePricing)
+	adminGroup.PUT("/vehicle-pricing/:pricingId", service.UpdateVehiclePricing)
+	adminGroup.DELETE("/vehicle-pricing/:pricingId", service.DeleteVehiclePricing)
+	adminGroup.GET("/surge-zones", service.GetSurgeZones)
+	adminGroup.POST("/surge-zones", service.CreateSurgeZone)
+	adminGroup.PUT("/surge-zones/:zoneId", service.UpdateSurgeZone)
+	adminGroup.GET("/regions", service.GetRegions)
+	adminGroup.POST("/regions", service.CreateRegion)
+	adminGroup.PUT("/regions/:regionId", service.UpdateRegion)
+	adminGroup.GET("/pricing-rules", service.GetPricingRules)
+	adminGroup.POST("/pricing-rules", service.CreatePricingRule)
+	adminGroup.PUT("/pricing-rules/:ruleId", service.UpdatePricingRule)
+	adminGroup.GET("/experiments", service.GetExperiments)
+	adminGroup.POST("/experiments", service.CreateExperiment)
+	adminGroup.PUT("/experiments/:experimentId", service.UpdateExperiment)
+	adminGroup.GET("/experiments/:experimentId/report", service.GetExperimentReport)
 
 }
@@ -6,9 +6,12 @@ import (
 
 	"github.com/jackc/pgx/v4/pgxpool"
 
+	kafkaConfig "logistics-platform/lib/kafka"
+	"logistics-platform/lib/payment"
 	"logistics-platform/services/admin/interfaces"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
 )
 
 type Cache struct {
@@ -22,13 +25,21 @@ type cacheItem struct {
 }
 
 type AdminService struct {
-	redisClient *redis.Client
-	pool        *pgxpool.Pool
-	cache       *Cache
+	redisClient   *redis.Client
+	pool          *pgxpool.Pool
+	cache         *Cache
+	bookingWriter *kafka.Writer
+	gateway       payment.PaymentGateway
 }
 
-func NewAdminService(redisClient *redis.Client, pool *pgxpool.Pool, cache *Cache) interfaces.AdminInterface {
-	return &AdminService{redisClient: redisClient, pool: pool, cache: cache}
+func NewAdminService(redisClient *redis.Client, pool *pgxpool.Pool, cache *Cache, gateway payment.PaymentGateway) interfaces.AdminInterface {
+	return &AdminService{
+		redisClient:   redisClient,
+		pool:          pool,
+		cache:         cache,
+		bookingWriter: kafkaConfig.InitKafkaWriter("booking_notifications"),
+		gateway:       gateway,
+	}
 }
 
 func NewCache() *Cache {
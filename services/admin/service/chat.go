@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"logistics-platform/lib/models"
+)
+
+// GetBookingChat returns the full chat of a booking, with read receipts, for
+// dispute review.
+func (s *AdminService) GetBookingChat(c *gin.Context) {
+	bookingID, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messages := []models.ChatMessage{}
+
+	err = retry(3, 100*time.Millisecond, func() error {
+		messages = messages[:0]
+
+		rows, err := s.pool.Query(ctx, `
+			SELECT id, booking_id, sender_role, sender_id, recipient_id, body, COALESCE(client_id, ''), sent_at, read_at
+			FROM chat_messages
+			WHERE booking_id = $1
+			ORDER BY sent_at, id
+		`, bookingID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch chat: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			message := models.ChatMessage{Type: models.ChatTypeMessage}
+			if err := rows.Scan(&message.ID, &message.BookingID, &message.SenderRole, &message.SenderID, &message.RecipientID,
+				&message.Body, &message.ClientID, &message.SentAt, &message.ReadAt); err != nil {
+				return fmt.Errorf("failed to scan chat message: %v", err)
+			}
+			messages = append(messages, message)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+const experimentColumns = `id, name, description, COALESCE(vehicle_type, ''), COALESCE(region_id, 0), variants, starts_at, ends_at, active, created_at`
+
+// GetExperiments lists the pricing experiments, optionally filtered by active.
+func (s *AdminService) GetExperiments(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + experimentColumns + " FROM pricing_experiments"
+	var args []interface{}
+	if active := c.Query("active"); active != "" {
+		args = append(args, active)
+		query += " WHERE active = $1"
+	}
+	query += " ORDER BY id DESC"
+
+	experiments := []models.PricingExperiment{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		experiments = experiments[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch experiments: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			experiment, err := scanExperiment(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan experiment: %v", err)
+			}
+			experiments = append(experiments, experiment)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, experiments)
+}
+
+// CreateExperiment starts a pricing experiment at starts_at, or immediately
+// when that is omitted.
+func (s *AdminService) CreateExperiment(c *gin.Context) {
+	var experiment models.PricingExperiment
+	if err := c.ShouldBindJSON(&experiment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := prepareExperiment(&experiment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		var err error
+		experiment, err = scanExperiment(s.pool.QueryRow(ctx, `
+			INSERT INTO pricing_experiments (name, description, vehicle_type, region_id, variants, starts_at, ends_at)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0), $5, $6, $7)
+			RETURNING `+experimentColumns,
+			experiment.Name, experiment.Description, experiment.VehicleType, experiment.RegionID, experiment.Variants, experiment.StartsAt,
+			experiment.EndsAt))
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create experiment: %v", err)})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusCreated, experiment)
+}
+
+// UpdateExperiment changes an experiment; setting active to false or ends_at
+// stops it. Users are bucketed by variant weights, so changing the weights
+// or variants of a running experiment moves users between variants and
+// muddies its report.
+func (s *AdminService) UpdateExperiment(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experimentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid experiment id"})
+		return
+	}
+
+	var experiment models.PricingExperiment
+	if err := c.ShouldBindJSON(&experiment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := prepareExperiment(&experiment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		experiment, err = scanExperiment(s.pool.QueryRow(ctx, `
+			UPDATE pricing_experiments SET name = $1, description = $2, vehicle_type = NULLIF($3, ''), region_id = NULLIF($4, 0), variants = $5,
+				starts_at = $6, ends_at = $7, active = $8
+			WHERE id = $9
+			RETURNING `+experimentColumns,
+			experiment.Name, experiment.Description, experiment.VehicleType, experiment.RegionID, experiment.Variants, experiment.StartsAt,
+			experiment.EndsAt, experiment.Active, experimentID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update experiment: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "experiment not found"})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusOK, experiment)
+}
+
+// GetExperimentReport compares the conversion and revenue of an experiment's
+// variants, in the order they were defined.
+func (s *AdminService) GetExperimentReport(c *gin.Context) {
+	experimentID, err := strconv.Atoi(c.Param("experimentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid experiment id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reports []models.ExperimentVariantReport
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		experiment, err := scanExperiment(s.pool.QueryRow(ctx, "SELECT "+experimentColumns+" FROM pricing_experiments WHERE id = $1", experimentID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to fetch experiment: %v", err)
+		}
+
+		reports, err = s.experimentReport(ctx, experiment)
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "experiment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+func (s *AdminService) experimentReport(ctx context.Context, experiment models.PricingExperiment) ([]models.ExperimentVariantReport, error) {
+	// variants removed since users were quoted under them are still reported,
+	// after the current ones
+	var order []string
+	byVariant := map[string]*models.ExperimentVariantReport{}
+	report := func(variant string) *models.ExperimentVariantReport {
+		if _, ok := byVariant[variant]; !ok {
+			order = append(order, variant)
+			byVariant[variant] = &models.ExperimentVariantReport{Variant: variant, Revenue: map[string]money.Amount{}, AverageFare: map[string]money.Amount{}}
+		}
+		return byVariant[variant]
+	}
+	for _, variant := range experiment.Variants {
+		report(variant.Name)
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT variant, COUNT(*), SUM(quotes) FROM pricing_experiment_exposures WHERE experiment_id = $1 GROUP BY variant`, experiment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch exposures: %v", err)
+	}
+	for rows.Next() {
+		var variant string
+		var users, quotes int
+		if err := rows.Scan(&variant, &users, &quotes); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan exposures: %v", err)
+		}
+		r := report(variant)
+		r.UsersQuoted, r.Quotes = users, quotes
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch exposures: %v", err)
+	}
+
+	rows, err = s.pool.Query(ctx, `
+		SELECT experiment_variant, COUNT(DISTINCT user_id) FROM booking
+		WHERE experiment_id = $1 AND status != 'cancelled' GROUP BY experiment_variant`, experiment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch experiment bookings: %v", err)
+	}
+	for rows.Next() {
+		var variant string
+		var users int
+		if err := rows.Scan(&variant, &users); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan experiment bookings: %v", err)
+		}
+		report(variant).UsersBooked = users
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch experiment bookings: %v", err)
+	}
+
+	rows, err = s.pool.Query(ctx, `
+		SELECT experiment_variant, COALESCE(currency, ''), status = 'cancelled', COUNT(*), COALESCE(SUM(price), 0) FROM booking
+		WHERE experiment_id = $1 GROUP BY experiment_variant, COALESCE(currency, ''), status = 'cancelled'`, experiment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch experiment revenue: %v", err)
+	}
+	bookingsByCurrency := map[string]map[string]int{}
+	for rows.Next() {
+		var variant, currency string
+		var cancelled bool
+		var bookings int
+		var revenue money.Amount
+		if err := rows.Scan(&variant, &currency, &cancelled, &bookings, &revenue); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan experiment revenue: %v", err)
+		}
+		r := report(variant)
+		if cancelled {
+			r.Cancelled += bookings
+			continue
+		}
+		r.Bookings += bookings
+		r.Revenue[currency] += revenue
+		if bookingsByCurrency[variant] == nil {
+			bookingsByCurrency[variant] = map[string]int{}
+		}
+		bookingsByCurrency[variant][currency] += bookings
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch experiment revenue: %v", err)
+	}
+
+	reports := make([]models.ExperimentVariantReport, 0, len(order))
+	for _, variant := range order {
+		r := byVariant[variant]
+		if r.UsersQuoted > 0 {
+			r.Conversion = math.Round(float64(r.UsersBooked)/float64(r.UsersQuoted)*10000) / 10000
+		}
+		for currency, revenue := range r.Revenue {
+			r.Revenue[currency] = revenue.Round(currency)
+			r.AverageFare[currency] = revenue.Mul(1 / float64(bookingsByCurrency[variant][currency])).Round(currency)
+		}
+		reports = append(reports, *r)
+	}
+	return reports, nil
+}
+
+// prepareExperiment checks what binding cannot.
+func prepareExperiment(experiment *models.PricingExperiment) error {
+	names := map[string]bool{}
+	for _, variant := range experiment.Variants {
+		if names[variant.Name] {
+			return fmt.Errorf("variant %q is defined twice", variant.Name)
+		}
+		names[variant.Name] = true
+	}
+	if experiment.StartsAt.IsZero() {
+		experiment.StartsAt = time.Now()
+	}
+	if experiment.EndsAt != nil && !experiment.EndsAt.After(experiment.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+func scanExperiment(row pgx.Row) (models.PricingExperiment, error) {
+	var experiment models.PricingExperiment
+	err := row.Scan(&experiment.ID, &experiment.Name, &experiment.Description, &experiment.VehicleType, &experiment.RegionID, &experiment.Variants,
+		&experiment.StartsAt, &experiment.EndsAt, &experiment.Active, &experiment.CreatedAt)
+	return experiment, err
+}
@@ -104,15 +104,30 @@ func (s *AdminService) GetDriverPerformance(c *gin.Context) {
 				vd.name,
 				COUNT(b.id) AS trip_count,
 				AVG(EXTRACT(EPOCH FROM (b.completed_at - b.created_at))) AS avg_trip_time,
-				SUM(CAST(b.price AS FLOAT)) AS total_revenue
+				SUM(CAST(b.price + b.adjustments_total AS FLOAT)) AS total_revenue,
+				COALESCE(r.avg_rating, 0) AS avg_rating,
+				COALESCE(r.rating_count, 0) AS rating_count,
+				COALESCE(e.earnings, 0) AS earnings
 			FROM 
 				vehicle_drivers vd
 			LEFT JOIN 
 				booking b ON vd.id = b.driver_id AND b.status = 'completed'
+			LEFT JOIN (
+				SELECT ratee_id, AVG(rating)::float8 AS avg_rating, COUNT(*) AS rating_count
+				FROM booking_ratings
+				WHERE rater_role = 'user'
+				GROUP BY ratee_id
+			) r ON r.ratee_id = vd.id
+			LEFT JOIN (
+				SELECT driver_id, SUM(amount) AS earnings
+				FROM ledger_entries
+				WHERE account = 'driver' AND kind != 'payout'
+				GROUP BY driver_id
+			) e ON e.driver_id = vd.id
 			WHERE
 				b.completed_at IS NOT NULL
 			GROUP BY 
-				vd.id, vd.name
+				vd.id, vd.name, r.avg_rating, r.rating_count, e.earnings
 			ORDER BY 
 				trip_count DESC
 		`)
@@ -123,7 +138,7 @@ func (s *AdminService) GetDriverPerformance(c *gin.Context) {
 
 		for rows.Next() {
 			var perf models.DriverPerformance
-			if err := rows.Scan(&perf.DriverID, &perf.Name, &perf.TripCount, &perf.AvgTripTime, &perf.TotalRevenue); err != nil {
+			if err := rows.Scan(&perf.DriverID, &perf.Name, &perf.TripCount, &perf.AvgTripTime, &perf.TotalRevenue, &perf.AvgRating, &perf.RatingCount, &perf.Earnings); err != nil {
 				return fmt.Errorf("failed to scan driver performance: %v", err)
 			}
 			perf.AvgTripTime = perf.AvgTripTime / 60
@@ -154,7 +169,7 @@ func (s *AdminService) GetBookingAnalytics(c *gin.Context) {
 				COUNT(*) FILTER (WHERE status = 'completed') AS completed_bookings,
 				COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled_bookings,
 				AVG(EXTRACT(EPOCH FROM (completed_at - created_at))) FILTER (WHERE status = 'completed') AS avg_trip_time,
-				SUM(CAST(price AS FLOAT)) FILTER (WHERE status = 'completed') AS total_revenue
+				SUM(CAST(price + adjustments_total AS FLOAT)) FILTER (WHERE status = 'completed') AS total_revenue
 			FROM booking
 			WHERE
 				completed_at IS NOT NULL
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/segmentio/kafka-go"
+
+	"logistics-platform/lib/models"
+)
+
+// ResendInvoice re-publishes the invoice_issued event for a booking so the
+// user is notified again and can download the invoice.
+func (s *AdminService) ResendInvoice(c *gin.Context) {
+	bookingID, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var userID, driverID int32
+	invoiceEvent := models.BookedNotification{Status: "invoice_issued", BookingID: int32(bookingID)}
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		err := s.pool.QueryRow(ctx, `
+			SELECT i.invoice_number, i.user_id, i.driver_id, vd.name
+			FROM invoices i
+			INNER JOIN vehicle_drivers vd ON vd.id = i.driver_id
+			WHERE i.booking_id = $1 AND i.original_invoice_id IS NULL
+		`, bookingID).Scan(&invoiceEvent.InvoiceNumber, &userID, &driverID, &invoiceEvent.DriverName)
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch invoice: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		return
+	}
+
+	invoiceEvent.UserID = strconv.Itoa(int(userID))
+	invoiceEvent.DriverID = strconv.Itoa(int(driverID))
+
+	invoiceEventJSON, err := json.Marshal(invoiceEvent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to encode invoice event: %v", err)})
+		return
+	}
+
+	if err := s.bookingWriter.WriteMessages(ctx, kafka.Message{Value: invoiceEventJSON}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to resend invoice: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invoice resent", "invoice_number": invoiceEvent.InvoiceNumber})
+}
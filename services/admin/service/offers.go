@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"logistics-platform/lib/models"
+)
+
+// offerIgnoredAfter is how long an offer can go unanswered before it counts as
+// ignored rather than still pending.
+const offerIgnoredAfter = 10 * time.Minute
+
+// GetDriverAcceptance reports how often each driver accepts, declines or
+// ignores the offers they are sent, with a breakdown of decline reasons.
+// Offers another driver accepted first are left out.
+func (s *AdminService) GetDriverAcceptance(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var acceptance []models.DriverAcceptance
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		acceptance = nil
+		byDriver := make(map[int]int)
+
+		rows, err := s.pool.Query(ctx, `
+			SELECT
+				vd.id,
+				vd.name,
+				COUNT(*) AS offers,
+				COUNT(*) FILTER (WHERE o.response = 'accepted') AS accepted,
+				COUNT(*) FILTER (WHERE o.response = 'declined') AS declined,
+				COUNT(*) FILTER (WHERE o.response IS NULL AND o.offered_at < NOW() - $1::INTERVAL) AS ignored
+			FROM driver_offers o
+			INNER JOIN vehicle_drivers vd ON vd.id = o.driver_id
+			WHERE o.response IS DISTINCT FROM 'taken'
+			GROUP BY vd.id, vd.name
+			ORDER BY offers DESC
+		`, offerIgnoredAfter.String())
+		if err != nil {
+			return fmt.Errorf("failed to fetch driver acceptance: %v", err)
+		}
+
+		for rows.Next() {
+			var stats models.DriverAcceptance
+			if err := rows.Scan(&stats.DriverID, &stats.Name, &stats.Offers, &stats.Accepted, &stats.Declined, &stats.Ignored); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan driver acceptance: %v", err)
+			}
+			if answered := stats.Accepted + stats.Declined + stats.Ignored; answered > 0 {
+				stats.AcceptanceRate = float64(stats.Accepted) / float64(answered)
+			}
+			stats.DeclineReasons = make(map[string]int)
+			byDriver[stats.DriverID] = len(acceptance)
+			acceptance = append(acceptance, stats)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read driver acceptance: %v", err)
+		}
+
+		rows, err = s.pool.Query(ctx, `
+			SELECT driver_id, reason, COUNT(*)
+			FROM driver_offers
+			WHERE response = 'declined'
+			GROUP BY driver_id, reason
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to fetch decline reasons: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var driverID, count int
+			var reason string
+			if err := rows.Scan(&driverID, &reason, &count); err != nil {
+				return fmt.Errorf("failed to scan decline reasons: %v", err)
+			}
+			if i, ok := byDriver[driverID]; ok {
+				acceptance[i].DeclineReasons[reason] = count
+			}
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, acceptance)
+}
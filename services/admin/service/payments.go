@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/payment"
+)
+
+const paymentColumns = `id, COALESCE(mongo_id, ''), COALESCE(booking_id, 0), user_id, provider, COALESCE(authorization_id, ''), amount, captured_amount,
+	refunded_amount, currency, status, COALESCE(failure_reason, ''), created_at, updated_at`
+
+// GetBookingPayment returns the card payment taken for a booking, without the
+// separate charges for its supplementary invoices.
+func (s *AdminService) GetBookingPayment(c *gin.Context) {
+	bookingID, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var record models.Payment
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		record, err = scanPayment(s.pool.QueryRow(ctx, "SELECT "+paymentColumns+" FROM payments WHERE booking_id = $1 AND invoice_id IS NULL", bookingID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch payment: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// RefundBooking refunds part or all of a booking's captured payment through
+// the payment provider. The payment row stays locked while the provider is
+// called, so concurrent refunds cannot exceed what was captured.
+func (s *AdminService) RefundBooking(c *gin.Context) {
+	bookingID, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	var refundReq models.RefundRequest
+	if err := c.ShouldBindJSON(&refundReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to refund payment: %v", err)})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	record, err := scanPayment(tx.QueryRow(ctx, "SELECT "+paymentColumns+" FROM payments WHERE booking_id = $1 AND invoice_id IS NULL FOR UPDATE", bookingID))
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch payment: %v", err)})
+		return
+	}
+
+	if record.Status != models.PaymentCaptured && record.Status != models.PaymentPartiallyRefunded {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("payment is %s and cannot be refunded", record.Status)})
+		return
+	}
+
+	remaining := record.CapturedAmount - record.RefundedAmount
+	amount := refundReq.Amount.Round(record.Currency)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %s can be refunded", remaining)})
+		return
+	}
+
+	result, err := s.gateway.Refund(ctx, record.AuthorizationID, amount)
+	if errors.Is(err, payment.ErrDeclined) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Failed to refund payment: %v", err)})
+		return
+	}
+
+	record.RefundedAmount += amount
+	record.Status = models.PaymentPartiallyRefunded
+	if record.RefundedAmount >= record.CapturedAmount {
+		record.Status = models.PaymentRefunded
+	}
+
+	if err := recordRefund(ctx, tx, record, int32(bookingID), result.ID, amount, refundReq.Reason); err != nil {
+		// the provider has refunded already; the refund id lets it be reconciled
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Refund %s was issued but could not be recorded: %v", result.ID, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payment refunded", "refund_id": result.ID, "amount": amount, "payment": record})
+}
+
+func recordRefund(ctx context.Context, tx pgx.Tx, record models.Payment, bookingID int32, refundID string, amount money.Amount, reason string) error {
+	if _, err := tx.Exec(ctx, "INSERT INTO payment_refunds (payment_id, refund_id, amount, reason) VALUES ($1, $2, $3, $4)",
+		record.ID, refundID, amount, reason); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE payments SET refunded_amount = $1, status = $2, updated_at = NOW() WHERE id = $3",
+		record.RefundedAmount, record.Status, record.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "UPDATE booking SET payment_status = $1 WHERE id = $2", record.Status, bookingID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func scanPayment(row pgx.Row) (models.Payment, error) {
+	var record models.Payment
+	err := row.Scan(&record.ID, &record.MongoID, &record.BookingID, &record.UserID, &record.Provider, &record.AuthorizationID, &record.Amount,
+		&record.CapturedAmount, &record.RefundedAmount, &record.Currency, &record.Status, &record.FailureReason, &record.CreatedAt, &record.UpdatedAt)
+	return record, err
+}
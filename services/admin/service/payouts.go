@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/ledger"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+)
+
+// GetPayoutStatements lists drivers' weekly payout statements, optionally
+// filtered by driver_id and status.
+func (s *AdminService) GetPayoutStatements(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ps.id, ps.driver_id, vd.name, to_char(ps.period_start, 'YYYY-MM-DD'), to_char(ps.period_end, 'YYYY-MM-DD'),
+			ps.trip_fares, ps.commission, ps.tips, ps.adjustments, ps.bonuses, ps.penalties, ps.amount, ps.status,
+			ps.settled_at, COALESCE(ps.settlement_reference, ''), ps.created_at
+		FROM payout_statements ps
+		INNER JOIN vehicle_drivers vd ON vd.id = ps.driver_id
+		WHERE ps.amount > 0`
+	var args []interface{}
+	for _, filter := range []struct{ param, column string }{
+		{"driver_id", "ps.driver_id"},
+		{"status", "ps.status"},
+	} {
+		if value := c.Query(filter.param); value != "" {
+			args = append(args, value)
+			query += fmt.Sprintf(" AND %s = $%d", filter.column, len(args))
+		}
+	}
+	query += " ORDER BY ps.period_start DESC, ps.driver_id"
+
+	statements := []models.PayoutStatement{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		statements = statements[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch payout statements: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var st models.PayoutStatement
+			if err := rows.Scan(&st.ID, &st.DriverID, &st.DriverName, &st.PeriodStart, &st.PeriodEnd, &st.TripFares, &st.Commission,
+				&st.Tips, &st.Adjustments, &st.Bonuses, &st.Penalties, &st.Amount, &st.Status, &st.SettledAt, &st.SettlementReference,
+				&st.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan payout statement: %v", err)
+			}
+			statements = append(statements, st)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statements)
+}
+
+// SettlePayout marks a pending statement as paid and debits the paid amount
+// from the driver's balance. reference is the bank or payment provider's id
+// for the transfer.
+func (s *AdminService) SettlePayout(c *gin.Context) {
+	statementID, err := strconv.Atoi(c.Param("statementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement id"})
+		return
+	}
+
+	var settlement models.PayoutSettlement
+	if err := c.ShouldBindJSON(&settlement); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		tx, err := s.pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		var driverID int32
+		var amount money.Amount
+		err = tx.QueryRow(ctx, `
+			UPDATE payout_statements SET status = 'settled', settled_at = NOW(), settlement_reference = $1
+			WHERE id = $2 AND status = 'pending' AND amount > 0
+			RETURNING driver_id, amount`, settlement.Reference, statementID).Scan(&driverID, &amount)
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := ledger.Post(ctx, tx, ledger.Payout(int32(statementID), driverID, amount)); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to settle payout: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pending statement not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payout settled"})
+}
+
+// AddDriverEarning credits a bonus to or debits a penalty from a driver. It
+// lands on the driver's next payout statement.
+func (s *AdminService) AddDriverEarning(c *gin.Context) {
+	driverID, err := strconv.Atoi(c.Param("driverId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid driver id"})
+		return
+	}
+
+	var earningReq models.EarningAdjustmentRequest
+	if err := c.ShouldBindJSON(&earningReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reference := earningReq.Reference
+	if reference == "" {
+		reference = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	txn := ledger.Incentive(fmt.Sprintf("%s:%d:%s", earningReq.Kind, driverID, reference), int32(driverID), earningReq.BookingID,
+		earningReq.Kind, earningReq.Description, earningReq.Amount)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	posted := false
+	err = retry(3, 100*time.Millisecond, func() error {
+		var exists bool
+		if err := s.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM vehicle_drivers WHERE id = $1)", driverID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			found = false
+			return nil
+		}
+
+		tx, err := s.pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if posted, err = ledger.Post(ctx, tx, txn); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to record %s: %v", earningReq.Kind, err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "driver not found"})
+		return
+	}
+
+	if !posted {
+		c.JSON(http.StatusOK, gin.H{"message": "Already recorded", "reference": txn.Reference})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": fmt.Sprintf("%s recorded", earningReq.Kind), "reference": txn.Reference})
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"logistics-platform/lib/models"
+)
+
+// GetDriverPreferences lists shippers' favourite and blocked drivers,
+// optionally filtered by user_id, driver_id and preference.
+func (s *AdminService) GetDriverPreferences(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT dp.user_id, dp.driver_id, vd.name, dp.preference, dp.created_at
+		FROM driver_preferences dp
+		INNER JOIN vehicle_drivers vd ON vd.id = dp.driver_id
+		WHERE 1 = 1`
+	var args []interface{}
+	for _, filter := range []struct{ param, column string }{
+		{"user_id", "dp.user_id"},
+		{"driver_id", "dp.driver_id"},
+		{"preference", "dp.preference"},
+	} {
+		if value := c.Query(filter.param); value != "" {
+			args = append(args, value)
+			query += fmt.Sprintf(" AND %s = $%d", filter.column, len(args))
+		}
+	}
+	query += " ORDER BY dp.created_at DESC"
+
+	preferences := []models.DriverPreference{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		preferences = preferences[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch driver preferences: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var preference models.DriverPreference
+			if err := rows.Scan(&preference.UserID, &preference.DriverID, &preference.DriverName, &preference.Preference, &preference.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan driver preference: %v", err)
+			}
+			preferences = append(preferences, preference)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+)
+
+const pricingRuleColumns = `id, COALESCE(region_id, 0), name, kind, days, dates, start_time, end_time, multiplier, priority, active, created_at`
+
+// GetPricingRules lists the peak, weekend and holiday pricing rules,
+// optionally filtered by region_id (0 for rules that apply everywhere) and
+// active.
+func (s *AdminService) GetPricingRules(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + pricingRuleColumns + " FROM pricing_rules WHERE TRUE"
+	var args []interface{}
+	if regionID := c.Query("region_id"); regionID != "" {
+		args = append(args, regionID)
+		query += fmt.Sprintf(" AND COALESCE(region_id, 0) = $%d", len(args))
+	}
+	if active := c.Query("active"); active != "" {
+		args = append(args, active)
+		query += fmt.Sprintf(" AND active = $%d", len(args))
+	}
+	query += " ORDER BY priority DESC, id"
+
+	rules := []models.PricingRule{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		rules = rules[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch pricing rules: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			rule, err := scanPricingRule(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan pricing rule: %v", err)
+			}
+			rules = append(rules, rule)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreatePricingRule adds a pricing rule, which applies to quotes as soon as
+// the pricing service reloads.
+func (s *AdminService) CreatePricingRule(c *gin.Context) {
+	var rule models.PricingRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := preparePricingRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		var err error
+		rule, err = scanPricingRule(s.pool.QueryRow(ctx, `
+			INSERT INTO pricing_rules (region_id, name, kind, days, dates, start_time, end_time, multiplier, priority)
+			VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING `+pricingRuleColumns,
+			rule.RegionID, rule.Name, rule.Kind, rule.Days, rule.Dates, rule.StartTime, rule.EndTime, rule.Multiplier, rule.Priority))
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create pricing rule: %v", err)})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePricingRule changes a pricing rule; setting active to false stops it
+// applying. Bookings already made keep the price they were quoted.
+func (s *AdminService) UpdatePricingRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("ruleId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pricing rule id"})
+		return
+	}
+
+	var rule models.PricingRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := preparePricingRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		rule, err = scanPricingRule(s.pool.QueryRow(ctx, `
+			UPDATE pricing_rules SET region_id = NULLIF($1, 0), name = $2, kind = $3, days = $4, dates = $5, start_time = $6, end_time = $7,
+				multiplier = $8, priority = $9, active = $10
+			WHERE id = $11
+			RETURNING `+pricingRuleColumns,
+			rule.RegionID, rule.Name, rule.Kind, rule.Days, rule.Dates, rule.StartTime, rule.EndTime, rule.Multiplier, rule.Priority,
+			rule.Active, ruleID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update pricing rule: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pricing rule not found"})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusOK, rule)
+}
+
+// preparePricingRule checks what binding cannot. Weekend rules without days
+// default to Saturday and Sunday.
+func preparePricingRule(rule *models.PricingRule) error {
+	if rule.Days == nil {
+		rule.Days = []int{}
+	}
+	if rule.Dates == nil {
+		rule.Dates = []string{}
+	}
+
+	switch rule.Kind {
+	case models.PricingRuleWeekend:
+		if len(rule.Days) == 0 {
+			rule.Days = []int{int(time.Saturday), int(time.Sunday)}
+		}
+	case models.PricingRuleHoliday:
+		if len(rule.Dates) == 0 {
+			return fmt.Errorf("holiday rules need dates")
+		}
+	}
+
+	// times are compared as strings, so 7:00 is stored as 07:00
+	for _, clock := range []*string{&rule.StartTime, &rule.EndTime} {
+		if parsed, err := time.Parse("15:04", *clock); err == nil {
+			*clock = parsed.Format("15:04")
+		}
+	}
+	// a window past midnight is two rules, one either side of it
+	if rule.StartTime != "" && rule.EndTime != "" && rule.EndTime <= rule.StartTime {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+func scanPricingRule(row pgx.Row) (models.PricingRule, error) {
+	var rule models.PricingRule
+	err := row.Scan(&rule.ID, &rule.RegionID, &rule.Name, &rule.Kind, &rule.Days, &rule.Dates, &rule.StartTime, &rule.EndTime, &rule.Multiplier,
+		&rule.Priority, &rule.Active, &rule.CreatedAt)
+	return rule, err
+}
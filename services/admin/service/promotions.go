@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/promotion"
+)
+
+// GetPromotions lists promo codes, optionally filtered by campaign and
+// active.
+func (s *AdminService) GetPromotions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + promotion.Columns + " FROM promotions WHERE TRUE"
+	var args []interface{}
+	for _, filter := range []struct{ param, column string }{
+		{"campaign", "campaign"},
+		{"active", "active"},
+	} {
+		if value := c.Query(filter.param); value != "" {
+			args = append(args, value)
+			query += fmt.Sprintf(" AND %s = $%d", filter.column, len(args))
+		}
+	}
+	query += " ORDER BY created_at DESC"
+
+	promotions := []models.Promotion{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		promotions = promotions[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch promotions: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			p, err := promotion.Scan(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan promotion: %v", err)
+			}
+			promotions = append(promotions, p)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, promotions)
+}
+
+// CreatePromotion adds a promo code. Codes are case-insensitive and unique
+// across campaigns.
+func (s *AdminService) CreatePromotion(c *gin.Context) {
+	var p models.Promotion
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := preparePromotion(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p.Active = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	duplicate := false
+	err := retry(3, 100*time.Millisecond, func() error {
+		err := s.pool.QueryRow(ctx, `
+			INSERT INTO promotions (code, campaign, description, discount_type, discount_value, max_discount, first_booking_only, per_user_limit,
+				max_redemptions, vehicle_types, zone_latitude, zone_longitude, zone_radius_km, starts_at, ends_at, currency)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''))
+			ON CONFLICT (code) DO NOTHING
+			RETURNING id, created_at`,
+			p.Code, p.Campaign, p.Description, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.FirstBookingOnly, p.PerUserLimit,
+			p.MaxRedemptions, p.VehicleTypes, p.Zone.Latitude, p.Zone.Longitude, p.ZoneRadius, p.StartsAt, p.EndsAt, p.Currency).Scan(&p.ID, &p.CreatedAt)
+		if err == pgx.ErrNoRows {
+			duplicate = true
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create promotion: %v", err)})
+		return
+	}
+
+	if duplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("promo code %s already exists", p.Code)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// UpdatePromotion replaces a promo code's terms. Redemptions already made
+// keep the discount they were given.
+func (s *AdminService) UpdatePromotion(c *gin.Context) {
+	promotionID, err := strconv.Atoi(c.Param("promotionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	var p models.Promotion
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := preparePromotion(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		p, err = promotion.Scan(s.pool.QueryRow(ctx, `
+			UPDATE promotions SET code = $1, campaign = $2, description = $3, discount_type = $4, discount_value = $5, max_discount = $6,
+				first_booking_only = $7, per_user_limit = $8, max_redemptions = $9, vehicle_types = $10, zone_latitude = $11,
+				zone_longitude = $12, zone_radius_km = $13, starts_at = $14, ends_at = $15, active = $16, currency = NULLIF($17, '')
+			WHERE id = $18
+			RETURNING `+promotion.Columns,
+			p.Code, p.Campaign, p.Description, p.DiscountType, p.DiscountValue, p.MaxDiscount, p.FirstBookingOnly, p.PerUserLimit,
+			p.MaxRedemptions, p.VehicleTypes, p.Zone.Latitude, p.Zone.Longitude, p.ZoneRadius, p.StartsAt, p.EndsAt, p.Active, p.Currency, promotionID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update promotion: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// DeactivatePromotion stops a promo code from being quoted or redeemed.
+// It is kept for its redemption history.
+func (s *AdminService) DeactivatePromotion(c *gin.Context) {
+	promotionID, err := strconv.Atoi(c.Param("promotionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var rowsAffected int64
+	err = retry(3, 100*time.Millisecond, func() error {
+		pgComm, err := s.pool.Exec(ctx, "UPDATE promotions SET active = FALSE WHERE id = $1", promotionID)
+		rowsAffected = pgComm.RowsAffected()
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to deactivate promotion: %v", err)})
+		return
+	}
+
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "promotion not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deactivated"})
+}
+
+// GetPromotionRedemptions lists the bookings a promo code was redeemed on.
+func (s *AdminService) GetPromotionRedemptions(c *gin.Context) {
+	promotionID, err := strconv.Atoi(c.Param("promotionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	redemptions := []models.PromotionRedemption{}
+
+	err = retry(3, 100*time.Millisecond, func() error {
+		redemptions = redemptions[:0]
+
+		rows, err := s.pool.Query(ctx, `
+			SELECT id, promotion_id, user_id, booking_id, discount, redeemed_at
+			FROM promotion_redemptions WHERE promotion_id = $1 ORDER BY redeemed_at DESC`, promotionID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch redemptions: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var r models.PromotionRedemption
+			if err := rows.Scan(&r.ID, &r.PromotionID, &r.UserID, &r.BookingID, &r.Discount, &r.RedeemedAt); err != nil {
+				return fmt.Errorf("failed to scan redemption: %v", err)
+			}
+			redemptions = append(redemptions, r)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
+
+// preparePromotion normalises an admin's promotion and checks what binding
+// cannot.
+func preparePromotion(p *models.Promotion) error {
+	p.Code = promotion.NormaliseCode(p.Code)
+	if p.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if p.DiscountType == models.DiscountPercentage && p.DiscountValue > 100 {
+		return fmt.Errorf("percentage discounts cannot exceed 100")
+	}
+	if p.StartsAt.IsZero() {
+		p.StartsAt = time.Now()
+	}
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if p.VehicleTypes == nil {
+		p.VehicleTypes = []string{}
+	}
+	p.Currency = strings.ToUpper(p.Currency)
+	return nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+)
+
+const regionColumns = `id, name, country, currency, polygon, tax_name, tax_rate, tax_inclusive, timezone, active, created_at`
+
+// GetRegions lists the pricing regions, optionally filtered by active.
+func (s *AdminService) GetRegions(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + regionColumns + " FROM pricing_regions"
+	var args []interface{}
+	if active := c.Query("active"); active != "" {
+		args = append(args, active)
+		query += " WHERE active = $1"
+	}
+	query += " ORDER BY id"
+
+	regions := []models.Region{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		regions = regions[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch regions: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			region, err := scanRegion(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan region: %v", err)
+			}
+			regions = append(regions, region)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, regions)
+}
+
+// CreateRegion adds a pricing region. Pickups inside it are priced in its
+// currency, so it needs rate cards of its own unless that is the default
+// currency.
+func (s *AdminService) CreateRegion(c *gin.Context) {
+	var region models.Region
+	if err := c.ShouldBindJSON(&region); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := prepareRegion(&region); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		var err error
+		region, err = scanRegion(s.pool.QueryRow(ctx, `
+			INSERT INTO pricing_regions (name, country, currency, polygon, tax_name, tax_rate, tax_inclusive, timezone)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING `+regionColumns,
+			region.Name, region.Country, region.Currency, region.Polygon, region.TaxName, region.TaxRate, region.TaxInclusive, region.Timezone))
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create region: %v", err)})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusCreated, region)
+}
+
+// UpdateRegion redraws a region or changes its tax rule; setting active to
+// false prices its pickups by default again. Bookings already made keep the
+// currency and tax they were priced under.
+func (s *AdminService) UpdateRegion(c *gin.Context) {
+	regionID, err := strconv.Atoi(c.Param("regionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid region id"})
+		return
+	}
+
+	var region models.Region
+	if err := c.ShouldBindJSON(&region); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := prepareRegion(&region); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		region, err = scanRegion(s.pool.QueryRow(ctx, `
+			UPDATE pricing_regions SET name = $1, country = $2, currency = $3, polygon = $4, tax_name = $5, tax_rate = $6, tax_inclusive = $7,
+				timezone = $8, active = $9
+			WHERE id = $10
+			RETURNING `+regionColumns,
+			region.Name, region.Country, region.Currency, region.Polygon, region.TaxName, region.TaxRate, region.TaxInclusive, region.Timezone,
+			region.Active, regionID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update region: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "region not found"})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusOK, region)
+}
+
+func prepareRegion(region *models.Region) error {
+	region.Country = strings.ToUpper(region.Country)
+	region.Currency = strings.ToUpper(region.Currency)
+	if region.TaxName == "" {
+		region.TaxName = "Tax"
+	}
+	if region.Timezone != "" {
+		if _, err := time.LoadLocation(region.Timezone); err != nil {
+			return fmt.Errorf("unknown timezone %q", region.Timezone)
+		}
+	}
+	return nil
+}
+
+func scanRegion(row pgx.Row) (models.Region, error) {
+	var region models.Region
+	err := row.Scan(&region.ID, &region.Name, &region.Country, &region.Currency, &region.Polygon, &region.TaxName, &region.TaxRate,
+		&region.TaxInclusive, &region.Timezone, &region.Active, &region.CreatedAt)
+	return region, err
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+)
+
+const surgeZoneColumns = `id, name, polygon, max_multiplier, active, created_at`
+
+// GetSurgeZones lists the surge zones, optionally filtered by active.
+func (s *AdminService) GetSurgeZones(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + surgeZoneColumns + " FROM surge_zones"
+	var args []interface{}
+	if active := c.Query("active"); active != "" {
+		args = append(args, active)
+		query += " WHERE active = $1"
+	}
+	query += " ORDER BY id"
+
+	zones := []models.SurgeZone{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		zones = zones[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch surge zones: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			zone, err := scanSurgeZone(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan surge zone: %v", err)
+			}
+			zones = append(zones, zone)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, zones)
+}
+
+// CreateSurgeZone adds a zone. The pricing service picks it up on its next
+// surge run.
+func (s *AdminService) CreateSurgeZone(c *gin.Context) {
+	var zone models.SurgeZone
+	if err := c.ShouldBindJSON(&zone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		var err error
+		zone, err = scanSurgeZone(s.pool.QueryRow(ctx,
+			"INSERT INTO surge_zones (name, polygon, max_multiplier) VALUES ($1, $2, $3) RETURNING "+surgeZoneColumns,
+			zone.Name, zone.Polygon, zone.MaxMultiplier))
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create surge zone: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, zone)
+}
+
+// UpdateSurgeZone redraws a zone or changes its cap; setting active to false
+// stops surge being computed for it.
+func (s *AdminService) UpdateSurgeZone(c *gin.Context) {
+	zoneID, err := strconv.Atoi(c.Param("zoneId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone id"})
+		return
+	}
+
+	var zone models.SurgeZone
+	if err := c.ShouldBindJSON(&zone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		zone, err = scanSurgeZone(s.pool.QueryRow(ctx,
+			"UPDATE surge_zones SET name = $1, polygon = $2, max_multiplier = $3, active = $4 WHERE id = $5 RETURNING "+surgeZoneColumns,
+			zone.Name, zone.Polygon, zone.MaxMultiplier, zone.Active, zoneID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update surge zone: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "surge zone not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+func scanSurgeZone(row pgx.Row) (models.SurgeZone, error) {
+	var zone models.SurgeZone
+	err := row.Scan(&zone.ID, &zone.Name, &zone.Polygon, &zone.MaxMultiplier, &zone.Active, &zone.CreatedAt)
+	return zone, err
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+
+	"logistics-platform/lib/models"
+)
+
+// vehiclePricingChannel tells pricing instances to reload their rate cards
+// and regions.
+const vehiclePricingChannel = "vehicle_pricing_updated"
+
+const vehiclePricingColumns = `id, COALESCE(region_id, 0), vehicle_type, version, base_price, price_per_km, price_per_minute, price_per_kg, price_per_cubic_metre,
+	minimum_charges, fragile_surcharge, hazardous_surcharge, loading_assistance_fee, retired, effective_from, created_at`
+
+// GetVehiclePricing lists every version of the vehicle rate cards, optionally
+// filtered by vehicle_type and region_id, where 0 is the default rate cards.
+func (s *AdminService) GetVehiclePricing(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := "SELECT " + vehiclePricingColumns + " FROM vehicle_pricing WHERE TRUE"
+	var args []interface{}
+	for _, filter := range []struct{ param, column string }{
+		{"vehicle_type", "vehicle_type"},
+		{"region_id", "COALESCE(region_id, 0)"},
+	} {
+		if value := c.Query(filter.param); value != "" {
+			args = append(args, value)
+			query += fmt.Sprintf(" AND %s = $%d", filter.column, len(args))
+		}
+	}
+	query += " ORDER BY region_id NULLS FIRST, vehicle_type, version DESC"
+
+	versions := []models.VehiclePricing{}
+
+	err := retry(3, 100*time.Millisecond, func() error {
+		versions = versions[:0]
+
+		rows, err := s.pool.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to fetch vehicle pricing: %v", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			vp, err := scanVehiclePricing(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan vehicle pricing: %v", err)
+			}
+			versions = append(versions, vp)
+		}
+
+		return rows.Err()
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// effectiveFromSkew is how far in the past a new rate card's effective_from
+// may be, to allow for the client's clock; it takes effect immediately.
+const effectiveFromSkew = time.Minute
+
+// CreateVehiclePricing adds the next version of a vehicle type's rate card in
+// a region, or by default when region_id is omitted, or the first version of
+// a new vehicle type. It takes effect at effective_from, which cannot be in
+// the past, or immediately when that is omitted.
+func (s *AdminService) CreateVehiclePricing(c *gin.Context) {
+	var vp models.VehiclePricing
+	if err := c.ShouldBindJSON(&vp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// a version backdated into the past would reprice quotes and invoices
+	// already made on the version it replaces; a minute is allowed for clock
+	// skew
+	now := time.Now()
+	if !vp.EffectiveFrom.IsZero() && vp.EffectiveFrom.Before(now.Add(-effectiveFromSkew)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from cannot be in the past"})
+		return
+	}
+	if vp.EffectiveFrom.Before(now) {
+		vp.EffectiveFrom = now
+	}
+	minimumCharges, err := marshalMinimumCharges(vp.MinimumCharges)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// a concurrent version for the same type fails the unique constraint and
+	// is retried with the next number
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		vp, err = scanVehiclePricing(s.pool.QueryRow(ctx, `
+			INSERT INTO vehicle_pricing (region_id, vehicle_type, version, base_price, price_per_km, price_per_minute, price_per_kg, price_per_cubic_metre,
+				minimum_charges, fragile_surcharge, hazardous_surcharge, loading_assistance_fee, retired, effective_from)
+			SELECT NULLIF($1, 0), $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13 FROM vehicle_pricing
+			WHERE vehicle_type = $2 AND COALESCE(region_id, 0) = $1
+			RETURNING `+vehiclePricingColumns,
+			vp.RegionID, vp.Type, vp.BasePrice, vp.PricePerKm, vp.PricePerMinute, vp.PricePerKg, vp.PricePerCubicMetre, minimumCharges,
+			vp.FragileSurcharge, vp.HazardousSurcharge, vp.LoadingAssistanceFee, vp.Retired, vp.EffectiveFrom))
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create vehicle pricing: %v", err)})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusCreated, vp)
+}
+
+// UpdateVehiclePricing edits a rate card version that has not taken effect
+// yet. Versions already in effect are kept as they were, so past quotes and
+// invoices can be explained; changes to them go in a new version.
+func (s *AdminService) UpdateVehiclePricing(c *gin.Context) {
+	pricingID, err := strconv.Atoi(c.Param("pricingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pricing id"})
+		return
+	}
+
+	var vp models.VehiclePricing
+	if err := c.ShouldBindJSON(&vp); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !vp.EffectiveFrom.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be in the future"})
+		return
+	}
+	minimumCharges, err := marshalMinimumCharges(vp.MinimumCharges)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	found := true
+	err = retry(3, 100*time.Millisecond, func() error {
+		var err error
+		vp, err = scanVehiclePricing(s.pool.QueryRow(ctx, `
+			UPDATE vehicle_pricing SET base_price = $1, price_per_km = $2, price_per_minute = $3, price_per_kg = $4, price_per_cubic_metre = $5,
+				minimum_charges = $6, fragile_surcharge = $7, hazardous_surcharge = $8, loading_assistance_fee = $9, retired = $10, effective_from = $11
+			WHERE id = $12 AND effective_from > NOW()
+			RETURNING `+vehiclePricingColumns,
+			vp.BasePrice, vp.PricePerKm, vp.PricePerMinute, vp.PricePerKg, vp.PricePerCubicMetre, minimumCharges, vp.FragileSurcharge,
+			vp.HazardousSurcharge, vp.LoadingAssistanceFee, vp.Retired, vp.EffectiveFrom, pricingID))
+		if err == pgx.ErrNoRows {
+			found = false
+			return nil
+		}
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update vehicle pricing: %v", err)})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusConflict, gin.H{"error": "pricing version not found or already in effect"})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusOK, vp)
+}
+
+// DeleteVehiclePricing withdraws a rate card version that has not taken
+// effect yet.
+func (s *AdminService) DeleteVehiclePricing(c *gin.Context) {
+	pricingID, err := strconv.Atoi(c.Param("pricingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pricing id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var rowsAffected int64
+	err = retry(3, 100*time.Millisecond, func() error {
+		pgComm, err := s.pool.Exec(ctx, "DELETE FROM vehicle_pricing WHERE id = $1 AND effective_from > NOW()", pricingID)
+		rowsAffected = pgComm.RowsAffected()
+		return err
+	})
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete vehicle pricing: %v", err)})
+		return
+	}
+
+	if rowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "pricing version not found or already in effect"})
+		return
+	}
+
+	s.announceVehiclePricing(ctx)
+	c.JSON(http.StatusOK, gin.H{"message": "Pricing version deleted"})
+}
+
+// announceVehiclePricing asks the pricing service to reload. It reloads
+// periodically anyway, so a failed announcement only delays the change.
+func (s *AdminService) announceVehiclePricing(ctx context.Context) {
+	if err := s.redisClient.Publish(ctx, vehiclePricingChannel, time.Now().Unix()).Err(); err != nil {
+		log.Printf("Error announcing vehicle pricing change: %v", err)
+	}
+}
+
+// marshalMinimumCharges stores a rate card's minimum charge tiers, none as an
+// empty list.
+func marshalMinimumCharges(tiers []models.MinimumCharge) ([]byte, error) {
+	if tiers == nil {
+		tiers = []models.MinimumCharge{}
+	}
+	return json.Marshal(tiers)
+}
+
+func scanVehiclePricing(row pgx.Row) (models.VehiclePricing, error) {
+	var vp models.VehiclePricing
+	var minimumCharges []byte
+	err := row.Scan(&vp.ID, &vp.RegionID, &vp.Type, &vp.Version, &vp.BasePrice, &vp.PricePerKm, &vp.PricePerMinute, &vp.PricePerKg,
+		&vp.PricePerCubicMetre, &minimumCharges, &vp.FragileSurcharge, &vp.HazardousSurcharge, &vp.LoadingAssistanceFee, &vp.Retired,
+		&vp.EffectiveFrom, &vp.CreatedAt)
+	if err != nil {
+		return vp, err
+	}
+	err = json.Unmarshal(minimumCharges, &vp.MinimumCharges)
+	return vp, err
+}
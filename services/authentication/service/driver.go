@@ -58,6 +58,14 @@ func (s *authService) GetDriverProfile(c *gin.Context) {
 		return
 	}
 
+	// shippers rate the drivers who carried their bookings
+	rating, err := s.getRatingSummary(models.RaterRoleUser, driver.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get driver rating", "message": err.Error()})
+		return
+	}
+	driver.Rating = &rating
+
 	c.JSON(http.StatusOK, gin.H{"driver": driver})
 }
 
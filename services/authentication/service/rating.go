@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+	"logistics-platform/lib/models"
+)
+
+// getRatingSummary aggregates the ratings left by raterRole for rateeID.
+func (s *authService) getRatingSummary(raterRole string, rateeID int32) (models.RatingSummary, error) {
+	var summary models.RatingSummary
+	err := s.db.QueryRow(
+		context.Background(),
+		"SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*) FROM booking_ratings WHERE rater_role = $1 AND ratee_id = $2",
+		raterRole, rateeID,
+	).Scan(&summary.AverageRating, &summary.RatingCount)
+	return summary, err
+}
@@ -81,5 +81,13 @@ func (s *authService) GetProfile(c *gin.Context) {
 		return
 	}
 
+	// drivers rate the shippers they carried for
+	rating, err := s.getRatingSummary(models.RaterRoleDriver, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user rating", "message": err.Error()})
+		return
+	}
+	user.Rating = &rating
+
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
@@ -21,5 +21,40 @@ type BookingInterface interface {
 	HandleDriverBookingCheck(c *gin.Context)
 	HandleDriverBookingHistory(c *gin.Context)
 	GetVehicleType(driverID string) (string, error)
+	HandleUserRating(c *gin.Context)
+	HandleDriverRating(c *gin.Context)
+	GetDriverRating(driverID string) (models.RatingSummary, error)
+	HandleUserInvoice(c *gin.Context)
+	GenerateInvoice(bookingID int32) (models.Invoice, error)
+	HandleUserTip(c *gin.Context)
+	HandleDriverAdjustment(c *gin.Context)
+	HandleAdjustmentDecision(c *gin.Context)
+	HandleUserAdjustments(c *gin.Context)
+	HandleDriverAdjustments(c *gin.Context)
+	HandleCreateOrganisation(c *gin.Context)
+	HandleUpdateOrganisation(c *gin.Context)
+	HandleAddOrganisationMember(c *gin.Context)
+	HandleAddCostCentre(c *gin.Context)
+	HandleListCostCentres(c *gin.Context)
+	HandleListApprovals(c *gin.Context)
+	HandleApprovalDecision(c *gin.Context)
+	HandleOrganisationStatement(c *gin.Context)
+	HandleBulkBookingRequest(c *gin.Context)
+	HandleBulkJobStatus(c *gin.Context)
+	HandleCreateRecurringBooking(c *gin.Context)
+	HandleListRecurringBookings(c *gin.Context)
+	HandleListOccurrences(c *gin.Context)
+	HandleAddRecurringExceptions(c *gin.Context)
+	HandleCancelRecurringBooking(c *gin.Context)
+	RunRecurringScheduler()
+	HandleListDriverPreferences(c *gin.Context)
+	HandleSetDriverPreference(c *gin.Context)
+	HandleRemoveDriverPreference(c *gin.Context)
+	ConsumeOfferActions()
+	HandleDriverEarnings(c *gin.Context)
+	HandleDriverStatements(c *gin.Context)
+	HandleDriverStatement(c *gin.Context)
+	RunPayoutScheduler()
+	HandlePaymentWebhook(c *gin.Context)
 	GracefulShutdown(server *http.Server)
 }
@@ -9,6 +9,7 @@ import (
 	"logistics-platform/lib/config"
 	"logistics-platform/lib/database"
 	"logistics-platform/lib/middlewares/cors"
+	"logistics-platform/lib/payment"
 	"logistics-platform/services/booking/router"
 	"logistics-platform/services/booking/service"
 
@@ -49,7 +50,12 @@ func main() {
 	}
 	defer pool.Close()
 
-	service := service.NewBookingService(mongoClient, redisClient, pool)
+	paymentGateway, err := payment.NewGateway(config.GetPaymentProvider(), config.GetPaymentWebhookSecret(), config.IsDevelopment())
+	if err != nil {
+		log.Fatalf("Failed to set up payment gateway: %v", err)
+	}
+
+	service := service.NewBookingService(mongoClient, redisClient, pool, paymentGateway)
 
 	r := gin.Default()
 	r.Use(cors.CORSMiddleware())
@@ -60,6 +66,10 @@ func main() {
 		Handler: r,
 	}
 
+	go service.RunRecurringScheduler()
+	go service.RunPayoutScheduler()
+	go service.ConsumeOfferActions()
+
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
@@ -13,11 +13,27 @@ func SetupRouter(router *gin.Engine, service interfaces.BookingInterface) {
 		c.String(http.StatusOK, "pong")
 	})
 
+	// called by the payment provider, which authenticates with a signature
+	router.POST("/payments/webhook", service.HandlePaymentWebhook)
+
 	userGroup := router.Group("/user")
 	userGroup.Use(auth.AuthInjectionMiddleware())
 	{
 		userGroup.GET("/booking", service.HandleUserBookingCheck)
 		userGroup.GET("/booking-history", service.HandleUserBookingHistory)
+		userGroup.POST("/booking/:id/rating", service.HandleUserRating)
+		userGroup.GET("/booking/:id/invoice", service.HandleUserInvoice)
+		userGroup.POST("/booking/:id/tip", service.HandleUserTip)
+		userGroup.GET("/booking/:id/adjustments", service.HandleUserAdjustments)
+		userGroup.POST("/booking/:id/adjustments/:adjustmentId", service.HandleAdjustmentDecision)
+		userGroup.POST("/recurring-bookings", service.HandleCreateRecurringBooking)
+		userGroup.GET("/recurring-bookings", service.HandleListRecurringBookings)
+		userGroup.GET("/recurring-bookings/:id/occurrences", service.HandleListOccurrences)
+		userGroup.POST("/recurring-bookings/:id/exceptions", service.HandleAddRecurringExceptions)
+		userGroup.DELETE("/recurring-bookings/:id", service.HandleCancelRecurringBooking)
+		userGroup.GET("/driver-preferences", service.HandleListDriverPreferences)
+		userGroup.PUT("/driver-preferences/:driverId", service.HandleSetDriverPreference)
+		userGroup.DELETE("/driver-preferences/:driverId", service.HandleRemoveDriverPreference)
 	}
 
 	driverGroup := router.Group("/driver")
@@ -25,12 +41,33 @@ func SetupRouter(router *gin.Engine, service interfaces.BookingInterface) {
 	{
 		driverGroup.GET("/booking", service.HandleDriverBookingCheck)
 		driverGroup.GET("/booking-history", service.HandleDriverBookingHistory)
+		driverGroup.POST("/booking/:id/rating", service.HandleDriverRating)
+		driverGroup.GET("/booking/:id/adjustments", service.HandleDriverAdjustments)
+		driverGroup.POST("/booking/:id/adjustments", service.HandleDriverAdjustment)
+		driverGroup.GET("/earnings", service.HandleDriverEarnings)
+		driverGroup.GET("/earnings/statements", service.HandleDriverStatements)
+		driverGroup.GET("/earnings/statements/:id", service.HandleDriverStatement)
+	}
+
+	orgGroup := router.Group("/organisation")
+	orgGroup.Use(auth.AuthInjectionMiddleware())
+	{
+		orgGroup.POST("", service.HandleCreateOrganisation)
+		orgGroup.PATCH("", service.HandleUpdateOrganisation)
+		orgGroup.POST("/members", service.HandleAddOrganisationMember)
+		orgGroup.GET("/cost-centres", service.HandleListCostCentres)
+		orgGroup.POST("/cost-centres", service.HandleAddCostCentre)
+		orgGroup.GET("/approvals", service.HandleListApprovals)
+		orgGroup.POST("/approvals/:id", service.HandleApprovalDecision)
+		orgGroup.GET("/statements/:month", service.HandleOrganisationStatement)
 	}
 
 	router.Use(auth.AuthInjectionMiddleware())
 	{
 		router.POST("/booking/accept", service.HandleBookingAccept)
 		router.POST("/booking", service.HandleBookingRequest)
+		router.POST("/booking/bulk", service.HandleBulkBookingRequest)
+		router.GET("/booking/bulk/:jobId", service.HandleBulkJobStatus)
 		router.PATCH("/booking/:userId", service.HandleBookingUpdate)
 	}
 }
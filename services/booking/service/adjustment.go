@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logistics-platform/lib/ledger"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+)
+
+// adjustmentWindow is how long after completion a booking can be tipped or
+// adjusted.
+const adjustmentWindow = 72 * time.Hour
+
+// Default adjustment rates, overridable through ADJUSTMENT_WAITING_RATE (per
+// minute), ADJUSTMENT_EXTRA_STOP_FEE and ADJUSTMENT_LOADING_FEE.
+const (
+	defaultWaitingRate  = 0.5
+	defaultExtraStopFee = 5.0
+	defaultLoadingFee   = 10.0
+
+	maxWaitingMinutes = 600
+	maxExtraStops     = 10
+)
+
+var (
+	errBookingNotFound    = errors.New("booking not found")
+	errAdjustmentsClosed  = errors.New("booking can only be adjusted within 72 hours of completion")
+	errAdjustmentNotFound = errors.New("adjustment not found")
+	errLoadingBooked      = errors.New("loading assistance was booked and is already charged")
+)
+
+type adjustableBooking struct {
+	userID            int32
+	driverID          int32
+	price             money.Amount
+	currency          string
+	loadingAssistance bool
+}
+
+func (s *BookingService) HandleUserTip(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var tipReq models.TipRequest
+	if err := c.ShouldBindJSON(&tipReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	booking, bookingID, ok := s.adjustableBookingFromRequest(ctx, c, models.ChatRoleUser, user.UserID)
+	if !ok {
+		return
+	}
+
+	if tipReq.Amount > booking.price {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tip cannot be more than the trip price"})
+		return
+	}
+
+	adjustment := models.BookingAdjustment{
+		BookingID:   bookingID,
+		Kind:        models.AdjustmentTip,
+		Description: "Tip for the driver",
+		Amount:      tipReq.Amount.Round(booking.currency),
+		Status:      models.AdjustmentApproved,
+		RequestedBy: models.ChatRoleUser,
+	}
+	if err := s.storeAdjustment(ctx, &adjustment, booking); isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": "booking already has a tip"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing tip"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"adjustment": adjustment})
+}
+
+// HandleDriverAdjustment records a driver-reported charge. Waiting time and
+// extra stops are priced by quantity, loading and unloading are fixed
+// surcharges that cannot be reported when loading assistance was booked with
+// the trip, and every charge waits for the user's approval.
+func (s *BookingService) HandleDriverAdjustment(c *gin.Context) {
+	authDriver, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	driver, _ := authDriver.(models.UserRequest)
+
+	var adjustmentReq models.AdjustmentRequest
+	if err := c.ShouldBindJSON(&adjustmentReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	booking, bookingID, ok := s.adjustableBookingFromRequest(ctx, c, models.ChatRoleDriver, driver.UserID)
+	if !ok {
+		return
+	}
+
+	if booking.loadingAssistance && (adjustmentReq.Kind == models.AdjustmentLoading || adjustmentReq.Kind == models.AdjustmentUnloading) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errLoadingBooked.Error()})
+		return
+	}
+
+	adjustment, err := priceAdjustment(adjustmentReq, booking.currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustment.BookingID = bookingID
+	adjustment.RequestedBy = models.ChatRoleDriver
+	if err := s.storeAdjustment(ctx, &adjustment, booking); isUniqueViolation(err) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("booking already has a %s surcharge", adjustment.Kind)})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing adjustment"})
+		return
+	}
+
+	if adjustment.Status == models.AdjustmentPending {
+		go s.writeBookingEvent(models.BookedNotification{
+			UserID:     strconv.Itoa(int(booking.userID)),
+			DriverID:   driver.UserID,
+			DriverName: driver.UserName,
+			Status:     "adjustment_requested",
+			BookingID:  bookingID,
+		})
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"adjustment": adjustment})
+}
+
+// HandleAdjustmentDecision lets the user approve or reject a pending
+// adjustment reported by the driver.
+func (s *BookingService) HandleAdjustmentDecision(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var decision models.AdjustmentDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	booking, bookingID, ok := s.adjustableBookingFromRequest(ctx, c, models.ChatRoleUser, user.UserID)
+	if !ok {
+		return
+	}
+
+	status := models.AdjustmentRejected
+	if decision.Approve {
+		status = models.AdjustmentApproved
+	}
+
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deciding adjustment"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	adjustment := models.BookingAdjustment{BookingID: bookingID}
+	err = tx.QueryRow(ctx, `
+		UPDATE booking_adjustments SET status = $1, decided_at = NOW()
+		WHERE id = $2 AND booking_id = $3 AND status = 'pending'
+		RETURNING id, kind, description, quantity, unit, amount, status, requested_by, created_at, decided_at`,
+		status, c.Param("adjustmentId"), bookingID).Scan(&adjustment.ID, &adjustment.Kind, &adjustment.Description, &adjustment.Quantity,
+		&adjustment.Unit, &adjustment.Amount, &adjustment.Status, &adjustment.RequestedBy, &adjustment.CreatedAt, &adjustment.DecidedAt)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": errAdjustmentNotFound.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deciding adjustment"})
+		return
+	}
+
+	if decision.Approve {
+		if err := applyAdjustment(ctx, tx, adjustment, booking); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error deciding adjustment"})
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deciding adjustment"})
+		return
+	}
+
+	if decision.Approve {
+		go s.invoiceAdjustments(bookingID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjustment": adjustment})
+}
+
+func (s *BookingService) HandleUserAdjustments(c *gin.Context) {
+	s.handleListAdjustments(c, models.ChatRoleUser)
+}
+
+func (s *BookingService) HandleDriverAdjustments(c *gin.Context) {
+	s.handleListAdjustments(c, models.ChatRoleDriver)
+}
+
+func (s *BookingService) handleListAdjustments(c *gin.Context, role string) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	caller, _ := authUser.(models.UserRequest)
+
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx := context.Background()
+	if _, err := s.adjustableBooking(ctx, int32(bookingID), role, caller.UserID); err == errBookingNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil && err != errAdjustmentsClosed {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching booking"})
+		return
+	}
+
+	adjustments, err := s.loadAdjustments(ctx, int32(bookingID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching adjustments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"adjustments": adjustments})
+}
+
+// adjustableBookingFromRequest resolves the :id booking for a tip or
+// adjustment, writing the error response itself when the booking cannot be
+// adjusted by the caller.
+func (s *BookingService) adjustableBookingFromRequest(ctx context.Context, c *gin.Context, role, callerID string) (adjustableBooking, int32, bool) {
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return adjustableBooking{}, 0, false
+	}
+
+	booking, err := s.adjustableBooking(ctx, int32(bookingID), role, callerID)
+	switch err {
+	case nil:
+		return booking, int32(bookingID), true
+	case errBookingNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errAdjustmentsClosed:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching booking"})
+	}
+	return adjustableBooking{}, 0, false
+}
+
+// adjustableBooking loads a booking of the caller and checks that it completed
+// within the adjustment window.
+func (s *BookingService) adjustableBooking(ctx context.Context, bookingID int32, role, callerID string) (adjustableBooking, error) {
+	var booking adjustableBooking
+	var status string
+	var completedAt *time.Time
+	err := s.PostgreSQLConn.QueryRow(ctx,
+		"SELECT user_id, driver_id, price, COALESCE(currency, ''), loading_assistance, status, completed_at FROM booking WHERE id = $1", bookingID).
+		Scan(&booking.userID, &booking.driverID, &booking.price, &booking.currency, &booking.loadingAssistance, &status, &completedAt)
+	if err == pgx.ErrNoRows {
+		return adjustableBooking{}, errBookingNotFound
+	} else if err != nil {
+		return adjustableBooking{}, err
+	}
+
+	partyID := booking.userID
+	if role == models.ChatRoleDriver {
+		partyID = booking.driverID
+	}
+	if strconv.Itoa(int(partyID)) != callerID {
+		return adjustableBooking{}, errBookingNotFound
+	}
+
+	if status != "completed" || completedAt == nil || time.Since(*completedAt) > adjustmentWindow {
+		return booking, errAdjustmentsClosed
+	}
+
+	return booking, nil
+}
+
+// storeAdjustment inserts an adjustment and, when it is approved already,
+// applies it in the same transaction, then bills it on a supplementary
+// invoice if the booking is invoiced.
+func (s *BookingService) storeAdjustment(ctx context.Context, adjustment *models.BookingAdjustment, booking adjustableBooking) error {
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO booking_adjustments (booking_id, kind, description, quantity, unit, amount, status, requested_by, decided_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $7 = 'approved' THEN NOW() END)
+		RETURNING id, created_at, decided_at`,
+		adjustment.BookingID, adjustment.Kind, adjustment.Description, adjustment.Quantity, adjustment.Unit, adjustment.Amount,
+		adjustment.Status, adjustment.RequestedBy).Scan(&adjustment.ID, &adjustment.CreatedAt, &adjustment.DecidedAt)
+	if err != nil {
+		return err
+	}
+
+	if adjustment.Status == models.AdjustmentApproved {
+		if err := applyAdjustment(ctx, tx, *adjustment, booking); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if adjustment.Status == models.AdjustmentApproved {
+		go s.invoiceAdjustments(adjustment.BookingID)
+	}
+	return nil
+}
+
+// applyAdjustment adds an approved adjustment to the booking's total and
+// credits the driver for it.
+func applyAdjustment(ctx context.Context, tx pgx.Tx, adjustment models.BookingAdjustment, booking adjustableBooking) error {
+	if _, err := tx.Exec(ctx, "UPDATE booking SET adjustments_total = adjustments_total + $1 WHERE id = $2", adjustment.Amount, adjustment.BookingID); err != nil {
+		return err
+	}
+
+	_, err := ledger.Post(ctx, tx, ledger.AdjustmentEarnings(adjustment, booking.driverID, booking.currency))
+	return err
+}
+
+func (s *BookingService) loadAdjustments(ctx context.Context, bookingID int32) ([]models.BookingAdjustment, error) {
+	rows, err := s.PostgreSQLConn.Query(ctx,
+		"SELECT id, booking_id, kind, description, quantity, unit, amount, status, requested_by, created_at, decided_at FROM booking_adjustments WHERE booking_id = $1 ORDER BY created_at, id",
+		bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := []models.BookingAdjustment{}
+	for rows.Next() {
+		var adjustment models.BookingAdjustment
+		if err := rows.Scan(&adjustment.ID, &adjustment.BookingID, &adjustment.Kind, &adjustment.Description, &adjustment.Quantity,
+			&adjustment.Unit, &adjustment.Amount, &adjustment.Status, &adjustment.RequestedBy, &adjustment.CreatedAt, &adjustment.DecidedAt); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments, rows.Err()
+}
+
+// priceAdjustment turns a driver's report into a priced adjustment using the
+// platform's rates, so drivers never choose the amount themselves. Amounts
+// are rounded to the booking's currency.
+func priceAdjustment(adjustmentReq models.AdjustmentRequest, currency string) (models.BookingAdjustment, error) {
+	adjustment := models.BookingAdjustment{Kind: adjustmentReq.Kind, Status: models.AdjustmentPending}
+
+	switch adjustmentReq.Kind {
+	case models.AdjustmentWaitingTime:
+		if adjustmentReq.Quantity <= 0 || adjustmentReq.Quantity > maxWaitingMinutes {
+			return adjustment, fmt.Errorf("waiting time must be between 1 and %d minutes", maxWaitingMinutes)
+		}
+		adjustment.Quantity = math.Ceil(adjustmentReq.Quantity)
+		adjustment.Unit = "min"
+		adjustment.Description = "Waiting time"
+		adjustment.Amount = adjustmentRate("ADJUSTMENT_WAITING_RATE", defaultWaitingRate).Mul(adjustment.Quantity)
+	case models.AdjustmentExtraStop:
+		stops := math.Max(1, math.Round(adjustmentReq.Quantity))
+		if stops > maxExtraStops {
+			return adjustment, fmt.Errorf("at most %d extra stops can be reported", maxExtraStops)
+		}
+		adjustment.Quantity = stops
+		adjustment.Unit = "stop"
+		adjustment.Description = "Extra stops"
+		adjustment.Amount = adjustmentRate("ADJUSTMENT_EXTRA_STOP_FEE", defaultExtraStopFee).Mul(stops)
+	case models.AdjustmentLoading, models.AdjustmentUnloading:
+		adjustment.Description = "Loading surcharge"
+		if adjustmentReq.Kind == models.AdjustmentUnloading {
+			adjustment.Description = "Unloading surcharge"
+		}
+		adjustment.Amount = adjustmentRate("ADJUSTMENT_LOADING_FEE", defaultLoadingFee)
+	}
+
+	if adjustmentReq.Description != "" {
+		adjustment.Description += ": " + truncate(adjustmentReq.Description, 200)
+	}
+	adjustment.Amount = adjustment.Amount.Round(currency)
+
+	return adjustment, nil
+}
+
+func adjustmentRate(key string, fallback float64) money.Amount {
+	if viper.IsSet(key) {
+		return money.FromFloat(viper.GetFloat64(key))
+	}
+	return money.FromFloat(fallback)
+}
+
+// truncate cuts value to at most length characters, never splitting one.
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
@@ -3,9 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/payment"
+	"logistics-platform/lib/promotion"
+	"logistics-platform/lib/surge"
 	"logistics-platform/services/booking/interfaces"
 	"net/http"
 	"os"
@@ -27,23 +32,33 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// favouriteDriverGrace is how long a shipper's favourite drivers have the
+// request to themselves before it is offered to other nearby drivers
+const favouriteDriverGrace = 2 * time.Minute
+
 type BookingService struct {
 	mongoClient        *mongo.Client
 	notificationWriter *kafka.Writer
 	bookingWriter      *kafka.Writer
+	offerResultWriter  *kafka.Writer
+	offerActionReader  *kafka.Reader
 	redisClient        *redis.Client
 	PostgreSQLConn     *pgxpool.Pool
+	paymentGateway     payment.PaymentGateway
 	shutdown           chan struct{}
 	wg                 sync.WaitGroup
 }
 
-func NewBookingService(mongoClient *mongo.Client, redisClient *redis.Client, pool *pgxpool.Pool) interfaces.BookingInterface {
+func NewBookingService(mongoClient *mongo.Client, redisClient *redis.Client, pool *pgxpool.Pool, paymentGateway payment.PaymentGateway) interfaces.BookingInterface {
 	return &BookingService{
 		mongoClient:        mongoClient,
 		notificationWriter: kafkaConfig.InitKafkaWriter("driver_notification"),
 		redisClient:        redisClient,
 		PostgreSQLConn:     pool,
+		paymentGateway:     paymentGateway,
 		bookingWriter:      kafkaConfig.InitKafkaWriter("booking_notifications"),
+		offerResultWriter:  kafkaConfig.InitKafkaWriter("driver_offer_results"),
+		offerActionReader:  kafkaConfig.InitKafkaReader("driver_offer_actions", "booking_service_group"),
 		shutdown:           make(chan struct{}),
 	}
 }
@@ -68,31 +83,68 @@ func (s *BookingService) HandleBookingUpdate(c *gin.Context) {
 		return
 	}
 
+	query := "UPDATE booking SET status = $1 WHERE user_id = $2 AND driver_id = $3 AND status != $4 RETURNING id"
 	if booking.Status == "completed" || booking.Status == "cancelled" {
-		pgComm, err := s.PostgreSQLConn.Exec(context.Background(), "UPDATE booking SET status = $1, completed_at = NOW() WHERE user_id = $2 AND driver_id = $3 AND status != $4", booking.Status, userID, driver.UserID, "completed")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating booking"})
-			return
-		}
+		query = "UPDATE booking SET status = $1, completed_at = NOW() WHERE user_id = $2 AND driver_id = $3 AND status != $4 RETURNING id"
+	}
 
-		if pgComm.RowsAffected() == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
-			return
-		}
-	} else {
-		pgComm, err := s.PostgreSQLConn.Exec(context.Background(), "UPDATE booking SET status = $1 WHERE user_id = $2 AND driver_id = $3 AND status != $4", booking.Status, userID, driver.UserID, "completed")
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating booking"})
-			return
-		}
+	rows, err := s.PostgreSQLConn.Query(context.Background(), query, booking.Status, userID, driver.UserID, "completed")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating booking"})
+		return
+	}
 
-		if pgComm.RowsAffected() == 0 {
-			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
-			return
+	// the booking id lets the notification service close the booking's chat;
+	// the newest row updated is the trip the driver is on
+	var bookingID int32
+	found := false
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err == nil {
+			found = true
+			if id > bookingID {
+				bookingID = id
+			}
 		}
 	}
+	rows.Close()
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating booking"})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		return
+	}
+
+	go s.writeBookingEvent(models.BookedNotification{
+		UserID:     userID,
+		DriverID:   driver.UserID,
+		DriverName: driver.UserName,
+		Status:     booking.Status,
+		BookingID:  bookingID,
+	})
 
-	go s.ProduceBookingEvent(userID, driver.UserID, driver.UserName, booking.Status)
+	if booking.Status == "completed" || booking.Status == "cancelled" {
+		go func() {
+			// payments are captured for what the invoice bills
+			if booking.Status == "completed" {
+				s.generatePendingInvoices(userID, driver.UserID)
+			}
+			if err := s.settlePayments(context.Background(), userID, driver.UserID); err != nil {
+				log.Printf("Error settling payments for user %s: %v", userID, err)
+			}
+		}()
+	}
+
+	if booking.Status == "completed" {
+		go func() {
+			if err := s.postPendingEarnings(context.Background(), driver.UserID); err != nil {
+				log.Printf("Error posting earnings for driver %s: %v", driver.UserID, err)
+			}
+		}()
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Booking updated"})
 }
@@ -118,56 +170,110 @@ func (s *BookingService) HandleBookingAccept(c *gin.Context) {
 		return
 	}
 
-	collection := s.mongoClient.Database("logistics").Collection("booking_requests")
-	var bookConReq models.BookingConfirmation
-	objectID, err := primitive.ObjectIDFromHex(mongoID.MongoID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mongo id"})
+	userIDs, stops, err := s.acceptOffer(mongoID.MongoID, driver)
+	if errors.Is(err, errInvalidOfferID) || errors.Is(err, errOfferNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
-	}
-	if err := collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&bookConReq.BookingReq); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "booking request not found"})
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	bookConReq.DriverID = driver.UserID
-	bookConReq.DriverName = driver.UserName
-
-	// delete the booking request
-	if _, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID}); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting booking request"})
-		return
-	}
-	err = s.ProcessBooked(bookConReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if len(stops) > 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Shared booking accepted", "user_ids": userIDs, "stops": stops})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking accepted", "user_id": bookConReq.BookingReq.UserID})
+	c.JSON(http.StatusOK, gin.H{"message": "Booking accepted", "user_id": userIDs[0]})
 }
 
 func (s *BookingService) ProcessBooked(bookConReq models.BookingConfirmation) error {
 	// make a new booking in the postgres database
 	bookingReq := bookConReq.BookingReq
-	_, err := s.PostgreSQLConn.Exec(context.Background(), "INSERT INTO booking (user_id, driver_id, pickup_latitude, pickup_longitude, dropoff_latitude, dropoff_longitude, vehicle_type, price, status, pickup_name, dropoff_name) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)", bookingReq.UserID, bookConReq.DriverID, bookingReq.Pickup.Latitude, bookingReq.Pickup.Longitude, bookingReq.Dropoff.Latitude, bookingReq.Dropoff.Longitude, bookingReq.VehicleType, bookingReq.Price, "enroute_to_pickup", bookConReq.BookingReq.Pickup.Name, bookConReq.BookingReq.Dropoff.Name)
+	// requests priced before currencies were recorded have no tax rule
+	var taxRate interface{}
+	if bookingReq.Currency != "" {
+		taxRate = bookingReq.TaxRate
+	}
+	// requests priced before quotes carried the routed trip are invoiced as
+	// a single fare
+	var quotedDistance, quotedDuration, surgeAmount interface{}
+	if bookingReq.QuotedDuration != 0 {
+		quotedDistance, quotedDuration, surgeAmount = bookingReq.QuotedDistance, bookingReq.QuotedDuration, bookingReq.SurgeAmount
+	}
+	// only members of a pool dispatched with others were given the discount
+	var sharedDiscount money.Amount
+	if bookingReq.SharedPoolID != "" {
+		sharedDiscount = bookingReq.SharedDiscount
+	}
+	// the promo code is redeemed with the booking, so a booking never exists
+	// at a discount its promotion did not grant
+	var discount money.Amount
+	if bookingReq.PromotionID != 0 {
+		discount = bookingReq.Discount
+	}
+	ctx := context.Background()
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
+	var bookingID int32
+	err = tx.QueryRow(ctx, "INSERT INTO booking (user_id, driver_id, pickup_latitude, pickup_longitude, dropoff_latitude, dropoff_longitude, vehicle_type, price, status, pickup_name, dropoff_name, organisation_id, cost_centre_id, shared_trip_id, currency, region_id, tax_name, tax_rate, tax_inclusive, cargo_weight, cargo_volume, fragile, hazardous, loading_assistance, experiment_id, experiment_variant, quoted_distance, quoted_duration, surge_amount, shared_discount, promotion_id, discount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32) RETURNING id", bookingReq.UserID, bookConReq.DriverID, bookingReq.Pickup.Latitude, bookingReq.Pickup.Longitude, bookingReq.Dropoff.Latitude, bookingReq.Dropoff.Longitude, bookingReq.VehicleType, bookingReq.Price, "enroute_to_pickup", bookConReq.BookingReq.Pickup.Name, bookConReq.BookingReq.Dropoff.Name, nullableID(bookingReq.OrganisationID), nullableID(bookingReq.CostCentreID), nullableString(bookingReq.SharedPoolID), nullableString(bookingReq.Currency), nullableID(bookingReq.RegionID), nullableString(bookingReq.TaxName), taxRate, bookingReq.TaxInclusive, bookingReq.Cargo.Weight, bookingReq.Cargo.Volume, bookingReq.Cargo.Fragile, bookingReq.Cargo.Hazardous, bookingReq.Cargo.LoadingAssistance, nullableID(bookingReq.ExperimentID), nullableString(bookingReq.ExperimentVariant), quotedDistance, quotedDuration, surgeAmount, sharedDiscount, nullableID(bookingReq.PromotionID), discount).Scan(&bookingID)
 	if err != nil {
 		return fmt.Errorf("error storing booking: %w", err)
 	}
 
-	go s.ProduceBookingEvent(bookingReq.UserID, bookConReq.DriverID, bookConReq.DriverName, "booked")
+	if bookingReq.PromotionID != 0 {
+		if err := promotion.Redeem(ctx, tx, bookingReq.PromotionID, bookingReq.UserID, bookingID, discount); err != nil {
+			// the promotion's limits ran out while the request waited for a
+			// driver; the request is dropped rather than charged a fare the
+			// user was not quoted
+			if isPromotionError(err) {
+				go s.writeBookingEvent(models.BookedNotification{UserID: bookingReq.UserID, Status: "promo_not_applied"})
+			}
+			return fmt.Errorf("error redeeming promotion: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error storing booking: %w", err)
+	}
+
+	if err := surge.CloseOpenRequest(context.Background(), s.redisClient, bookingReq.MongoID); err != nil {
+		log.Printf("Error closing open request %s: %v", bookingReq.MongoID, err)
+	}
+
+	s.linkPayment(context.Background(), bookingReq, bookingID)
+
+	if bookingReq.RecurringBookingID != 0 {
+		go s.recordRecurringDriver(bookingReq.RecurringBookingID, bookConReq.DriverID)
+	}
+
+	// the trip endpoints let the notification service compute ETAs for the user
+	go s.writeBookingEvent(models.BookedNotification{
+		UserID:     bookingReq.UserID,
+		DriverID:   bookConReq.DriverID,
+		DriverName: bookConReq.DriverName,
+		Status:     "booked",
+		BookingID:  bookingID,
+		Pickup:     &bookingReq.Pickup,
+		Dropoff:    &bookingReq.Dropoff,
+	})
 	return nil
 }
 
 func (s *BookingService) ProduceBookingEvent(userID, driverID, driverName, status string) {
-	bookingEvent := models.BookedNotification{
+	s.writeBookingEvent(models.BookedNotification{
 		UserID:     userID,
 		DriverID:   driverID,
 		DriverName: driverName,
 		Status:     status,
-	}
+	})
+}
 
+func (s *BookingService) writeBookingEvent(bookingEvent models.BookedNotification) {
 	bookingEventJSON, err := json.Marshal(bookingEvent)
 	if err != nil {
 		log.Printf("Error marshaling booking event: %v", err)
@@ -198,24 +304,87 @@ func (s *BookingService) HandleBookingRequest(c *gin.Context) {
 	bookingReq.UserID = user.UserID
 	bookingReq.UserName = user.UserName
 
+	// requests are priced here rather than trusting the client's price, so
+	// the currency, tax and any promo code discount match what is charged
+	// and redeemed
+	promoError, err := priceBookingRequest(&bookingReq)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "error pricing booking request", "err": err.Error()})
+		return
+	}
+	if promoError != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": promoError})
+		return
+	}
+
+	approvalID, err := s.applyOrganisationPolicy(context.Background(), &bookingReq)
+	if errors.Is(err, errSpendLimitExceeded) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, errUnknownCostCentre) || errors.Is(err, errNoOrganisation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error", "err": err.Error()})
+		return
+	}
+
+	if approvalID != "" {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Booking request awaiting approval", "approval_id": approvalID})
+		return
+	}
+
 	go s.ProcessBookingRequest(bookingReq)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Booking request received"})
 }
 
 func (s *BookingService) ProcessBookingRequest(bookingReq models.BookingRequest) error {
+	// organisation requests are stored while their spend limit is checked
+	if bookingReq.MongoID == "" {
+		if err := s.storeBookingRequest(context.Background(), &bookingReq); err != nil {
+			return err
+		}
+	}
+
+	if err := s.authorizePayment(context.Background(), bookingReq); err != nil {
+		// the request never reaches drivers without a hold on the fare
+		objectID, _ := primitive.ObjectIDFromHex(bookingReq.MongoID)
+		collection := s.mongoClient.Database("logistics").Collection("booking_requests")
+		if _, delErr := collection.DeleteOne(context.Background(), bson.M{"_id": objectID}); delErr != nil {
+			log.Printf("Error deleting booking request %s: %v", bookingReq.MongoID, delErr)
+		}
+		if errors.Is(err, payment.ErrDeclined) {
+			go s.writeBookingEvent(models.BookedNotification{UserID: bookingReq.UserID, Status: "payment_failed"})
+		}
+		return err
+	}
+
+	// open requests are the demand side of surge pricing
+	if err := surge.TrackOpenRequest(context.Background(), s.redisClient, bookingReq.MongoID, bookingReq.Pickup); err != nil {
+		log.Printf("Error tracking open request %s: %v", bookingReq.MongoID, err)
+	}
+
+	if bookingReq.AllowShared && bookingReq.Cargo.Volume > 0 {
+		return s.poolSharedRequest(bookingReq)
+	}
+
+	return s.FindAndNotifyNearbyDrivers(bookingReq, bookingReq.VehicleType)
+}
+
+// storeBookingRequest stores a request waiting for a driver and sets its
+// MongoID.
+func (s *BookingService) storeBookingRequest(ctx context.Context, bookingReq *models.BookingRequest) error {
 	bookingReq.CreatedAt = time.Now()
 
 	collection := s.mongoClient.Database("logistics").Collection("booking_requests")
-	res, err := collection.InsertOne(context.Background(), bookingReq)
+	res, err := collection.InsertOne(ctx, bookingReq)
 	if err != nil {
 		return fmt.Errorf("error storing booking request: %w", err)
 	}
 
-	newBookingMongoID := res.InsertedID.(primitive.ObjectID).Hex()
-	bookingReq.MongoID = newBookingMongoID
-
-	return s.FindAndNotifyNearbyDrivers(bookingReq, bookingReq.VehicleType)
+	bookingReq.MongoID = res.InsertedID.(primitive.ObjectID).Hex()
+	return nil
 }
 
 func (s *BookingService) FindAndNotifyNearbyDrivers(bookingReq models.BookingRequest, vehicleType string) error {
@@ -228,43 +397,116 @@ func (s *BookingService) FindAndNotifyNearbyDrivers(bookingReq models.BookingReq
 		return fmt.Errorf("error finding nearby drivers: %w", err)
 	}
 
+	favourites, blocked, err := s.driverPreferences(context.Background(), bookingReq)
+	if err != nil {
+		return fmt.Errorf("error loading driver preferences: %w", err)
+	}
+	if bookingReq.PreferredDriverID != "" {
+		favourites[bookingReq.PreferredDriverID] = true
+	}
+
+	driverIDs := make([]string, 0, len(drivers))
 	for _, driver := range drivers {
-		go func(driverID string) {
+		driverIDs = append(driverIDs, driver.Name)
+	}
+	poorlyRated, err := s.poorlyRatedDrivers(context.Background(), driverIDs)
+	if err != nil {
+		log.Printf("Error loading driver ratings: %v", err)
+	}
+
+	// blocked drivers are never offered the request; nearby favourites with
+	// the right vehicle get it to themselves for a short while before it is
+	// offered to everyone else who is not poorly rated
+	var firstWave, secondWave []string
+	for _, driverID := range driverIDs {
+		switch {
+		case blocked[driverID]:
+		case favourites[driverID] && s.hasVehicleType(driverID, vehicleType):
+			firstWave = append(firstWave, driverID)
+		case poorlyRated[driverID]:
+		default:
+			secondWave = append(secondWave, driverID)
+		}
+	}
 
-			// check if the driver has the same vehicle type
-			driverVehicleType, err := s.GetVehicleType(driverID)
-			if err != nil {
-				log.Printf("Error getting vehicle type for driver %s: %v", driverID, err)
-				return
-			}
+	if len(firstWave) == 0 {
+		s.notifyMatchingDrivers(secondWave, bookingReq, vehicleType)
+		return nil
+	}
+
+	s.notifyMatchingDrivers(firstWave, bookingReq, vehicleType)
+	time.AfterFunc(favouriteDriverGrace, func() {
+		if s.bookingRequestOpen(bookingReq) {
+			s.notifyMatchingDrivers(secondWave, bookingReq, vehicleType)
+		}
+	})
+	return nil
+}
 
-			if driverVehicleType != vehicleType {
+func (s *BookingService) hasVehicleType(driverID, vehicleType string) bool {
+	driverVehicleType, err := s.GetVehicleType(driverID)
+	if err != nil {
+		log.Printf("Error getting vehicle type for driver %s: %v", driverID, err)
+		return false
+	}
+	return driverVehicleType == vehicleType
+}
+
+// notifyMatchingDrivers offers the request to every driver whose vehicle is of
+// the requested type.
+func (s *BookingService) notifyMatchingDrivers(driverIDs []string, bookingReq models.BookingRequest, vehicleType string) {
+	for _, driverID := range driverIDs {
+		go func(driverID string) {
+			if !s.hasVehicleType(driverID, vehicleType) {
 				return
 			}
 
 			if err := s.NotifyDriver(driverID, bookingReq); err != nil {
 				log.Printf("Error notifying driver %s: %v", driverID, err)
 			}
-		}(driver.Name)
+		}(driverID)
 	}
-	return nil
+}
+
+// bookingRequestOpen reports whether a booking request, or the shared pool
+// offered in its place, is still waiting for a driver.
+func (s *BookingService) bookingRequestOpen(bookingReq models.BookingRequest) bool {
+	objectID, err := primitive.ObjectIDFromHex(bookingReq.MongoID)
+	if err != nil {
+		return false
+	}
+
+	filter := bson.M{"_id": objectID}
+	collection := s.mongoClient.Database("logistics").Collection("booking_requests")
+	if bookingReq.SharedPoolID == bookingReq.MongoID {
+		filter["status"] = "dispatched"
+		collection = s.mongoClient.Database("logistics").Collection("shared_pools")
+	}
+
+	count, err := collection.CountDocuments(context.Background(), filter)
+	return err == nil && count > 0
 }
 
 func (s *BookingService) NotifyDriver(driverID string, bookingReq models.BookingRequest) error {
 	notification := models.BookingNotification{
 		UserID:   bookingReq.UserID,
 		Price:    bookingReq.Price,
+		Currency: bookingReq.Currency,
 		DriverID: driverID,
 		Pickup:   bookingReq.Pickup,
 		Dropoff:  bookingReq.Dropoff,
 		UserName: bookingReq.UserName,
 		MongoID:  bookingReq.MongoID,
+		PickupAt: bookingReq.PickupAt,
+		Stops:    bookingReq.Stops,
 	}
 	notificationJSON, err := json.Marshal(notification)
 	if err != nil {
 		return fmt.Errorf("error marshaling notification: %w", err)
 	}
 
+	s.recordOffer(bookingReq.MongoID, driverID)
+
 	return s.notificationWriter.WriteMessages(context.Background(), kafka.Message{Value: notificationJSON})
 }
 
@@ -302,8 +544,8 @@ func (s *BookingService) HandleUserBookingCheck(c *gin.Context) {
 	// Check if the user has any booking made in PostgreSQL where status is not completed or cancelled
 	var booking models.Booking
 	err = s.PostgreSQLConn.QueryRow(context.Background(),
-		"SELECT b.user_id, b.driver_id, b.price, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.status, b.pickup_name, b.dropoff_name, d.name FROM booking b INNER JOIN vehicle_drivers d ON d.id=b.driver_id WHERE b.user_id=$1 AND status!=$2 AND status != $3",
-		userId, "completed", "cancelled").Scan(&booking.UserID, &booking.DriverID, &booking.Price, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.DriverName)
+		"SELECT b.id, b.user_id, b.driver_id, b.price, COALESCE(b.currency, ''), b.adjustments_total, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.status, b.pickup_name, b.dropoff_name, COALESCE(b.payment_status, ''), d.name FROM booking b INNER JOIN vehicle_drivers d ON d.id=b.driver_id WHERE b.user_id=$1 AND status!=$2 AND status != $3",
+		userId, "completed", "cancelled").Scan(&booking.ID, &booking.UserID, &booking.DriverID, &booking.Price, &booking.Currency, &booking.AdjustmentsTotal, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.PaymentStatus, &booking.DriverName)
 
 	if err == pgx.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "no booking found"})
@@ -327,7 +569,7 @@ func (s *BookingService) HandleUserBookingHistory(c *gin.Context) {
 	user, _ := authUser.(models.UserRequest)
 
 	// check if the user has any booking made which is in postgres
-	rows, err := s.PostgreSQLConn.Query(context.Background(), "SELECT b.user_id, b.driver_id, b.price, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.completed_at, b.status, b.pickup_name, b.dropoff_name, d.name FROM booking b INNER JOIN vehicle_drivers d on d.id=b.driver_id WHERE user_id=$1", user.UserID)
+	rows, err := s.PostgreSQLConn.Query(context.Background(), "SELECT b.id, b.user_id, b.driver_id, b.price, COALESCE(b.currency, ''), b.adjustments_total, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.completed_at, b.status, b.pickup_name, b.dropoff_name, COALESCE(b.payment_status, ''), d.name FROM booking b INNER JOIN vehicle_drivers d on d.id=b.driver_id WHERE user_id=$1", user.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching booking history", "err": err})
 		return
@@ -338,7 +580,7 @@ func (s *BookingService) HandleUserBookingHistory(c *gin.Context) {
 	for rows.Next() {
 		var booking models.Booking
 		completedAt := new(time.Time)
-		if err := rows.Scan(&booking.UserID, &booking.DriverID, &booking.Price, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &completedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.DriverName); err != nil {
+		if err := rows.Scan(&booking.ID, &booking.UserID, &booking.DriverID, &booking.Price, &booking.Currency, &booking.AdjustmentsTotal, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &completedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.PaymentStatus, &booking.DriverName); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading booking history",
 				"err": err})
 			return
@@ -374,8 +616,8 @@ func (s *BookingService) HandleDriverBookingCheck(c *gin.Context) {
 	// Check if the user has any booking made in PostgreSQL where status is not completed or cancelled
 	var booking models.Booking
 	err = s.PostgreSQLConn.QueryRow(context.Background(),
-		"SELECT b.user_id, b.driver_id, b.price, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.status, b.pickup_name, b.dropoff_name, u.name FROM booking b INNER JOIN users u ON u.id=b.user_id WHERE driver_id=$1 AND status!=$2 AND status!=$3",
-		driverID, "completed", "cancelled").Scan(&booking.UserID, &booking.DriverID, &booking.Price, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.UserName)
+		"SELECT b.id, b.user_id, b.driver_id, b.price, COALESCE(b.currency, ''), b.adjustments_total, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.status, b.pickup_name, b.dropoff_name, COALESCE(b.payment_status, ''), u.name FROM booking b INNER JOIN users u ON u.id=b.user_id WHERE driver_id=$1 AND status!=$2 AND status!=$3",
+		driverID, "completed", "cancelled").Scan(&booking.ID, &booking.UserID, &booking.DriverID, &booking.Price, &booking.Currency, &booking.AdjustmentsTotal, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.PaymentStatus, &booking.UserName)
 
 	if err == pgx.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "no booking found"})
@@ -399,7 +641,7 @@ func (s *BookingService) HandleDriverBookingHistory(c *gin.Context) {
 	driver, _ := authDriver.(models.UserRequest)
 
 	// check if the driver has any booking made which is in postgres
-	rows, err := s.PostgreSQLConn.Query(context.Background(), "SELECT b.user_id, b.driver_id, b.price, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.completed_at, b.status, b.pickup_name, b.dropoff_name, u.name FROM booking b INNER JOIN users u on u.id=b.user_id WHERE driver_id=$1", driver.UserID)
+	rows, err := s.PostgreSQLConn.Query(context.Background(), "SELECT b.id, b.user_id, b.driver_id, b.price, COALESCE(b.currency, ''), b.adjustments_total, b.pickup_latitude, b.pickup_longitude, b.dropoff_latitude, b.dropoff_longitude, b.created_at, b.completed_at, b.status, b.pickup_name, b.dropoff_name, COALESCE(b.payment_status, ''), u.name FROM booking b INNER JOIN users u on u.id=b.user_id WHERE driver_id=$1", driver.UserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching booking history"})
 		return
@@ -410,7 +652,7 @@ func (s *BookingService) HandleDriverBookingHistory(c *gin.Context) {
 	for rows.Next() {
 		var booking models.Booking
 		completedAt := new(time.Time)
-		if err := rows.Scan(&booking.UserID, &booking.DriverID, &booking.Price, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &completedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.UserName); err != nil {
+		if err := rows.Scan(&booking.ID, &booking.UserID, &booking.DriverID, &booking.Price, &booking.Currency, &booking.AdjustmentsTotal, &booking.Pickup.Latitude, &booking.Pickup.Longitude, &booking.Dropoff.Latitude, &booking.Dropoff.Longitude, &booking.BookedAt, &completedAt, &booking.Status, &booking.Pickup.Name, &booking.Dropoff.Name, &booking.PaymentStatus, &booking.UserName); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching booking history"})
 			return
 		}
@@ -508,6 +750,8 @@ func (s *BookingService) GracefulShutdown(server *http.Server) {
 
 	closeWithTimeout(s.notificationWriter.Close, "notification writer")
 	closeWithTimeout(s.bookingWriter.Close, "book notification writer")
+	closeWithTimeout(s.offerResultWriter.Close, "offer result writer")
+	closeWithTimeout(s.offerActionReader.Close, "offer action reader")
 
 	// Close Redis connection
 	if err := s.redisClient.Close(); err != nil {
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"logistics-platform/lib/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	maxBulkRows = 1000
+	bulkJobTTL  = 24 * time.Hour
+)
+
+var bulkCSVColumns = []string{"pickup_latitude", "pickup_longitude", "pickup_name", "dropoff_latitude", "dropoff_longitude", "dropoff_name", "vehicle_type", "pickup_at"}
+
+type bulkRow struct {
+	bookingReq models.BookingRequest
+	err        error
+}
+
+// HandleBulkBookingRequest accepts a CSV (Content-Type: text/csv) or JSON
+// lines upload of booking requests. Every row is validated up front and the
+// per-row results are returned straight away; valid rows are then created in
+// the background and their progress is available from the job endpoint.
+func (s *BookingService) HandleBulkBookingRequest(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var rows []bulkRow
+	var err error
+	if c.ContentType() == "text/csv" {
+		rows, err = parseBulkCSV(c.Request.Body)
+	} else {
+		rows, err = parseBulkJSONLines(c.Request.Body)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no booking rows found"})
+		return
+	}
+	if len(rows) > maxBulkRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d rows can be imported at once", maxBulkRows)})
+		return
+	}
+
+	jobID, err := newBulkJobID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating bulk job"})
+		return
+	}
+
+	job := models.BulkBookingJob{
+		ID:        jobID,
+		UserID:    user.UserID,
+		Status:    "processing",
+		Total:     len(rows),
+		CreatedAt: time.Now(),
+	}
+
+	knownVehicleTypes := make(map[string]bool)
+	for i := range rows {
+		if rows[i].err == nil {
+			rows[i].err = validateBulkRow(rows[i].bookingReq, knownVehicleTypes)
+		}
+
+		result := models.BulkRowResult{Row: i + 1, Status: models.BulkRowQueued}
+		if rows[i].err != nil {
+			result.Status = models.BulkRowInvalid
+			result.Error = rows[i].err.Error()
+			job.Invalid++
+		} else {
+			job.Queued++
+		}
+		job.Rows = append(job.Rows, result)
+	}
+
+	if job.Queued == 0 {
+		job.Status = "completed"
+	}
+
+	if err := s.saveBulkJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing bulk job"})
+		return
+	}
+
+	if job.Queued > 0 {
+		s.wg.Add(1)
+		go s.processBulkJob(job, rows, user)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
+
+func (s *BookingService) HandleBulkJobStatus(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	jobJSON, err := s.redisClient.Get(context.Background(), "bulk-job:"+c.Param("jobId")).Result()
+	if err == redis.Nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bulk job not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching bulk job"})
+		return
+	}
+
+	var job models.BulkBookingJob
+	if err := json.Unmarshal([]byte(jobJSON), &job); err != nil || job.UserID != user.UserID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bulk job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// processBulkJob prices and submits every queued row through the same path as
+// a single booking request, recording the outcome of each row on the job.
+func (s *BookingService) processBulkJob(job models.BulkBookingJob, rows []bulkRow, user models.UserRequest) {
+	defer s.wg.Done()
+
+	for i, row := range rows {
+		if row.err != nil {
+			continue
+		}
+
+		select {
+		case <-s.shutdown:
+			job.Status = "interrupted"
+			if err := s.saveBulkJob(job); err != nil {
+				log.Printf("Error storing bulk job %s: %v", job.ID, err)
+			}
+			return
+		default:
+		}
+
+		job.Rows[i] = s.submitBulkRow(row.bookingReq, user, i+1)
+		if err := s.saveBulkJob(job); err != nil {
+			log.Printf("Error storing bulk job %s: %v", job.ID, err)
+		}
+	}
+
+	job.Status = "completed"
+	if err := s.saveBulkJob(job); err != nil {
+		log.Printf("Error storing bulk job %s: %v", job.ID, err)
+	}
+}
+
+func (s *BookingService) submitBulkRow(bookingReq models.BookingRequest, user models.UserRequest, rowNumber int) models.BulkRowResult {
+	result := models.BulkRowResult{Row: rowNumber, Status: models.BulkRowFailed}
+
+	bookingReq.UserID = user.UserID
+	bookingReq.UserName = user.UserName
+
+	// rows for later are priced and offered to drivers shortly before pickup,
+	// like the occurrences of a recurring booking
+	if bookingReq.PickupAt != nil && time.Until(*bookingReq.PickupAt) > recurringLeadTime {
+		if _, err := s.scheduleBookingRequest(context.Background(), bookingReq); err != nil {
+			result.Error = "error scheduling booking request: " + err.Error()
+			return result
+		}
+		result.Status = models.BulkRowScheduled
+		return result
+	}
+
+	promoError, err := priceBookingRequest(&bookingReq)
+	if err != nil {
+		result.Error = "error estimating price: " + err.Error()
+		return result
+	}
+	if promoError != "" {
+		result.Error = promoError
+		return result
+	}
+
+	approvalID, err := s.applyOrganisationPolicy(context.Background(), &bookingReq)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if approvalID != "" {
+		result.Status = models.BulkRowAwaitingApproval
+		return result
+	}
+
+	if err := s.ProcessBookingRequest(bookingReq); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = models.BulkRowSubmitted
+	return result
+}
+
+func (s *BookingService) saveBulkJob(job models.BulkBookingJob) error {
+	job.UpdatedAt = time.Now()
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return s.redisClient.Set(context.Background(), "bulk-job:"+job.ID, jobJSON, bulkJobTTL).Err()
+}
+
+func validateBulkRow(bookingReq models.BookingRequest, knownVehicleTypes map[string]bool) error {
+	for _, point := range []models.GeoPoint{bookingReq.Pickup, bookingReq.Dropoff} {
+		if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+			return fmt.Errorf("coordinates out of range: %g,%g", point.Latitude, point.Longitude)
+		}
+		if point.Latitude == 0 && point.Longitude == 0 {
+			return errors.New("pickup and dropoff coordinates are required")
+		}
+	}
+
+	if bookingReq.PickupAt != nil && bookingReq.PickupAt.Before(time.Now()) {
+		return errors.New("pickup time is in the past")
+	}
+
+	if bookingReq.VehicleType == "" {
+		return errors.New("vehicle type is required")
+	}
+
+	known, checked := knownVehicleTypes[bookingReq.VehicleType]
+	if !checked {
+		_, err := fetchVehiclePricing(bookingReq.VehicleType, 0, time.Time{}, 0, "")
+		if err != nil && !errors.Is(err, errUnsupportedVehicleType) {
+			return fmt.Errorf("error checking vehicle type: %w", err)
+		}
+		known = err == nil
+		knownVehicleTypes[bookingReq.VehicleType] = known
+	}
+	if !known {
+		return fmt.Errorf("%w %q", errUnsupportedVehicleType, bookingReq.VehicleType)
+	}
+
+	return nil
+}
+
+// parseBulkCSV reads a CSV upload whose header names the columns in
+// bulkCSVColumns; cost_centre is an optional extra column.
+func parseBulkCSV(body io.Reader) ([]bulkRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading csv header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range bulkCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", name)
+		}
+	}
+
+	var rows []bulkRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading csv: %w", err)
+		}
+		if len(rows) >= maxBulkRows {
+			rows = append(rows, bulkRow{})
+			break
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		var row bulkRow
+		row.bookingReq.Pickup.Name = field("pickup_name")
+		row.bookingReq.Dropoff.Name = field("dropoff_name")
+		row.bookingReq.VehicleType = field("vehicle_type")
+		row.bookingReq.CostCentre = field("cost_centre")
+
+		coordinates := []struct {
+			column string
+			dest   *float64
+		}{
+			{"pickup_latitude", &row.bookingReq.Pickup.Latitude},
+			{"pickup_longitude", &row.bookingReq.Pickup.Longitude},
+			{"dropoff_latitude", &row.bookingReq.Dropoff.Latitude},
+			{"dropoff_longitude", &row.bookingReq.Dropoff.Longitude},
+		}
+		for _, coordinate := range coordinates {
+			value, err := strconv.ParseFloat(field(coordinate.column), 64)
+			if err != nil {
+				row.err = fmt.Errorf("invalid %s", coordinate.column)
+				break
+			}
+			*coordinate.dest = value
+		}
+
+		if pickupAt := field("pickup_at"); pickupAt != "" && row.err == nil {
+			t, err := time.Parse(time.RFC3339, pickupAt)
+			if err != nil {
+				row.err = errors.New("pickup_at must be an RFC 3339 timestamp")
+			} else {
+				row.bookingReq.PickupAt = &t
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseBulkJSONLines(body io.Reader) ([]bulkRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []bulkRow
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) >= maxBulkRows {
+			rows = append(rows, bulkRow{})
+			break
+		}
+
+		var row bulkRow
+		if err := json.Unmarshal([]byte(line), &row.bookingReq); err != nil {
+			row.err = fmt.Errorf("invalid json: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading upload: %w", err)
+	}
+
+	return rows, nil
+}
+
+func newBulkJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
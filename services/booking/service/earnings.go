@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"logistics-platform/lib/ledger"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	payoutTick          = time.Hour
+	recentEntriesLimit  = 50
+	payoutStatementDays = 7
+)
+
+const payoutStatementColumns = `id, driver_id, to_char(period_start, 'YYYY-MM-DD'), to_char(period_end, 'YYYY-MM-DD'), trip_fares, commission, tips,
+	adjustments, bonuses, penalties, amount, status, settled_at, COALESCE(settlement_reference, ''), created_at`
+
+// HandleDriverEarnings returns the driver's balance and latest ledger entries.
+func (s *BookingService) HandleDriverEarnings(c *gin.Context) {
+	authDriver, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	driver, _ := authDriver.(models.UserRequest)
+
+	ctx := context.Background()
+	var balance models.DriverBalance
+	err := s.PostgreSQLConn.QueryRow(ctx, `
+		SELECT $1::INTEGER,
+			COALESCE(SUM(amount), 0),
+			COALESCE(SUM(amount) FILTER (WHERE statement_id IS NULL), 0),
+			COALESCE((SELECT SUM(amount) FROM payout_statements WHERE driver_id = $1 AND status = 'pending'), 0)
+		FROM ledger_entries WHERE account = 'driver' AND driver_id = $1`, driver.UserID).
+		Scan(&balance.DriverID, &balance.Balance, &balance.Unstatemented, &balance.PendingPayout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching balance"})
+		return
+	}
+
+	balance.RecentEntries, err = s.driverLedgerEntries(ctx,
+		"le.driver_id = $1 ORDER BY le.created_at DESC, le.id DESC LIMIT $2", driver.UserID, recentEntriesLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching ledger entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"earnings": balance})
+}
+
+func (s *BookingService) HandleDriverStatements(c *gin.Context) {
+	authDriver, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	driver, _ := authDriver.(models.UserRequest)
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(),
+		"SELECT "+payoutStatementColumns+" FROM payout_statements WHERE driver_id = $1 ORDER BY period_start DESC", driver.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching statements"})
+		return
+	}
+	defer rows.Close()
+
+	statements := []models.PayoutStatement{}
+	for rows.Next() {
+		statement, err := scanPayoutStatement(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading statements"})
+			return
+		}
+		statements = append(statements, statement)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statements": statements})
+}
+
+// HandleDriverStatement returns one statement with every ledger entry on it.
+func (s *BookingService) HandleDriverStatement(c *gin.Context) {
+	authDriver, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	driver, _ := authDriver.(models.UserRequest)
+
+	ctx := context.Background()
+	statement, err := scanPayoutStatement(s.PostgreSQLConn.QueryRow(ctx,
+		"SELECT "+payoutStatementColumns+" FROM payout_statements WHERE id = $1 AND driver_id = $2", c.Param("id"), driver.UserID))
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "statement not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching statement"})
+		return
+	}
+
+	statement.Entries, err = s.driverLedgerEntries(ctx,
+		"le.driver_id = $1 AND le.statement_id = $2 ORDER BY le.created_at, le.id", driver.UserID, statement.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching ledger entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statement": statement})
+}
+
+func (s *BookingService) driverLedgerEntries(ctx context.Context, where string, args ...interface{}) ([]models.LedgerEntry, error) {
+	rows, err := s.PostgreSQLConn.Query(ctx, `
+		SELECT le.id, le.kind, le.amount, COALESCE(lt.booking_id, 0), COALESCE(le.statement_id, 0), lt.description, le.created_at
+		FROM ledger_entries le
+		INNER JOIN ledger_transactions lt ON lt.id = le.transaction_id
+		WHERE le.account = 'driver' AND `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.LedgerEntry{}
+	for rows.Next() {
+		var entry models.LedgerEntry
+		if err := rows.Scan(&entry.ID, &entry.Kind, &entry.Amount, &entry.BookingID, &entry.StatementID, &entry.Description, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// RunPayoutScheduler settles payments, posts earnings and issues
+// supplementary invoices that were missed when they happened, and closes each
+// week's payout statements once the week is over.
+func (s *BookingService) RunPayoutScheduler() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(payoutTick)
+	defer ticker.Stop()
+
+	for {
+		ctx := context.Background()
+		// retry captures and voids that could not reach the payment provider
+		if err := s.settlePayments(ctx, "", ""); err != nil {
+			log.Printf("Error settling payments: %v", err)
+		}
+		if err := s.postPendingEarnings(ctx, ""); err != nil {
+			log.Printf("Error posting driver earnings: %v", err)
+		}
+		if err := s.invoicePendingAdjustments(ctx); err != nil {
+			log.Printf("Error invoicing adjustments: %v", err)
+		}
+
+		periodEnd := weekStart(time.Now())
+		if err := s.generateStatements(ctx, periodEnd.AddDate(0, 0, -payoutStatementDays), periodEnd); err != nil {
+			log.Printf("Error generating payout statements: %v", err)
+		}
+
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// postPendingEarnings credits drivers for completed bookings and approved
+// adjustments that have no ledger transaction yet. An empty driverID covers
+// every driver.
+func (s *BookingService) postPendingEarnings(ctx context.Context, driverID string) error {
+	query := `SELECT b.id, b.driver_id, b.price, b.discount, COALESCE(b.currency, '') FROM booking b
+		LEFT JOIN ledger_transactions lt ON lt.reference = 'booking:' || b.id
+		WHERE b.status = 'completed' AND lt.id IS NULL`
+	var args []interface{}
+	if driverID != "" {
+		query += " AND b.driver_id = $1"
+		args = append(args, driverID)
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error fetching unposted bookings: %w", err)
+	}
+
+	var transactions []ledger.Transaction
+	for rows.Next() {
+		var bookingID, bookingDriverID int32
+		var price, discount money.Amount
+		var currency string
+		if err := rows.Scan(&bookingID, &bookingDriverID, &price, &discount, &currency); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading unposted booking: %w", err)
+		}
+		transactions = append(transactions, ledger.TripEarnings(bookingID, bookingDriverID, price, discount, currency))
+	}
+	rows.Close()
+
+	// adjustments are posted when approved; this only catches ones approved
+	// before the ledger existed
+	if driverID == "" {
+		rows, err = s.PostgreSQLConn.Query(ctx, `
+			SELECT a.id, a.booking_id, a.kind, a.description, a.amount, b.driver_id, COALESCE(b.currency, '') FROM booking_adjustments a
+			INNER JOIN booking b ON b.id = a.booking_id
+			LEFT JOIN ledger_transactions lt ON lt.reference = 'adjustment:' || a.id
+			WHERE a.status = 'approved' AND lt.id IS NULL`)
+		if err != nil {
+			return fmt.Errorf("error fetching unposted adjustments: %w", err)
+		}
+
+		for rows.Next() {
+			var adjustment models.BookingAdjustment
+			var adjustmentDriverID int32
+			var currency string
+			if err := rows.Scan(&adjustment.ID, &adjustment.BookingID, &adjustment.Kind, &adjustment.Description, &adjustment.Amount, &adjustmentDriverID, &currency); err != nil {
+				rows.Close()
+				return fmt.Errorf("error reading unposted adjustment: %w", err)
+			}
+			transactions = append(transactions, ledger.AdjustmentEarnings(adjustment, adjustmentDriverID, currency))
+		}
+		rows.Close()
+	}
+
+	for _, txn := range transactions {
+		if err := s.postLedgerTransaction(ctx, txn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *BookingService) postLedgerTransaction(ctx context.Context, txn ledger.Transaction) error {
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := ledger.Post(ctx, tx, txn); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// generateStatements puts every driver entry made before periodEnd that is not
+// on a statement yet onto the driver's statement for the period. Drivers who
+// net nothing or owe the platform get no statement; their entries carry over
+// to the next week.
+func (s *BookingService) generateStatements(ctx context.Context, periodStart, periodEnd time.Time) error {
+	rows, err := s.PostgreSQLConn.Query(ctx,
+		"SELECT DISTINCT driver_id FROM ledger_entries WHERE account = 'driver' AND statement_id IS NULL AND created_at < $1", periodEnd)
+	if err != nil {
+		return fmt.Errorf("error fetching drivers to pay: %w", err)
+	}
+
+	var driverIDs []int32
+	for rows.Next() {
+		var driverID int32
+		if err := rows.Scan(&driverID); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading driver to pay: %w", err)
+		}
+		driverIDs = append(driverIDs, driverID)
+	}
+	rows.Close()
+
+	for _, driverID := range driverIDs {
+		if err := s.generateStatement(ctx, driverID, periodStart, periodEnd); err != nil {
+			log.Printf("Error generating statement for driver %d: %v", driverID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *BookingService) generateStatement(ctx context.Context, driverID int32, periodStart, periodEnd time.Time) error {
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// the unique period keeps two instances from issuing the same statement
+	statement := models.PayoutStatement{DriverID: driverID}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO payout_statements (driver_id, period_start, period_end) VALUES ($1, $2, $3)
+		ON CONFLICT (driver_id, period_start) DO NOTHING RETURNING id`,
+		driverID, periodStart, periodEnd.AddDate(0, 0, -1)).Scan(&statement.ID)
+	if err == pgx.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE ledger_entries SET statement_id = $1
+		WHERE account = 'driver' AND driver_id = $2 AND statement_id IS NULL AND created_at < $3
+		RETURNING kind, amount`, statement.ID, driverID, periodEnd)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var kind string
+		var amount money.Amount
+		if err := rows.Scan(&kind, &amount); err != nil {
+			rows.Close()
+			return err
+		}
+
+		switch kind {
+		case models.EarningTripFare:
+			statement.TripFares += amount
+		case models.EarningCommission:
+			statement.Commission += amount
+		case models.EarningTip:
+			statement.Tips += amount
+		case models.EarningAdjustment:
+			statement.Adjustments += amount
+		case models.EarningBonus:
+			statement.Bonuses += amount
+		case models.EarningPenalty:
+			statement.Penalties += amount
+		}
+		statement.Amount += amount
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if statement.Amount <= 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE payout_statements SET trip_fares = $1, commission = $2, tips = $3, adjustments = $4, bonuses = $5, penalties = $6, amount = $7
+		WHERE id = $8`,
+		statement.TripFares, statement.Commission, statement.Tips, statement.Adjustments, statement.Bonuses, statement.Penalties, statement.Amount,
+		statement.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanPayoutStatement(row pgx.Row) (models.PayoutStatement, error) {
+	var statement models.PayoutStatement
+	err := row.Scan(&statement.ID, &statement.DriverID, &statement.PeriodStart, &statement.PeriodEnd, &statement.TripFares, &statement.Commission,
+		&statement.Tips, &statement.Adjustments, &statement.Bonuses, &statement.Penalties, &statement.Amount, &statement.Status,
+		&statement.SettledAt, &statement.SettlementReference, &statement.CreatedAt)
+	return statement, err
+}
+
+// weekStart returns midnight UTC on the Monday of t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
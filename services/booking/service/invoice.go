@@ -0,0 +1,581 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"logistics-platform/lib/fare"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+)
+
+func (s *BookingService) HandleUserInvoice(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx := context.Background()
+	invoice, err := s.loadInvoice(ctx, int32(bookingID))
+	if err == pgx.ErrNoRows || (err == nil && strconv.Itoa(int(invoice.UserID)) != user.UserID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching invoice"})
+		return
+	}
+
+	supplementary, err := s.loadSupplementaryInvoices(ctx, int32(bookingID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching invoice"})
+		return
+	}
+
+	if c.Query("format") == "pdf" {
+		// ?number= picks one of the booking's supplementary invoices
+		if number := c.Query("number"); number != "" && number != invoice.InvoiceNumber {
+			found := false
+			for _, candidate := range supplementary {
+				if candidate.InvoiceNumber == number {
+					invoice, found = candidate, true
+					break
+				}
+			}
+			if !found {
+				c.JSON(http.StatusNotFound, gin.H{"error": "invoice not found"})
+				return
+			}
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", invoice.InvoiceNumber))
+		c.Data(http.StatusOK, "application/pdf", renderInvoicePDF(invoice))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice": invoice, "supplementary_invoices": supplementary})
+}
+
+// generatePendingInvoices invoices every completed booking between the user
+// and driver that does not have an invoice yet.
+func (s *BookingService) generatePendingInvoices(userID, driverID string) {
+	rows, err := s.PostgreSQLConn.Query(context.Background(),
+		"SELECT b.id FROM booking b LEFT JOIN invoices i ON i.booking_id=b.id AND i.original_invoice_id IS NULL WHERE b.user_id=$1 AND b.driver_id=$2 AND b.status=$3 AND i.id IS NULL",
+		userID, driverID, "completed")
+	if err != nil {
+		log.Printf("Error fetching bookings to invoice: %v", err)
+		return
+	}
+
+	var bookingIDs []int32
+	for rows.Next() {
+		var bookingID int32
+		if err := rows.Scan(&bookingID); err != nil {
+			log.Printf("Error reading booking to invoice: %v", err)
+			rows.Close()
+			return
+		}
+		bookingIDs = append(bookingIDs, bookingID)
+	}
+	rows.Close()
+
+	for _, bookingID := range bookingIDs {
+		if _, err := s.GenerateInvoice(bookingID); err != nil {
+			log.Printf("Error generating invoice for booking %d: %v", bookingID, err)
+		}
+	}
+}
+
+// GenerateInvoice issues the invoice for a completed booking, billing the
+// trip as it was quoted and every adjustment approved so far. Calling it
+// again for an invoiced booking returns the existing invoice.
+func (s *BookingService) GenerateInvoice(bookingID int32) (models.Invoice, error) {
+	ctx := context.Background()
+
+	invoice, err := s.loadInvoice(ctx, bookingID)
+	if err == nil {
+		return invoice, nil
+	} else if err != pgx.ErrNoRows {
+		return models.Invoice{}, fmt.Errorf("error fetching invoice: %w", err)
+	}
+
+	draft := invoiceDraft{bookingID: bookingID}
+	var vehicleType, status string
+	var price, discount, sharedDiscount money.Amount
+	var regionID int32
+	var experiment experimentVariant
+	var bookedAt time.Time
+	var quote tripQuote
+	var cargo models.Cargo
+	err = s.PostgreSQLConn.QueryRow(ctx,
+		"SELECT user_id, driver_id, vehicle_type, price, discount, status, created_at, COALESCE(currency, ''), COALESCE(region_id, 0), tax_name, tax_rate, tax_inclusive, cargo_weight, cargo_volume, fragile, hazardous, loading_assistance, COALESCE(experiment_id, 0), COALESCE(experiment_variant, ''), quoted_duration IS NOT NULL, COALESCE(quoted_distance, 0), COALESCE(quoted_duration, 0), COALESCE(surge_amount, 0), shared_discount FROM booking WHERE id=$1",
+		bookingID).Scan(&draft.userID, &draft.driverID, &vehicleType, &price, &discount, &status, &bookedAt, &draft.currency, &regionID, &draft.rule.name, &draft.rule.rate, &draft.rule.inclusive, &cargo.Weight, &cargo.Volume, &cargo.Fragile, &cargo.Hazardous, &cargo.LoadingAssistance, &experiment.id, &experiment.name, &quote.quoted, &quote.distance, &quote.duration, &quote.surge, &sharedDiscount)
+	if err != nil {
+		return models.Invoice{}, fmt.Errorf("error fetching booking: %w", err)
+	}
+
+	if status != "completed" {
+		return models.Invoice{}, fmt.Errorf("booking %d is not completed", bookingID)
+	}
+
+	draft.lineItems = buildLineItems(vehicleType, regionID, experiment, draft.currency, price, discount, sharedDiscount, bookedAt, quote, cargo, draft.rule)
+	invoice, issued, err := s.issueInvoice(ctx, draft)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+	if !issued {
+		// another instance invoiced the booking while we waited on the counter lock
+		return s.loadInvoice(ctx, bookingID)
+	}
+
+	go s.produceInvoiceEvent(invoice)
+	return invoice, nil
+}
+
+// invoiceAdjustments bills the adjustments approved since a booking was
+// invoiced on a supplementary invoice, charged to the card the booking was
+// paid with. Bookings that are not invoiced yet are left alone; their invoice
+// picks the adjustments up when it is issued.
+func (s *BookingService) invoiceAdjustments(bookingID int32) {
+	ctx := context.Background()
+
+	draft := invoiceDraft{bookingID: bookingID}
+	err := s.PostgreSQLConn.QueryRow(ctx,
+		"SELECT b.user_id, b.driver_id, COALESCE(b.currency, ''), b.tax_name, b.tax_rate, b.tax_inclusive, i.id FROM booking b INNER JOIN invoices i ON i.booking_id=b.id AND i.original_invoice_id IS NULL WHERE b.id=$1",
+		bookingID).Scan(&draft.userID, &draft.driverID, &draft.currency, &draft.rule.name, &draft.rule.rate, &draft.rule.inclusive, &draft.originalID)
+	if err == pgx.ErrNoRows {
+		return
+	} else if err != nil {
+		log.Printf("Error fetching invoice of booking %d: %v", bookingID, err)
+		return
+	}
+
+	invoice, issued, err := s.issueInvoice(ctx, draft)
+	if err != nil {
+		log.Printf("Error invoicing adjustments of booking %d: %v", bookingID, err)
+		return
+	}
+	if issued {
+		s.produceInvoiceEvent(invoice)
+		if err := s.chargeInvoices(ctx, bookingID); err != nil {
+			log.Printf("Error charging invoices of booking %d: %v", bookingID, err)
+		}
+	}
+}
+
+// invoicePendingAdjustments issues the supplementary invoices missed when
+// an adjustment was approved, e.g. while the booking was being invoiced.
+func (s *BookingService) invoicePendingAdjustments(ctx context.Context) error {
+	rows, err := s.PostgreSQLConn.Query(ctx,
+		"SELECT DISTINCT a.booking_id FROM booking_adjustments a INNER JOIN invoices i ON i.booking_id=a.booking_id AND i.original_invoice_id IS NULL WHERE a.status='approved' AND a.invoice_id IS NULL")
+	if err != nil {
+		return err
+	}
+
+	var bookingIDs []int32
+	for rows.Next() {
+		var bookingID int32
+		if err := rows.Scan(&bookingID); err != nil {
+			rows.Close()
+			return err
+		}
+		bookingIDs = append(bookingIDs, bookingID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, bookingID := range bookingIDs {
+		s.invoiceAdjustments(bookingID)
+	}
+	return nil
+}
+
+// invoiceDraft is an invoice about to be issued. A zero originalID issues the
+// booking's invoice; otherwise a supplementary invoice to it.
+type invoiceDraft struct {
+	bookingID  int32
+	userID     int32
+	driverID   int32
+	currency   string
+	rule       taxRule
+	lineItems  []models.InvoiceLineItem
+	originalID int32
+}
+
+// issueInvoice issues the draft together with the approved adjustments not
+// on an invoice yet, which it marks as billed so no later invoice repeats
+// them. The invoice number is taken from a row-locked counter in the same
+// transaction as the insert, so a failed insert never consumes a number.
+// Nothing is issued, and issued is false, when the booking already has its
+// invoice or a supplementary invoice would have no adjustments to bill.
+func (s *BookingService) issueInvoice(ctx context.Context, draft invoiceDraft) (invoice models.Invoice, issued bool, err error) {
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var number int64
+	if err := tx.QueryRow(ctx, "UPDATE invoice_sequence SET last_number = last_number + 1 WHERE id = 1 RETURNING last_number").Scan(&number); err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error allocating invoice number: %w", err)
+	}
+
+	if draft.originalID == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM invoices WHERE booking_id=$1 AND original_invoice_id IS NULL)", draft.bookingID).Scan(&exists); err != nil {
+			return models.Invoice{}, false, fmt.Errorf("error checking invoice: %w", err)
+		}
+		if exists {
+			return models.Invoice{}, false, nil
+		}
+	}
+
+	adjustments, err := lockUninvoicedAdjustments(ctx, tx, draft.bookingID)
+	if err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error fetching adjustments: %w", err)
+	}
+	if draft.originalID != 0 && len(adjustments) == 0 {
+		return models.Invoice{}, false, nil
+	}
+
+	lineItems := append(draft.lineItems, adjustmentLineItems(adjustments, draft.rule, draft.currency)...)
+	var subtotal, tax money.Amount
+	for _, item := range lineItems {
+		switch item.Kind {
+		case models.LineItemTax:
+			tax += item.Amount
+		case models.LineItemTaxIncluded:
+			// already in the other items, so the subtotal is shown net of it
+			tax += item.Amount
+			subtotal -= item.Amount
+		default:
+			subtotal += item.Amount
+		}
+	}
+
+	lineItemsJSON, err := json.Marshal(lineItems)
+	if err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error marshaling line items: %w", err)
+	}
+
+	var invoiceID int32
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO invoices (invoice_number, booking_id, user_id, driver_id, line_items, subtotal, tax, total, original_invoice_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		fmt.Sprintf("INV-%08d", number), draft.bookingID, draft.userID, draft.driverID, lineItemsJSON, subtotal, tax, subtotal+tax, nullableID(draft.originalID)).Scan(&invoiceID); err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error storing invoice: %w", err)
+	}
+
+	adjustmentIDs := make([]int32, len(adjustments))
+	for i, adjustment := range adjustments {
+		adjustmentIDs[i] = adjustment.ID
+	}
+	if _, err := tx.Exec(ctx, "UPDATE booking_adjustments SET invoice_id = $1 WHERE id = ANY($2)", invoiceID, adjustmentIDs); err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error marking adjustments invoiced: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error committing invoice: %w", err)
+	}
+
+	invoice, err = scanInvoice(s.PostgreSQLConn.QueryRow(ctx, invoiceQuery+" WHERE i.id=$1", invoiceID))
+	if err != nil {
+		return models.Invoice{}, false, fmt.Errorf("error fetching invoice: %w", err)
+	}
+	return invoice, true, nil
+}
+
+// lockUninvoicedAdjustments returns the approved adjustments of a booking not
+// billed on any invoice, locked until the transaction ends.
+func lockUninvoicedAdjustments(ctx context.Context, tx pgx.Tx, bookingID int32) ([]models.BookingAdjustment, error) {
+	rows, err := tx.Query(ctx,
+		"SELECT id, booking_id, kind, description, quantity, unit, amount FROM booking_adjustments WHERE booking_id = $1 AND status = 'approved' AND invoice_id IS NULL ORDER BY created_at, id FOR UPDATE",
+		bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var adjustments []models.BookingAdjustment
+	for rows.Next() {
+		var adjustment models.BookingAdjustment
+		if err := rows.Scan(&adjustment.ID, &adjustment.BookingID, &adjustment.Kind, &adjustment.Description, &adjustment.Quantity, &adjustment.Unit, &adjustment.Amount); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments, rows.Err()
+}
+
+const invoiceQuery = "SELECT i.id, i.invoice_number, COALESCE(o.invoice_number, ''), i.booking_id, i.user_id, u.name, i.driver_id, d.name, b.vehicle_type, b.pickup_latitude, b.pickup_longitude, b.pickup_name, b.dropoff_latitude, b.dropoff_longitude, b.dropoff_name, i.line_items, i.subtotal, i.tax, i.total, i.issued_at, COALESCE(b.currency, '') FROM invoices i INNER JOIN booking b ON b.id=i.booking_id INNER JOIN users u ON u.id=i.user_id INNER JOIN vehicle_drivers d ON d.id=i.driver_id LEFT JOIN invoices o ON o.id=i.original_invoice_id"
+
+// loadInvoice returns a booking's invoice, without its supplementary
+// invoices.
+func (s *BookingService) loadInvoice(ctx context.Context, bookingID int32) (models.Invoice, error) {
+	return scanInvoice(s.PostgreSQLConn.QueryRow(ctx, invoiceQuery+" WHERE i.booking_id=$1 AND i.original_invoice_id IS NULL", bookingID))
+}
+
+// loadSupplementaryInvoices returns the invoices issued for a booking's later
+// adjustments, oldest first.
+func (s *BookingService) loadSupplementaryInvoices(ctx context.Context, bookingID int32) ([]models.Invoice, error) {
+	rows, err := s.PostgreSQLConn.Query(ctx, invoiceQuery+" WHERE i.booking_id=$1 AND i.original_invoice_id IS NOT NULL ORDER BY i.id", bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []models.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	return invoices, rows.Err()
+}
+
+func scanInvoice(row pgx.Row) (models.Invoice, error) {
+	var invoice models.Invoice
+	var lineItemsJSON []byte
+	err := row.Scan(&invoice.ID, &invoice.InvoiceNumber, &invoice.OriginalInvoiceNumber, &invoice.BookingID, &invoice.UserID, &invoice.UserName, &invoice.DriverID, &invoice.DriverName, &invoice.VehicleType, &invoice.Pickup.Latitude, &invoice.Pickup.Longitude, &invoice.Pickup.Name, &invoice.Dropoff.Latitude, &invoice.Dropoff.Longitude, &invoice.Dropoff.Name, &lineItemsJSON, &invoice.Subtotal, &invoice.Tax, &invoice.Total, &invoice.IssuedAt, &invoice.Currency)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	if err := json.Unmarshal(lineItemsJSON, &invoice.LineItems); err != nil {
+		return models.Invoice{}, fmt.Errorf("error reading line items: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// taxRule is the tax a booking was priced under. Bookings priced before
+// regional taxes were recorded have no rule and are taxed at
+// INVOICE_TAX_RATE on top of the fare.
+type taxRule struct {
+	name      *string
+	rate      *float64
+	inclusive bool
+}
+
+func (r taxRule) resolve() (name string, rate float64, inclusive bool) {
+	if r.rate == nil {
+		return "Tax", viper.GetFloat64("INVOICE_TAX_RATE"), false
+	}
+	name = "Tax"
+	if r.name != nil && *r.name != "" {
+		name = *r.name
+	}
+	return name, *r.rate, r.inclusive
+}
+
+// taxLineItem taxes amount under the rule, rounded to currency. An inclusive
+// tax is the share of amount that is tax, itemised as included rather than
+// added.
+func (r taxRule) taxLineItem(amount money.Amount, description, currency string) models.InvoiceLineItem {
+	name, rate, inclusive := r.resolve()
+	if inclusive {
+		return models.InvoiceLineItem{
+			Kind:        models.LineItemTaxIncluded,
+			Description: fmt.Sprintf("%s included%s (%g%%)", name, description, rate),
+			Amount:      amount.Mul(rate / (100 + rate)).Round(currency),
+		}
+	}
+	return models.InvoiceLineItem{
+		Kind:        models.LineItemTax,
+		Description: fmt.Sprintf("%s%s (%g%%)", name, description, rate),
+		Amount:      amount.Percent(rate).Round(currency),
+	}
+}
+
+// experimentVariant is the pricing experiment variant a booking was quoted
+// under; a zero id means none.
+type experimentVariant struct {
+	id   int32
+	name string
+}
+
+// adjustmentLineItems itemises tips and surcharges, with the tax on the
+// surcharges. Tips go to the driver untaxed.
+func adjustmentLineItems(adjustments []models.BookingAdjustment, rule taxRule, currency string) []models.InvoiceLineItem {
+	var lineItems []models.InvoiceLineItem
+	var taxable money.Amount
+	for _, adjustment := range adjustments {
+		kind := models.LineItemAdjustment
+		if adjustment.Kind == models.AdjustmentTip {
+			kind = models.LineItemTip
+		} else {
+			taxable += adjustment.Amount
+		}
+
+		lineItems = append(lineItems, models.InvoiceLineItem{
+			Kind:        kind,
+			Description: adjustment.Description,
+			Quantity:    adjustment.Quantity,
+			Unit:        adjustment.Unit,
+			Amount:      adjustment.Amount,
+		})
+	}
+
+	if _, rate, _ := rule.resolve(); rate != 0 && taxable != 0 {
+		lineItems = append(lineItems, rule.taxLineItem(taxable, " on adjustments", currency))
+	}
+
+	return lineItems
+}
+
+// tripQuote is the routed trip and surge charge a booking was quoted with;
+// quoted is false for bookings priced before quotes carried them.
+type tripQuote struct {
+	quoted   bool
+	distance float64
+	duration float64
+	surge    money.Amount
+}
+
+// buildLineItems splits the agreed booking price back into the components of
+// the pricing formula. Base, distance, time and the cargo charges and
+// surcharges come from the vehicle's rate card of the booking's pricing
+// region in effect when the trip was booked, with the rates of the experiment
+// variant it was quoted under, applied to the quoted trip and the cargo
+// booked, and surge is the surge charge quoted. Anything else the price
+// differs by is shown as a fare adjustment. When the quote or the rate card is
+// unavailable the whole price is invoiced as a single base fare. The shared
+// load and promo code discounts, already taken off price, are shown as their
+// own negative lines.
+func buildLineItems(vehicleType string, regionID int32, experiment experimentVariant, currency string, price, discount, sharedDiscount money.Amount, bookedAt time.Time, quote tripQuote, cargo models.Cargo, rule taxRule) []models.InvoiceLineItem {
+	var lineItems []models.InvoiceLineItem
+	price += discount + sharedDiscount
+
+	var vehiclePricing models.VehiclePricing
+	var err error
+	if quote.quoted {
+		vehiclePricing, err = fetchVehiclePricing(vehicleType, regionID, bookedAt, experiment.id, experiment.name)
+		if err != nil {
+			log.Printf("Error fetching vehicle pricing for %s: %v", vehicleType, err)
+		}
+	}
+	if !quote.quoted || err != nil {
+		lineItems = append(lineItems, models.InvoiceLineItem{
+			Kind:        models.LineItemBase,
+			Description: "Trip fare (" + vehicleType + ")",
+			Amount:      price.Round(currency),
+		})
+	} else {
+		distance, duration, surge := quote.distance, quote.duration, quote.surge.Round(currency)
+		components, surcharges := fare.Compute(vehiclePricing, distance, duration, cargo, currency)
+		remainder := (price - components.Total() - surcharges.Total() - surge).Round(currency)
+
+		lineItems = append(lineItems,
+			models.InvoiceLineItem{Kind: models.LineItemBase, Description: "Base fare (" + vehicleType + ")", Amount: components.BaseFare},
+			models.InvoiceLineItem{Kind: models.LineItemDistance, Description: "Distance charge", Quantity: roundQuantity(distance), Unit: "km", Amount: components.DistanceCharge},
+			models.InvoiceLineItem{Kind: models.LineItemTime, Description: "Time charge", Quantity: roundQuantity(duration), Unit: "min", Amount: components.TimeCharge},
+		)
+		if components.WeightCharge != 0 {
+			lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemWeight, Description: "Weight charge", Quantity: cargo.Weight, Unit: "kg", Amount: components.WeightCharge})
+		}
+		if components.VolumeCharge != 0 {
+			lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemVolume, Description: "Volume charge", Quantity: cargo.Volume, Unit: "m3", Amount: components.VolumeCharge})
+		}
+		if components.MinimumChargeAdjustment != 0 {
+			lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemMinimum, Description: "Minimum charge adjustment", Amount: components.MinimumChargeAdjustment})
+		}
+		if surge != 0 {
+			lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemSurge, Description: "Surge pricing", Amount: surge})
+		}
+		for _, surcharge := range []struct {
+			description string
+			amount      money.Amount
+		}{
+			{"Fragile goods surcharge", surcharges.Fragile},
+			{"Hazardous goods surcharge", surcharges.Hazardous},
+			{"Loading assistance", surcharges.LoadingAssistance},
+		} {
+			if surcharge.amount != 0 {
+				lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemSurcharge, Description: surcharge.description, Amount: surcharge.amount})
+			}
+		}
+		if remainder != 0 {
+			lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemAdjustment, Description: "Fare adjustment", Amount: remainder})
+		}
+	}
+
+	if sharedDiscount != 0 {
+		lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemDiscount, Description: "Shared load discount", Amount: -sharedDiscount.Round(currency)})
+	}
+	if discount != 0 {
+		lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemDiscount, Description: "Promo code discount", Amount: -discount.Round(currency)})
+	}
+
+	taxable := (price - discount - sharedDiscount).Round(currency)
+	if fee := platformFee(currency); fee != 0 {
+		lineItems = append(lineItems, models.InvoiceLineItem{Kind: models.LineItemFee, Description: "Platform fee", Amount: fee})
+		taxable += fee
+	}
+
+	if _, rate, _ := rule.resolve(); rate != 0 {
+		lineItems = append(lineItems, rule.taxLineItem(taxable, "", currency))
+	}
+
+	return lineItems
+}
+
+// platformFee is the flat fee added to every trip's invoice.
+func platformFee(currency string) money.Amount {
+	return money.FromFloat(viper.GetFloat64("INVOICE_PLATFORM_FEE")).Round(currency)
+}
+
+// payableTotal is what the invoice of a trip at price will total: the fare,
+// the platform fee and any tax added on top of them.
+func payableTotal(price money.Amount, currency string, rule taxRule) money.Amount {
+	total := price.Round(currency) + platformFee(currency)
+	if _, rate, inclusive := rule.resolve(); rate != 0 && !inclusive {
+		total += rule.taxLineItem(total, "", currency).Amount
+	}
+	return total
+}
+
+// requestTaxRule is the tax rule a booking request was priced under, the
+// same one its booking is invoiced with.
+func requestTaxRule(bookingReq models.BookingRequest) taxRule {
+	if bookingReq.Currency == "" {
+		return taxRule{}
+	}
+	return taxRule{name: &bookingReq.TaxName, rate: &bookingReq.TaxRate, inclusive: bookingReq.TaxInclusive}
+}
+
+func (s *BookingService) produceInvoiceEvent(invoice models.Invoice) {
+	s.writeBookingEvent(models.BookedNotification{
+		UserID:        strconv.Itoa(int(invoice.UserID)),
+		DriverID:      strconv.Itoa(int(invoice.DriverID)),
+		DriverName:    invoice.DriverName,
+		Status:        "invoice_issued",
+		BookingID:     invoice.BookingID,
+		InvoiceNumber: invoice.InvoiceNumber,
+	})
+}
+
+// roundQuantity rounds a distance or duration to two places for display.
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*100) / 100
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"logistics-platform/lib/models"
+	"strings"
+)
+
+// renderInvoicePDF lays the invoice out as a PDF using the built-in Courier
+// font, so amounts line up and no font files or PDF libraries are needed.
+func renderInvoicePDF(invoice models.Invoice) []byte {
+	lines := []string{
+		"Invoice " + invoice.InvoiceNumber,
+		"Issued: " + invoice.IssuedAt.Format("2006-01-02 15:04 MST"),
+		fmt.Sprintf("Booking: %d", invoice.BookingID),
+		"Customer: " + invoice.UserName,
+		"Driver: " + invoice.DriverName + " (" + invoice.VehicleType + ")",
+		"From: " + invoice.Pickup.Name,
+		"To: " + invoice.Dropoff.Name,
+		"",
+	}
+	if invoice.OriginalInvoiceNumber != "" {
+		lines = append(lines[:2], append([]string{"Supplementary to invoice " + invoice.OriginalInvoiceNumber}, lines[2:]...)...)
+	}
+	for _, item := range invoice.LineItems {
+		description := item.Description
+		if item.Unit != "" {
+			description = fmt.Sprintf("%s (%.2f %s)", description, item.Quantity, item.Unit)
+		}
+		lines = append(lines, fmt.Sprintf("%-48s %10s", description, item.Amount.Format(invoice.Currency)))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%-48s %10s", "Subtotal", invoice.Subtotal.Format(invoice.Currency)),
+		fmt.Sprintf("%-48s %10s", "Tax", invoice.Tax.Format(invoice.Currency)),
+		fmt.Sprintf("%-48s %10s", "Total", invoice.Total.Format(invoice.Currency)),
+	)
+
+	return writePDF(lines)
+}
+
+// invoiceLinesPerPage is how many 14pt lines fit between the top margin and
+// the page number at the foot of an A4 page.
+const invoiceLinesPerPage = 52
+
+// writePDF sets lines in 11pt Courier on as many A4 pages as they take,
+// numbering the pages when there is more than one. Objects 1 to 3 are the
+// catalog, the page tree and the font; each page and its content stream
+// follow.
+func writePDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > invoiceLinesPerPage {
+		pages = append(pages, lines[:invoiceLinesPerPage])
+		lines = lines[invoiceLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(pages))
+	for i, page := range pages {
+		var content bytes.Buffer
+		content.WriteString("BT\n/F1 11 Tf\n14 TL\n50 790 Td\n")
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", encodePDFText(line))
+		}
+		content.WriteString("ET\n")
+		if len(pages) > 1 {
+			fmt.Fprintf(&content, "BT\n/F1 9 Tf\n480 30 Td\n(Page %d of %d) Tj\nET\n", i+1, len(pages))
+		}
+
+		kids[i] = fmt.Sprintf("%d 0 R", len(objects)+1)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", len(objects)+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return pdf.Bytes()
+}
+
+// winAnsi maps the characters WinAnsiEncoding places in 0x80-0x9F. Latin-1
+// characters keep their code, and the rest cannot be shown in the
+// built-in fonts.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encodePDFText encodes text as the body of a PDF string in WinAnsiEncoding.
+// Delimiters and backslashes are escaped, control characters become spaces
+// and characters the encoding lacks become question marks.
+func encodePDFText(text string) string {
+	var encoded strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			encoded.WriteByte('\\')
+			encoded.WriteRune(r)
+		case r < 0x20 || r == 0x7F:
+			encoded.WriteByte(' ')
+		case r < 0x7F:
+			encoded.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			encoded.WriteByte(byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				encoded.WriteByte(b)
+			} else {
+				encoded.WriteByte('?')
+			}
+		}
+	}
+	return encoded.String()
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"logistics-platform/lib/models"
+)
+
+var (
+	pdfObject    = regexp.MustCompile(`(?s)^(\d+) 0 obj\n(.*?)\nendobj\n`)
+	pdfStream    = regexp.MustCompile(`(?s)^<< /Length (\d+) >>\nstream\n(.*)endstream$`)
+	pdfReference = regexp.MustCompile(`(\d+) 0 R`)
+	pdfShownText = regexp.MustCompile(`\(((?:[^\\()]|\\.)*)\) Tj`)
+)
+
+// parsePDF checks the cross-reference table and content stream lengths of a
+// rendered PDF and returns the text shown on each page, decoded back from
+// WinAnsiEncoding.
+func parsePDF(t *testing.T, pdf []byte) [][]string {
+	t.Helper()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	trailer := pdf[bytes.LastIndex(pdf, []byte("startxref\n"))+len("startxref\n"):]
+	xref, err := strconv.Atoi(string(trailer[:bytes.IndexByte(trailer, '\n')]))
+	if err != nil || !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point to the xref table")
+	}
+
+	var size int
+	if _, err := fmt.Sscanf(string(pdf[xref:]), "xref\n0 %d\n", &size); err != nil {
+		t.Fatalf("xref header: %v", err)
+	}
+	entries := strings.Split(string(pdf[xref:]), "\n")[3 : size+2]
+	objects := make(map[int]string, len(entries))
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("xref entry %q: %v", entry, err)
+		}
+		match := pdfObject.FindSubmatch(pdf[offset:])
+		if match == nil || string(match[1]) != strconv.Itoa(i+1) {
+			t.Fatalf("xref entry %d does not point to object %d", i+1, i+1)
+		}
+		objects[i+1] = string(match[2])
+	}
+	if !strings.Contains(string(pdf[xref:]), fmt.Sprintf("/Size %d /Root 1 0 R", size)) {
+		t.Errorf("trailer does not give /Size %d", size)
+	}
+
+	var pages [][]string
+	for _, kid := range pdfReference.FindAllStringSubmatch(objects[2], -1) {
+		page, _ := strconv.Atoi(kid[1])
+		contents := regexp.MustCompile(`/Contents (\d+) 0 R`).FindStringSubmatch(objects[page])
+		if contents == nil {
+			t.Fatalf("page object %d has no contents", page)
+		}
+		id, _ := strconv.Atoi(contents[1])
+		stream := pdfStream.FindStringSubmatch(objects[id])
+		if stream == nil {
+			t.Fatalf("object %d is not a stream", id)
+		}
+		if length, _ := strconv.Atoi(stream[1]); length != len(stream[2]) {
+			t.Errorf("stream %d /Length = %d, want %d", id, length, len(stream[2]))
+		}
+
+		var text []string
+		for _, shown := range pdfShownText.FindAllStringSubmatch(stream[2], -1) {
+			text = append(text, decodePDFText(shown[1]))
+		}
+		pages = append(pages, text)
+	}
+	if want := fmt.Sprintf("/Count %d", len(pages)); !strings.Contains(objects[2], want) {
+		t.Errorf("page tree %q, want %s", objects[2], want)
+	}
+	return pages
+}
+
+// decodePDFText reverses encodePDFText, for the characters WinAnsiEncoding has.
+func decodePDFText(encoded string) string {
+	fromWinAnsi := make(map[byte]rune, len(winAnsi))
+	for r, b := range winAnsi {
+		fromWinAnsi[b] = r
+	}
+
+	var text strings.Builder
+	for i := 0; i < len(encoded); i++ {
+		b := encoded[i]
+		if b == '\\' {
+			i++
+			b = encoded[i]
+		}
+		if r, ok := fromWinAnsi[b]; ok {
+			text.WriteRune(r)
+		} else {
+			text.WriteRune(rune(b))
+		}
+	}
+	return text.String()
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	tests := []struct {
+		name      string
+		userName  string
+		lineItems int
+		wantUser  string
+		wantPages int
+	}{
+		{name: "single page", userName: "Ada Lovelace", lineItems: 3, wantUser: "Customer: Ada Lovelace", wantPages: 1},
+		{name: "escaped delimiters", userName: `Smith (Acme) \ Co`, lineItems: 3, wantUser: `Customer: Smith (Acme) \ Co`, wantPages: 1},
+		{name: "Latin-1 and WinAnsi characters", userName: "Zoë Müller – Café €", lineItems: 3, wantUser: "Customer: Zoë Müller – Café €", wantPages: 1},
+		{name: "unencodable characters and line breaks", userName: "李\nTruck 🚚", lineItems: 3, wantUser: "Customer: ? Truck ?", wantPages: 1},
+		{name: "fills the first page", userName: "Ada", lineItems: invoiceLinesPerPage - 12, wantUser: "Customer: Ada", wantPages: 1},
+		{name: "runs over several pages", userName: "Ada", lineItems: 120, wantUser: "Customer: Ada", wantPages: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := models.Invoice{
+				InvoiceNumber: "INV-000042",
+				BookingID:     42,
+				UserName:      tt.userName,
+				DriverName:    "Grace",
+				VehicleType:   "van",
+				IssuedAt:      time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+			}
+			for i := 0; i < tt.lineItems; i++ {
+				invoice.LineItems = append(invoice.LineItems, models.InvoiceLineItem{Description: fmt.Sprintf("Item %d", i+1)})
+			}
+
+			pages := parsePDF(t, renderInvoicePDF(invoice))
+			if len(pages) != tt.wantPages {
+				t.Fatalf("rendered %d pages, want %d", len(pages), tt.wantPages)
+			}
+
+			var text []string
+			for i, page := range pages {
+				if tt.wantPages > 1 {
+					footer := fmt.Sprintf("Page %d of %d", i+1, tt.wantPages)
+					if page[len(page)-1] != footer {
+						t.Errorf("page %d ends with %q, want %q", i+1, page[len(page)-1], footer)
+					}
+					page = page[:len(page)-1]
+				}
+				if len(page) > invoiceLinesPerPage {
+					t.Errorf("page %d has %d lines, want at most %d", i+1, len(page), invoiceLinesPerPage)
+				}
+				text = append(text, page...)
+			}
+
+			if text[0] != "Invoice INV-000042" {
+				t.Errorf("first line = %q, want the invoice number", text[0])
+			}
+			if !contains(text, tt.wantUser) {
+				t.Errorf("no line %q in %q", tt.wantUser, text[:8])
+			}
+			for i := 0; i < tt.lineItems; i++ {
+				if item := fmt.Sprintf("Item %d ", i+1); !containsPrefix(text, item) {
+					t.Errorf("line item %d missing", i+1)
+				}
+			}
+			if last := text[len(text)-1]; !strings.HasPrefix(last, "Total") {
+				t.Errorf("last line = %q, want the total", last)
+			}
+		})
+	}
+}
+
+func contains(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPrefix(lines []string, prefix string) bool {
+	for _, line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	errInvalidOfferID = errors.New("invalid mongo id")
+	errOfferNotFound  = errors.New("booking request not found")
+)
+
+var declineReasons = map[string]bool{
+	models.DeclineReasonTooFar:       true,
+	models.DeclineReasonPriceTooLow:  true,
+	models.DeclineReasonWrongVehicle: true,
+	models.DeclineReasonBusy:         true,
+	models.DeclineReasonOther:        true,
+}
+
+// acceptOffer turns the offered booking request, or the shared pool offered in
+// its place, into bookings for the driver. The request is removed atomically
+// so only the first driver to accept gets it. It returns the users whose
+// bookings the driver now carries and, for a shared trip, its stops.
+func (s *BookingService) acceptOffer(mongoID string, driver models.UserRequest) ([]string, []models.Stop, error) {
+	objectID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return nil, nil, errInvalidOfferID
+	}
+
+	collection := s.mongoClient.Database("logistics").Collection("booking_requests")
+	bookConReq := models.BookingConfirmation{DriverID: driver.UserID, DriverName: driver.UserName}
+	var request bson.Raw
+	err = collection.FindOneAndDelete(context.Background(), bson.M{"_id": objectID}).Decode(&request)
+	if err == nil {
+		err = bson.Unmarshal(request, &bookConReq.BookingReq)
+	}
+	if err == mongo.ErrNoDocuments {
+		// offers for shared trips carry the id of the pool instead of a request
+		userIDs, stops, err := s.acceptSharedPool(objectID, driver)
+		if err == nil {
+			s.recordOfferAccepted(mongoID, driver.UserID)
+		}
+		return userIDs, stops, err
+	} else if err != nil {
+		return nil, nil, errOfferNotFound
+	}
+
+	bookConReq.BookingReq.MongoID = mongoID
+	if err := s.ProcessBooked(bookConReq); err != nil {
+		// the accept fails; unless its promotion ran out the request goes back
+		// to waiting for a driver
+		if !isPromotionError(err) {
+			if _, insErr := collection.InsertOne(context.Background(), request); insErr != nil {
+				log.Printf("Error restoring booking request %s: %v", mongoID, insErr)
+			}
+		}
+		return nil, nil, err
+	}
+
+	s.recordOfferAccepted(mongoID, driver.UserID)
+	return []string{bookConReq.BookingReq.UserID}, nil, nil
+}
+
+// declineOffer records that the driver turned down an offer they were sent and
+// have not answered yet. Unknown reasons are stored as "other" so the stats
+// stay groupable.
+func (s *BookingService) declineOffer(action models.OfferAction) error {
+	if _, err := primitive.ObjectIDFromHex(action.MongoID); err != nil {
+		return errInvalidOfferID
+	}
+
+	reason := action.Reason
+	if !declineReasons[reason] {
+		reason = models.DeclineReasonOther
+	}
+
+	pgComm, err := s.PostgreSQLConn.Exec(context.Background(),
+		"UPDATE driver_offers SET response = 'declined', reason = $1, note = $2, responded_at = NOW() WHERE mongo_id = $3 AND driver_id = $4 AND response IS NULL",
+		reason, nullableString(action.Note), action.MongoID, action.DriverID)
+	if err != nil {
+		return fmt.Errorf("error recording offer response: %w", err)
+	}
+	if pgComm.RowsAffected() == 0 {
+		return errOfferNotFound
+	}
+	return nil
+}
+
+// recordOffer notes that a driver was offered a request, so that offers the
+// driver never answers can be counted as ignored.
+func (s *BookingService) recordOffer(mongoID, driverID string) {
+	_, err := s.PostgreSQLConn.Exec(context.Background(),
+		"INSERT INTO driver_offers (mongo_id, driver_id) VALUES ($1, $2) ON CONFLICT (mongo_id, driver_id) DO NOTHING",
+		mongoID, driverID)
+	if err != nil {
+		log.Printf("Error recording offer %s for driver %s: %v", mongoID, driverID, err)
+	}
+}
+
+// recordOfferAccepted notes the driver who took an offer and closes the same
+// offer for every other driver who had not answered it yet, so they are not
+// counted as having ignored it.
+func (s *BookingService) recordOfferAccepted(mongoID, driverID string) {
+	_, err := s.PostgreSQLConn.Exec(context.Background(), `
+		INSERT INTO driver_offers (mongo_id, driver_id, response, responded_at) VALUES ($1, $2, 'accepted', NOW())
+		ON CONFLICT (mongo_id, driver_id) DO UPDATE SET response = EXCLUDED.response, responded_at = EXCLUDED.responded_at`,
+		mongoID, driverID)
+	if err != nil {
+		log.Printf("Error recording accepted offer %s for driver %s: %v", mongoID, driverID, err)
+	}
+
+	_, err = s.PostgreSQLConn.Exec(context.Background(),
+		"UPDATE driver_offers SET response = 'taken', responded_at = NOW() WHERE mongo_id = $1 AND driver_id <> $2 AND response IS NULL",
+		mongoID, driverID)
+	if err != nil {
+		log.Printf("Error closing competing offers for %s: %v", mongoID, err)
+	}
+}
+
+// ConsumeOfferActions handles the accept_offer and decline_offer messages
+// drivers send on their WebSocket and acknowledges each one back through the
+// notification service.
+func (s *BookingService) ConsumeOfferActions() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.shutdown:
+			log.Println("Stopping offer action consumer")
+			return
+		default:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			msg, err := s.offerActionReader.FetchMessage(ctx)
+			cancel()
+
+			if err != nil {
+				if err == context.DeadlineExceeded {
+					time.Sleep(1 * time.Second)
+				} else {
+					log.Printf("Error fetching offer action: %v", err)
+				}
+				continue
+			}
+
+			var action models.OfferAction
+			if err := json.Unmarshal(msg.Value, &action); err != nil {
+				log.Printf("Error unmarshaling offer action: %v", err)
+			} else {
+				s.writeOfferResult(s.handleOfferAction(action))
+			}
+
+			if err := s.offerActionReader.CommitMessages(context.Background(), msg); err != nil {
+				log.Printf("Error committing message: %v", err)
+			}
+		}
+	}
+}
+
+func (s *BookingService) handleOfferAction(action models.OfferAction) models.OfferActionResult {
+	result := models.OfferActionResult{
+		Type:      "offer_ack",
+		RequestID: action.RequestID,
+		Action:    action.Type,
+		MongoID:   action.MongoID,
+		DriverID:  action.DriverID,
+	}
+
+	var err error
+	switch action.Type {
+	case models.OfferActionAccept:
+		result.UserIDs, result.Stops, err = s.acceptOffer(action.MongoID, models.UserRequest{UserID: action.DriverID, UserName: action.DriverName})
+	case models.OfferActionDecline:
+		err = s.declineOffer(action)
+	default:
+		err = fmt.Errorf("unknown offer action %q", action.Type)
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Success = true
+	return result
+}
+
+func (s *BookingService) writeOfferResult(result models.OfferActionResult) {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Error marshaling offer result: %v", err)
+		return
+	}
+
+	if err := s.offerResultWriter.WriteMessages(context.Background(), kafka.Message{Value: resultJSON}); err != nil {
+		log.Printf("Error writing offer result: %v", err)
+	}
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errSpendLimitExceeded = errors.New("organisation monthly spend limit exceeded")
+	errUnknownCostCentre  = errors.New("unknown cost centre")
+	errNoOrganisation     = errors.New("user does not belong to an organisation")
+	errApprovalNotFound   = errors.New("approval not found")
+)
+
+type pendingApproval struct {
+	ID                    primitive.ObjectID `bson:"_id"`
+	models.BookingRequest `bson:",inline"`
+}
+
+func (s *BookingService) HandleCreateOrganisation(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var org models.Organisation
+	if err := c.ShouldBindJSON(&org); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating organisation"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx,
+		"INSERT INTO organisations (name, monthly_spend_limit, approval_threshold, currency) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		org.Name, org.MonthlySpendLimit, org.ApprovalThreshold, nullableString(org.Currency)).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating organisation"})
+		return
+	}
+
+	// the creator manages the account until other finance members are added
+	if _, err := tx.Exec(ctx, "INSERT INTO organisation_members (organisation_id, user_id, role) VALUES ($1, $2, $3)", org.ID, user.UserID, models.OrgRoleFinance); err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "user already belongs to an organisation"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating organisation"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating organisation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organisation": org})
+}
+
+func (s *BookingService) HandleUpdateOrganisation(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleFinance)
+	if !ok {
+		return
+	}
+
+	var limits struct {
+		MonthlySpendLimit *money.Amount `json:"monthly_spend_limit"`
+		ApprovalThreshold *money.Amount `json:"approval_threshold"`
+		Currency          string        `json:"currency" binding:"omitempty,len=3"`
+	}
+	if err := c.ShouldBindJSON(&limits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// omitted limits and currency keep their current values
+	_, err := s.PostgreSQLConn.Exec(context.Background(),
+		"UPDATE organisations SET monthly_spend_limit = COALESCE($1, monthly_spend_limit), approval_threshold = COALESCE($2, approval_threshold), currency = COALESCE($3, currency), updated_at = NOW() WHERE id = $4",
+		limits.MonthlySpendLimit, limits.ApprovalThreshold, nullableString(limits.Currency), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating organisation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organisation updated"})
+}
+
+func (s *BookingService) HandleAddOrganisationMember(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleFinance)
+	if !ok {
+		return
+	}
+
+	var member models.OrganisationMember
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	member.OrganisationID = orgID
+
+	_, err := s.PostgreSQLConn.Exec(context.Background(),
+		"INSERT INTO organisation_members (organisation_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT (organisation_id, user_id) DO UPDATE SET role = EXCLUDED.role",
+		member.OrganisationID, member.UserID, member.Role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "user already belongs to another organisation"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error adding member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"member": member})
+}
+
+func (s *BookingService) HandleAddCostCentre(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleFinance)
+	if !ok {
+		return
+	}
+
+	var costCentre models.CostCentre
+	if err := c.ShouldBindJSON(&costCentre); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	costCentre.OrganisationID = orgID
+
+	err := s.PostgreSQLConn.QueryRow(context.Background(),
+		"INSERT INTO cost_centres (organisation_id, code, name) VALUES ($1, $2, $3) RETURNING id",
+		costCentre.OrganisationID, costCentre.Code, costCentre.Name).Scan(&costCentre.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "cost centre already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error adding cost centre"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cost_centre": costCentre})
+}
+
+func (s *BookingService) HandleListCostCentres(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleBooker, models.OrgRoleApprover, models.OrgRoleFinance)
+	if !ok {
+		return
+	}
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(), "SELECT id, organisation_id, code, name FROM cost_centres WHERE organisation_id=$1 ORDER BY code", orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching cost centres"})
+		return
+	}
+	defer rows.Close()
+
+	costCentres := []models.CostCentre{}
+	for rows.Next() {
+		var costCentre models.CostCentre
+		if err := rows.Scan(&costCentre.ID, &costCentre.OrganisationID, &costCentre.Code, &costCentre.Name); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching cost centres"})
+			return
+		}
+		costCentres = append(costCentres, costCentre)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cost_centres": costCentres})
+}
+
+func (s *BookingService) HandleListApprovals(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleApprover)
+	if !ok {
+		return
+	}
+
+	collection := s.mongoClient.Database("logistics").Collection("booking_approvals")
+	cursor, err := collection.Find(context.Background(), bson.M{"organisation_id": orgID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching approvals"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	approvals := []models.BookingRequest{}
+	for cursor.Next(context.Background()) {
+		var approval pendingApproval
+		if err := cursor.Decode(&approval); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading approvals"})
+			return
+		}
+		approval.BookingRequest.MongoID = approval.ID.Hex()
+		approvals = append(approvals, approval.BookingRequest)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"approvals": approvals})
+}
+
+func (s *BookingService) HandleApprovalDecision(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleApprover)
+	if !ok {
+		return
+	}
+
+	var decision struct {
+		Approved *bool `json:"approved" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid approval id"})
+		return
+	}
+
+	collection := s.mongoClient.Database("logistics").Collection("booking_approvals")
+	filter := bson.M{"_id": objectID, "organisation_id": orgID}
+	if !*decision.Approved {
+		var approval pendingApproval
+		err := collection.FindOneAndDelete(context.Background(), filter).Decode(&approval)
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "approval not found"})
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching approval"})
+			return
+		}
+
+		go s.ProduceBookingEvent(approval.BookingRequest.UserID, "", "", "rejected")
+		c.JSON(http.StatusOK, gin.H{"message": "Booking request rejected"})
+		return
+	}
+
+	var approval pendingApproval
+	err = collection.FindOne(context.Background(), filter).Decode(&approval)
+	if err == mongo.ErrNoDocuments {
+		c.JSON(http.StatusNotFound, gin.H{"error": "approval not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching approval"})
+		return
+	}
+
+	// the limit may have been used up while the request was waiting, in
+	// which case the approval is rejected
+	bookingReq := approval.BookingRequest
+	_, err = s.storeOrganisationRequest(context.Background(), orgID, &bookingReq, approval.ID)
+	if errors.Is(err, errSpendLimitExceeded) {
+		go s.ProduceBookingEvent(bookingReq.UserID, "", "", "rejected")
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	} else if errors.Is(err, errApprovalNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "approval not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error approving booking request"})
+		return
+	}
+
+	go s.ProcessBookingRequest(bookingReq)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Booking request approved"})
+}
+
+func (s *BookingService) HandleOrganisationStatement(c *gin.Context) {
+	orgID, ok := s.requireOrganisationRole(c, models.OrgRoleFinance)
+	if !ok {
+		return
+	}
+
+	month, err := time.Parse("2006-01", c.Param("month"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month must be formatted as YYYY-MM"})
+		return
+	}
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(),
+		"SELECT b.id, b.user_id, u.name, COALESCE(cc.code, ''), b.vehicle_type, b.pickup_name, b.dropoff_name, b.price, COALESCE(i.invoice_number, ''), b.status, b.created_at FROM booking b INNER JOIN users u ON u.id=b.user_id LEFT JOIN cost_centres cc ON cc.id=b.cost_centre_id LEFT JOIN invoices i ON i.booking_id=b.id AND i.original_invoice_id IS NULL WHERE b.organisation_id=$1 AND b.created_at >= $2 AND b.created_at < $3 AND b.status != $4 ORDER BY b.created_at",
+		orgID, month, month.AddDate(0, 1, 0), "cancelled")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching statement"})
+		return
+	}
+	defer rows.Close()
+
+	statement := models.OrganisationStatement{
+		OrganisationID:   orgID,
+		Month:            month.Format("2006-01"),
+		Lines:            []models.StatementLine{},
+		CostCentreTotals: make(map[string]money.Amount),
+	}
+	for rows.Next() {
+		var line models.StatementLine
+		if err := rows.Scan(&line.BookingID, &line.UserID, &line.UserName, &line.CostCentre, &line.VehicleType, &line.Pickup, &line.Dropoff, &line.Price, &line.InvoiceNumber, &line.Status, &line.BookedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading statement"})
+			return
+		}
+		statement.Lines = append(statement.Lines, line)
+		statement.CostCentreTotals[line.CostCentre] += line.Price
+		statement.Total += line.Price
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statement": statement})
+}
+
+// applyOrganisationPolicy attaches the requesting user's organisation and cost
+// centre to the booking request and enforces the organisation's spend limit.
+// A member's request is stored as it is admitted, either parked for an
+// approver when above the approval threshold, with a non-empty approval id
+// returned that the caller must not process, or as an open booking request
+// with bookingReq.MongoID set.
+func (s *BookingService) applyOrganisationPolicy(ctx context.Context, bookingReq *models.BookingRequest) (string, error) {
+	// never trust organisation or request ids sent by the client
+	bookingReq.OrganisationID = 0
+	bookingReq.CostCentreID = 0
+	bookingReq.MongoID = ""
+
+	orgID, _, err := s.organisationMembership(ctx, bookingReq.UserID)
+	if err == pgx.ErrNoRows {
+		if bookingReq.CostCentre != "" {
+			return "", errNoOrganisation
+		}
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("error fetching organisation: %w", err)
+	}
+	bookingReq.OrganisationID = orgID
+
+	if bookingReq.CostCentre != "" {
+		err := s.PostgreSQLConn.QueryRow(ctx, "SELECT id FROM cost_centres WHERE organisation_id=$1 AND code=$2", orgID, bookingReq.CostCentre).Scan(&bookingReq.CostCentreID)
+		if err == pgx.ErrNoRows {
+			return "", errUnknownCostCentre
+		} else if err != nil {
+			return "", fmt.Errorf("error fetching cost centre: %w", err)
+		}
+	}
+
+	return s.storeOrganisationRequest(ctx, orgID, bookingReq, primitive.NilObjectID)
+}
+
+// storeOrganisationRequest stores an organisation's booking request if it
+// fits under the spend limit. The organisation row stays locked until the
+// request is stored, so concurrent requests of its members cannot all fit
+// under the limit. A request above the approval threshold is stored for an
+// approver and its approval id returned; otherwise it is stored as an open
+// booking request. A non-zero approvalID is the approval being granted: the
+// request skips the threshold and its approval is removed as it is stored.
+func (s *BookingService) storeOrganisationRequest(ctx context.Context, orgID int32, bookingReq *models.BookingRequest, approvalID primitive.ObjectID) (string, error) {
+	// the transaction only holds the lock, so it is never committed
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var limit, approvalThreshold *money.Amount
+	var currency string
+	err = tx.QueryRow(ctx, "SELECT monthly_spend_limit, approval_threshold, COALESCE(currency, '') FROM organisations WHERE id=$1 FOR UPDATE", orgID).
+		Scan(&limit, &approvalThreshold, &currency)
+	if err != nil {
+		return "", fmt.Errorf("error fetching organisation: %w", err)
+	}
+
+	approvals := s.mongoClient.Database("logistics").Collection("booking_approvals")
+	if !approvalID.IsZero() {
+		// decided by another approver while we waited on the lock
+		res, err := approvals.DeleteOne(ctx, bson.M{"_id": approvalID, "organisation_id": orgID})
+		if err != nil {
+			return "", fmt.Errorf("error deleting booking approval: %w", err)
+		}
+		if res.DeletedCount == 0 {
+			return "", errApprovalNotFound
+		}
+	}
+
+	if err := s.checkSpendLimit(ctx, tx, orgID, limit, currency, bookingReq.Price, bookingReq.Currency); err != nil {
+		return "", err
+	}
+
+	// a price in another currency cannot be compared with the threshold, so
+	// it always goes to an approver
+	bookingReq.CreatedAt = time.Now()
+	if approvalID.IsZero() && approvalThreshold != nil && (differentCurrency(currency, bookingReq.Currency) || bookingReq.Price > *approvalThreshold) {
+		res, err := approvals.InsertOne(ctx, bookingReq)
+		if err != nil {
+			return "", fmt.Errorf("error storing booking approval: %w", err)
+		}
+		return res.InsertedID.(primitive.ObjectID).Hex(), nil
+	}
+
+	if err := s.storeBookingRequest(ctx, bookingReq); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// checkSpendLimit fails when the organisation's bookings this calendar month
+// and its requests still waiting for a driver or an approver, plus price,
+// would exceed its monthly spend limit. Only bookings and requests in the
+// limit's currency count towards it, and a price in another currency is
+// refused; those priced before currencies were recorded count as the
+// limit's. The caller holds the organisation row locked in tx.
+func (s *BookingService) checkSpendLimit(ctx context.Context, tx pgx.Tx, orgID int32, limit *money.Amount, limitCurrency string, price money.Amount, currency string) error {
+	if limit == nil {
+		return nil
+	}
+	if differentCurrency(limitCurrency, currency) {
+		return fmt.Errorf("%w: the limit is set in %s, not %s", errSpendLimitExceeded, limitCurrency, currency)
+	}
+
+	var spent money.Amount
+	err := tx.QueryRow(ctx,
+		"SELECT COALESCE(SUM(price), 0) FROM booking WHERE organisation_id=$1 AND status != 'cancelled' AND created_at >= date_trunc('month', NOW()) AND ($2 = '' OR COALESCE(currency, $2) = $2)",
+		orgID, limitCurrency).Scan(&spent)
+	if err != nil {
+		return fmt.Errorf("error fetching organisation spend: %w", err)
+	}
+
+	for _, name := range []string{"booking_requests", "booking_approvals"} {
+		outstanding, err := s.outstandingSpend(ctx, name, orgID, limitCurrency)
+		if err != nil {
+			return fmt.Errorf("error fetching outstanding %s: %w", name, err)
+		}
+		spent += outstanding
+	}
+
+	if spent+price > *limit {
+		return errSpendLimitExceeded
+	}
+	return nil
+}
+
+// outstandingSpend sums the prices of an organisation's requests in a Mongo
+// collection that count towards a limit in limitCurrency.
+func (s *BookingService) outstandingSpend(ctx context.Context, collectionName string, orgID int32, limitCurrency string) (money.Amount, error) {
+	collection := s.mongoClient.Database("logistics").Collection(collectionName)
+	cursor, err := collection.Find(ctx, bson.M{"organisation_id": orgID}, options.Find().SetProjection(bson.M{"price": 1, "currency": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var total money.Amount
+	for cursor.Next(ctx) {
+		var request struct {
+			Price    money.Amount `bson:"price"`
+			Currency string       `bson:"currency"`
+		}
+		if err := cursor.Decode(&request); err != nil {
+			return 0, err
+		}
+		if !differentCurrency(limitCurrency, request.Currency) {
+			total += request.Price
+		}
+	}
+
+	return total, cursor.Err()
+}
+
+// differentCurrency reports whether two currencies are both known and differ.
+func differentCurrency(a, b string) bool {
+	return a != "" && b != "" && !strings.EqualFold(a, b)
+}
+
+func (s *BookingService) organisationMembership(ctx context.Context, userID string) (int32, string, error) {
+	var orgID int32
+	var role string
+	err := s.PostgreSQLConn.QueryRow(ctx, "SELECT organisation_id, role FROM organisation_members WHERE user_id=$1", userID).Scan(&orgID, &role)
+	return orgID, role, err
+}
+
+// requireOrganisationRole resolves the caller's organisation and writes an
+// error response unless they hold one of roles. Finance members may act as
+// approvers.
+func (s *BookingService) requireOrganisationRole(c *gin.Context, roles ...string) (int32, bool) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return 0, false
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	orgID, role, err := s.organisationMembership(context.Background(), user.UserID)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": errNoOrganisation.Error()})
+		return 0, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching organisation"})
+		return 0, false
+	}
+
+	for _, allowed := range roles {
+		if role == allowed || (allowed == models.OrgRoleApprover && role == models.OrgRoleFinance) {
+			return orgID, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "organisation role not permitted"})
+	return 0, false
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// nullableID stores zero ids as NULL.
+func nullableID(id int32) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableString stores empty strings as NULL.
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,500 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/payment"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultPaymentCurrency = "USD"
+
+// paymentSignatureHeader carries the provider's signature on webhooks.
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// expiredAuthorizationAfter is how old an authorization of a request no
+// driver accepted must be before it is voided; well past the 10 minute TTL on
+// booking_requests, so a request being accepted is never taken for expired.
+const expiredAuthorizationAfter = time.Hour
+
+const paymentColumns = `id, COALESCE(mongo_id, ''), COALESCE(booking_id, 0), user_id, provider, COALESCE(authorization_id, ''), amount, captured_amount,
+	refunded_amount, currency, status, COALESCE(failure_reason, ''), created_at, updated_at`
+
+// authorizePayment reserves what a booking request's invoice will total, the
+// fare with the platform fee and any tax added on top, on the user's card
+// before any driver is offered it. Organisation bookings are billed on the
+// monthly statement and are not authorized.
+func (s *BookingService) authorizePayment(ctx context.Context, bookingReq models.BookingRequest) error {
+	if bookingReq.OrganisationID != 0 || bookingReq.Price <= 0 {
+		return nil
+	}
+
+	// requests are priced in their region's currency; PAYMENT_CURRENCY only
+	// covers ones priced before currencies were recorded
+	currency := bookingReq.Currency
+	if currency == "" {
+		currency = viper.GetString("PAYMENT_CURRENCY")
+	}
+	if currency == "" {
+		currency = defaultPaymentCurrency
+	}
+	amount := payableTotal(bookingReq.Price, currency, requestTaxRule(bookingReq))
+
+	status, failureReason := models.PaymentAuthorized, ""
+	result, err := s.paymentGateway.Authorize(ctx, payment.AuthorizeRequest{
+		IdempotencyKey: bookingReq.MongoID,
+		CustomerID:     bookingReq.UserID,
+		Amount:         amount,
+		Currency:       currency,
+	})
+	if errors.Is(err, payment.ErrDeclined) {
+		status, failureReason = models.PaymentFailed, err.Error()
+	} else if err != nil {
+		return fmt.Errorf("error authorizing payment: %w", err)
+	}
+
+	_, dbErr := s.PostgreSQLConn.Exec(ctx, `
+		INSERT INTO payments (mongo_id, user_id, provider, authorization_id, amount, currency, status, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (mongo_id) DO NOTHING`,
+		bookingReq.MongoID, bookingReq.UserID, s.paymentGateway.Name(), nullableString(result.AuthorizationID), amount, currency,
+		status, nullableString(failureReason))
+	if dbErr != nil {
+		if status == models.PaymentAuthorized {
+			s.voidAuthorization(ctx, result.AuthorizationID)
+		}
+		return fmt.Errorf("error storing payment: %w", dbErr)
+	}
+
+	return err
+}
+
+// voidAuthorization releases an authorization that could not be recorded,
+// so the user's card is not left holding the fare.
+func (s *BookingService) voidAuthorization(ctx context.Context, authorizationID string) {
+	if _, err := s.paymentGateway.Void(ctx, authorizationID); err != nil {
+		log.Printf("Error voiding unrecorded authorization %s: %v", authorizationID, err)
+	}
+}
+
+// linkPayment attaches a request's payment to the booking made from it and
+// copies its status onto the booking.
+func (s *BookingService) linkPayment(ctx context.Context, bookingReq models.BookingRequest, bookingID int32) {
+	status := models.PaymentOnAccount
+	if bookingReq.OrganisationID == 0 {
+		err := s.PostgreSQLConn.QueryRow(ctx, "UPDATE payments SET booking_id = $1, updated_at = NOW() WHERE mongo_id = $2 RETURNING status",
+			bookingID, bookingReq.MongoID).Scan(&status)
+		if err == pgx.ErrNoRows {
+			return
+		} else if err != nil {
+			log.Printf("Error linking payment for booking %d: %v", bookingID, err)
+			return
+		}
+	}
+
+	if _, err := s.PostgreSQLConn.Exec(ctx, "UPDATE booking SET payment_status = $1 WHERE id = $2", status, bookingID); err != nil {
+		log.Printf("Error storing payment status for booking %d: %v", bookingID, err)
+	}
+}
+
+// pendingPayment is an authorized payment to capture, for amount, or to void.
+type pendingPayment struct {
+	id, bookingID, userID int32
+	authorizationID       string
+	amount                money.Amount
+	currency              string
+	capture               bool
+}
+
+// settlePayments captures the authorized payments of completed bookings once
+// they are invoiced, for what the invoice bills up to the amount authorized,
+// and voids those of cancelled ones. Charges for invoices the authorization
+// did not cover are captured the same way. Empty ids cover every booking,
+// which is how failed attempts are retried, and also void the authorizations
+// of requests that expired without a driver and charge invoices missed
+// earlier.
+func (s *BookingService) settlePayments(ctx context.Context, userID, driverID string) error {
+	query := `SELECT p.id, p.authorization_id, p.amount, p.currency, p.invoice_id IS NOT NULL, COALESCE(i.total, 0), b.id, b.user_id, b.status
+		FROM payments p
+		INNER JOIN booking b ON b.id = p.booking_id
+		LEFT JOIN invoices i ON i.booking_id = b.id AND i.original_invoice_id IS NULL
+		WHERE p.status = 'authorized' AND (b.status = 'cancelled' OR (b.status = 'completed' AND i.id IS NOT NULL))`
+	var args []interface{}
+	if userID != "" && driverID != "" {
+		query += " AND b.user_id = $1 AND b.driver_id = $2"
+		args = append(args, userID, driverID)
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error fetching payments to settle: %w", err)
+	}
+
+	var pending []pendingPayment
+	for rows.Next() {
+		var p pendingPayment
+		var invoiceCharge bool
+		var invoiceTotal money.Amount
+		var bookingStatus string
+		if err := rows.Scan(&p.id, &p.authorizationID, &p.amount, &p.currency, &invoiceCharge, &invoiceTotal, &p.bookingID, &p.userID, &bookingStatus); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading payment to settle: %w", err)
+		}
+		// a charge is captured in full; the booking's own payment for what
+		// its invoice bills, the rest of the hold being released
+		if bookingStatus == "completed" && !invoiceCharge && invoiceTotal < p.amount {
+			p.amount = invoiceTotal
+		}
+		p.amount = p.amount.Round(p.currency)
+		p.capture = bookingStatus == "completed" && p.amount > 0
+		pending = append(pending, p)
+	}
+	rows.Close()
+
+	for _, p := range pending {
+		if err := s.settlePayment(ctx, p); err != nil {
+			log.Printf("Error settling payment %d: %v", p.id, err)
+			continue
+		}
+		if p.capture && userID != "" {
+			if err := s.chargeInvoices(ctx, p.bookingID); err != nil {
+				log.Printf("Error charging invoices of booking %d: %v", p.bookingID, err)
+			}
+		}
+	}
+
+	if userID == "" {
+		if err := s.voidExpiredAuthorizations(ctx); err != nil {
+			return err
+		}
+		return s.chargeInvoices(ctx, 0)
+	}
+	return nil
+}
+
+// settlePayment captures or voids a payment and records the outcome. A
+// declined capture marks the payment failed and tells the user; an error
+// reaching the provider leaves it authorized to be retried.
+func (s *BookingService) settlePayment(ctx context.Context, p pendingPayment) error {
+	var status, failureReason string
+	var captured money.Amount
+	var err error
+	if p.capture {
+		status, captured = models.PaymentCaptured, p.amount
+		_, err = s.paymentGateway.Capture(ctx, p.authorizationID, p.amount)
+	} else {
+		status = models.PaymentVoided
+		_, err = s.paymentGateway.Void(ctx, p.authorizationID)
+	}
+
+	if errors.Is(err, payment.ErrDeclined) {
+		status, captured, failureReason = models.PaymentFailed, 0, err.Error()
+	} else if err != nil {
+		return err
+	}
+
+	if err := s.storePaymentUpdate(ctx, p.id, status, captured, failureReason); err != nil {
+		return fmt.Errorf("error storing settled payment: %w", err)
+	}
+
+	if status == models.PaymentFailed && p.bookingID != 0 {
+		go s.writeBookingEvent(models.BookedNotification{
+			UserID:    strconv.Itoa(int(p.userID)),
+			Status:    "payment_failed",
+			BookingID: p.bookingID,
+		})
+	}
+	return nil
+}
+
+// chargeInvoices charges the user's card for what invoices bill beyond the
+// booking's captured authorization: every supplementary invoice in full, and
+// the part of the booking's own invoice the authorization did not cover.
+// Only bookings paid by card are charged, each invoice at most once. A zero
+// bookingID covers every booking.
+func (s *BookingService) chargeInvoices(ctx context.Context, bookingID int32) error {
+	query := `SELECT i.id, i.invoice_number, i.booking_id, i.user_id, p.currency, c.amount FROM invoices i
+		INNER JOIN payments p ON p.booking_id = i.booking_id AND p.invoice_id IS NULL
+		LEFT JOIN payments existing ON existing.invoice_id = i.id
+		CROSS JOIN LATERAL (SELECT CASE WHEN i.original_invoice_id IS NULL THEN i.total - p.captured_amount ELSE i.total END AS amount) c
+		WHERE existing.id IS NULL AND p.status IN ('captured', 'partially_refunded', 'refunded') AND c.amount > 0`
+	var args []interface{}
+	if bookingID != 0 {
+		query += " AND i.booking_id = $1"
+		args = append(args, bookingID)
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error fetching invoices to charge: %w", err)
+	}
+
+	type invoiceCharge struct {
+		invoiceID, bookingID, userID int32
+		number, currency             string
+		amount                       money.Amount
+	}
+	var charges []invoiceCharge
+	for rows.Next() {
+		var charge invoiceCharge
+		if err := rows.Scan(&charge.invoiceID, &charge.number, &charge.bookingID, &charge.userID, &charge.currency, &charge.amount); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading invoice to charge: %w", err)
+		}
+		charges = append(charges, charge)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading invoices to charge: %w", err)
+	}
+
+	for _, charge := range charges {
+		amount := charge.amount.Round(charge.currency)
+		result, err := s.paymentGateway.Authorize(ctx, payment.AuthorizeRequest{
+			IdempotencyKey: charge.number,
+			CustomerID:     strconv.Itoa(int(charge.userID)),
+			Amount:         amount,
+			Currency:       charge.currency,
+		})
+		status, failureReason := models.PaymentAuthorized, ""
+		if errors.Is(err, payment.ErrDeclined) {
+			status, failureReason = models.PaymentFailed, err.Error()
+		} else if err != nil {
+			log.Printf("Error authorizing charge for invoice %s: %v", charge.number, err)
+			continue
+		}
+
+		var paymentID int32
+		err = s.PostgreSQLConn.QueryRow(ctx, `
+			INSERT INTO payments (booking_id, invoice_id, user_id, provider, authorization_id, amount, currency, status, failure_reason)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (invoice_id) DO NOTHING RETURNING id`,
+			charge.bookingID, charge.invoiceID, charge.userID, s.paymentGateway.Name(), nullableString(result.AuthorizationID), amount,
+			charge.currency, status, nullableString(failureReason)).Scan(&paymentID)
+		if err == pgx.ErrNoRows {
+			// another instance charged the invoice
+			continue
+		} else if err != nil {
+			if status == models.PaymentAuthorized {
+				s.voidAuthorization(ctx, result.AuthorizationID)
+			}
+			log.Printf("Error storing charge for invoice %s: %v", charge.number, err)
+			continue
+		}
+
+		if status == models.PaymentFailed {
+			go s.writeBookingEvent(models.BookedNotification{
+				UserID:    strconv.Itoa(int(charge.userID)),
+				Status:    "payment_failed",
+				BookingID: charge.bookingID,
+			})
+			continue
+		}
+
+		err = s.settlePayment(ctx, pendingPayment{
+			id:              paymentID,
+			bookingID:       charge.bookingID,
+			userID:          charge.userID,
+			authorizationID: result.AuthorizationID,
+			amount:          amount,
+			currency:        charge.currency,
+			capture:         true,
+		})
+		if err != nil {
+			log.Printf("Error capturing charge for invoice %s: %v", charge.number, err)
+		}
+	}
+
+	return nil
+}
+
+// voidExpiredAuthorizations releases the authorizations of booking requests
+// that expired without a driver accepting them, so the user's card is not
+// left holding the fare until the provider lets the hold lapse.
+func (s *BookingService) voidExpiredAuthorizations(ctx context.Context) error {
+	rows, err := s.PostgreSQLConn.Query(ctx, `
+		SELECT id, mongo_id, authorization_id, user_id FROM payments
+		WHERE status = 'authorized' AND booking_id IS NULL AND invoice_id IS NULL AND created_at < NOW() - $1::INTERVAL`,
+		expiredAuthorizationAfter.String())
+	if err != nil {
+		return fmt.Errorf("error fetching unaccepted authorizations: %w", err)
+	}
+
+	type unaccepted struct {
+		pendingPayment
+		mongoID string
+	}
+	var candidates []unaccepted
+	for rows.Next() {
+		var candidate unaccepted
+		if err := rows.Scan(&candidate.id, &candidate.mongoID, &candidate.authorizationID, &candidate.userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("error reading unaccepted authorization: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading unaccepted authorizations: %w", err)
+	}
+
+	collection := s.mongoClient.Database("logistics").Collection("booking_requests")
+	for _, candidate := range candidates {
+		objectID, err := primitive.ObjectIDFromHex(candidate.mongoID)
+		if err != nil {
+			continue
+		}
+		// requests still waiting for a driver keep their hold
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": objectID})
+		if err != nil {
+			log.Printf("Error checking booking request %s: %v", candidate.mongoID, err)
+			continue
+		} else if count > 0 {
+			continue
+		}
+
+		if err := s.settlePayment(ctx, candidate.pendingPayment); err != nil {
+			log.Printf("Error voiding authorization of expired request %s: %v", candidate.mongoID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *BookingService) storePaymentUpdate(ctx context.Context, paymentID int32, status string, captured money.Amount, failureReason string) error {
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updatePayment(ctx, tx, paymentID, status, captured, -1, failureReason); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// updatePayment stores a payment's new status and mirrors it on the booking,
+// unless it is a charge for an invoice. A negative refunded amount leaves the
+// refunded total unchanged.
+func updatePayment(ctx context.Context, tx pgx.Tx, paymentID int32, status string, captured, refunded money.Amount, failureReason string) error {
+	var bookingID int32
+	err := tx.QueryRow(ctx, `
+		UPDATE payments SET status = $1, captured_amount = $2,
+			refunded_amount = CASE WHEN $3::NUMERIC < 0 THEN refunded_amount ELSE $3::NUMERIC END,
+			failure_reason = $4, updated_at = NOW()
+		WHERE id = $5 RETURNING CASE WHEN invoice_id IS NULL THEN COALESCE(booking_id, 0) ELSE 0 END`,
+		status, captured, refunded, nullableString(failureReason), paymentID).Scan(&bookingID)
+	if err != nil {
+		return err
+	}
+
+	if bookingID != 0 {
+		if _, err := tx.Exec(ctx, "UPDATE booking SET payment_status = $1 WHERE id = $2", status, bookingID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandlePaymentWebhook applies the provider's callbacks about payments. Each
+// event is applied once; the provider retrying a delivered event gets a 200.
+func (s *BookingService) HandlePaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "error reading webhook"})
+		return
+	}
+
+	event, err := s.paymentGateway.VerifyWebhook(payload, c.GetHeader(paymentSignatureHeader))
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	} else if err != nil || event.ID == "" || event.AuthorizationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook"})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error handling webhook"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	pgComm, err := tx.Exec(ctx, "INSERT INTO payment_events (id, authorization_id, type) VALUES ($1, $2, $3) ON CONFLICT (id) DO NOTHING",
+		event.ID, event.AuthorizationID, event.Type)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error handling webhook"})
+		return
+	}
+	if pgComm.RowsAffected() == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Event already handled"})
+		return
+	}
+
+	record, err := scanPayment(tx.QueryRow(ctx, "SELECT "+paymentColumns+" FROM payments WHERE authorization_id = $1 FOR UPDATE", event.AuthorizationID))
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "payment not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error handling webhook"})
+		return
+	}
+
+	refunded := money.Amount(-1)
+	switch event.Type {
+	case payment.EventCaptured:
+		record.Status, record.CapturedAmount = models.PaymentCaptured, event.Amount
+	case payment.EventFailed:
+		record.Status, record.FailureReason = models.PaymentFailed, event.Reason
+	case payment.EventVoided:
+		record.Status = models.PaymentVoided
+	case payment.EventRefunded:
+		refunded = event.Amount
+		record.Status = models.PaymentPartiallyRefunded
+		if event.Amount >= record.CapturedAmount {
+			record.Status = models.PaymentRefunded
+		}
+	case payment.EventExpired:
+		// a captured payment no longer depends on its authorization
+		if record.Status == models.PaymentAuthorized {
+			record.Status = models.PaymentExpired
+		}
+	default:
+		log.Printf("Ignoring payment webhook %s of type %s", event.ID, event.Type)
+	}
+
+	if err := updatePayment(ctx, tx, record.ID, record.Status, record.CapturedAmount, refunded, record.FailureReason); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error handling webhook"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error handling webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event handled", "status": record.Status})
+}
+
+func scanPayment(row pgx.Row) (models.Payment, error) {
+	var record models.Payment
+	err := row.Scan(&record.ID, &record.MongoID, &record.BookingID, &record.UserID, &record.Provider, &record.AuthorizationID, &record.Amount,
+		&record.CapturedAmount, &record.RefundedAmount, &record.Currency, &record.Status, &record.FailureReason, &record.CreatedAt, &record.UpdatedAt)
+	return record, err
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// sharedPoolWindow is how long a pool collects compatible requests before
+	// it is offered to drivers
+	sharedPoolWindow = 5 * time.Minute
+	// sharedCorridorRadius is how far apart, in km, the pickups and the
+	// dropoffs of two requests may be for them to share a vehicle
+	sharedCorridorRadius = 5.0
+)
+
+// vehicleCapacity is the usable cargo volume in cubic metres of each vehicle
+// type, used to decide whether pooled shipments fit together.
+var vehicleCapacity = map[string]float64{
+	"light_truck": 8,
+	"van":         6,
+	"truck":       20,
+	"heavy_truck": 40,
+	"trailer":     80,
+}
+
+type sharedPoolDocument struct {
+	ID                primitive.ObjectID `bson:"_id"`
+	models.SharedPool `bson:",inline"`
+}
+
+// poolSharedRequest adds a booking request that allows sharing to an open
+// pool on the same corridor, or starts a new pool for it. Requests that do not
+// fit any vehicle of their type are dispatched on their own.
+func (s *BookingService) poolSharedRequest(bookingReq models.BookingRequest) error {
+	capacity, ok := vehicleCapacity[bookingReq.VehicleType]
+	if !ok || bookingReq.Cargo.Volume > capacity {
+		return s.FindAndNotifyNearbyDrivers(bookingReq, bookingReq.VehicleType)
+	}
+
+	ctx := context.Background()
+	pools := s.mongoClient.Database("logistics").Collection("shared_pools")
+	requests := s.mongoClient.Database("logistics").Collection("booking_requests")
+
+	cursor, err := pools.Find(ctx, bson.M{
+		"status":       "open",
+		"vehicle_type": bookingReq.VehicleType,
+		"dispatch_at":  bson.M{"$gt": time.Now()},
+		"total_volume": bson.M{"$lte": capacity - bookingReq.Cargo.Volume},
+	})
+	if err != nil {
+		return fmt.Errorf("error finding shared pools: %w", err)
+	}
+
+	var candidates []sharedPoolDocument
+	if err := cursor.All(ctx, &candidates); err != nil {
+		return fmt.Errorf("error reading shared pools: %w", err)
+	}
+
+	for _, pool := range candidates {
+		anchor := pool.Members[0]
+		if geo.Distance(anchor.Pickup, bookingReq.Pickup) > sharedCorridorRadius ||
+			geo.Distance(anchor.Dropoff, bookingReq.Dropoff) > sharedCorridorRadius {
+			continue
+		}
+
+		bookingReq.SharedPoolID = pool.ID.Hex()
+		// the volume check is repeated in the filter so concurrent joins cannot overfill the vehicle
+		res, err := pools.UpdateOne(ctx,
+			bson.M{"_id": pool.ID, "status": "open", "total_volume": bson.M{"$lte": capacity - bookingReq.Cargo.Volume}},
+			bson.M{"$push": bson.M{"members": bookingReq}, "$inc": bson.M{"total_volume": bookingReq.Cargo.Volume}})
+		if err != nil {
+			return fmt.Errorf("error joining shared pool: %w", err)
+		}
+		if res.ModifiedCount == 1 {
+			return s.markPooled(ctx, requests, bookingReq)
+		}
+	}
+
+	now := time.Now()
+	pool := sharedPoolDocument{ID: primitive.NewObjectID()}
+	bookingReq.SharedPoolID = pool.ID.Hex()
+	pool.SharedPool = models.SharedPool{
+		VehicleType: bookingReq.VehicleType,
+		Status:      "open",
+		Members:     []models.BookingRequest{bookingReq},
+		TotalVolume: bookingReq.Cargo.Volume,
+		CreatedAt:   now,
+		DispatchAt:  now.Add(sharedPoolWindow),
+	}
+	if _, err := pools.InsertOne(ctx, pool); err != nil {
+		return fmt.Errorf("error creating shared pool: %w", err)
+	}
+
+	time.AfterFunc(sharedPoolWindow, func() { s.dispatchSharedPool(pool.ID) })
+
+	return s.markPooled(ctx, requests, bookingReq)
+}
+
+func (s *BookingService) markPooled(ctx context.Context, requests *mongo.Collection, bookingReq models.BookingRequest) error {
+	objectID, err := primitive.ObjectIDFromHex(bookingReq.MongoID)
+	if err != nil {
+		return fmt.Errorf("invalid booking request id: %w", err)
+	}
+
+	_, err = requests.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"shared_pool_id": bookingReq.SharedPoolID}})
+	return err
+}
+
+// dispatchSharedPool closes a pool and offers it to nearby drivers as one trip
+// with a stop for every member's pickup and dropoff, each member discounted
+// for sharing. A pool nobody joined is dispatched as the single request it
+// holds, at its full price.
+func (s *BookingService) dispatchSharedPool(poolID primitive.ObjectID) {
+	ctx := context.Background()
+	pools := s.mongoClient.Database("logistics").Collection("shared_pools")
+
+	var pool sharedPoolDocument
+	err := pools.FindOneAndUpdate(ctx,
+		bson.M{"_id": poolID, "status": "open"},
+		bson.M{"$set": bson.M{"status": "dispatched"}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&pool)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Error dispatching shared pool %s: %v", poolID.Hex(), err)
+		}
+		return
+	}
+
+	if len(pool.Members) == 1 {
+		if _, err := pools.DeleteOne(ctx, bson.M{"_id": poolID}); err != nil {
+			log.Printf("Error removing shared pool %s: %v", poolID.Hex(), err)
+		}
+		// a request travelling alone is booked, and charged, as unshared
+		bookingReq := pool.Members[0]
+		bookingReq.SharedPoolID = ""
+		if objectID, err := primitive.ObjectIDFromHex(bookingReq.MongoID); err == nil {
+			requests := s.mongoClient.Database("logistics").Collection("booking_requests")
+			if _, err := requests.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$unset": bson.M{"shared_pool_id": ""}}); err != nil {
+				log.Printf("Error unpooling booking request %s: %v", bookingReq.MongoID, err)
+			}
+		}
+		if err := s.FindAndNotifyNearbyDrivers(bookingReq, bookingReq.VehicleType); err != nil {
+			log.Printf("Error dispatching booking request %s: %v", bookingReq.MongoID, err)
+		}
+		return
+	}
+
+	// members sharing the vehicle get the shared load discount they were
+	// quoted; the pool takes no more members, so they are safe to rewrite
+	for i := range pool.Members {
+		pool.Members[i].Price -= pool.Members[i].SharedDiscount
+	}
+	if _, err := pools.UpdateOne(ctx, bson.M{"_id": poolID}, bson.M{"$set": bson.M{"members": pool.Members}}); err != nil {
+		log.Printf("Error discounting shared pool %s: %v", poolID.Hex(), err)
+		return
+	}
+
+	stops := buildStops(pool.Members)
+	offer := models.BookingRequest{
+		UserName:     fmt.Sprintf("%d shippers", len(pool.Members)),
+		Pickup:       stops[0].Location,
+		Dropoff:      stops[len(stops)-1].Location,
+		VehicleType:  pool.VehicleType,
+		MongoID:      poolID.Hex(),
+		CreatedAt:    time.Now(),
+		AllowShared:  true,
+		Cargo:        models.Cargo{Volume: pool.TotalVolume},
+		SharedPoolID: poolID.Hex(),
+		Stops:        stops,
+	}
+	// members are pooled from nearby pickups, so share a pricing region
+	for _, member := range pool.Members {
+		offer.Price += member.Price
+		offer.Currency = member.Currency
+	}
+
+	if err := s.FindAndNotifyNearbyDrivers(offer, offer.VehicleType); err != nil {
+		log.Printf("Error dispatching shared pool %s: %v", poolID.Hex(), err)
+	}
+}
+
+// dispatchDueSharedPools dispatches the open pools whose window has closed
+// but were not dispatched when it did, e.g. because the instance that
+// created them restarted.
+func (s *BookingService) dispatchDueSharedPools(ctx context.Context) error {
+	pools := s.mongoClient.Database("logistics").Collection("shared_pools")
+	cursor, err := pools.Find(ctx, bson.M{"status": "open", "dispatch_at": bson.M{"$lte": time.Now()}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+
+	var due []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &due); err != nil {
+		return err
+	}
+
+	// dispatchSharedPool claims each pool, so instances sweeping at the same
+	// time dispatch it once
+	for _, pool := range due {
+		s.dispatchSharedPool(pool.ID)
+	}
+	return nil
+}
+
+// acceptSharedPool assigns every member of a dispatched pool to the accepting
+// driver. Each member gets their own booking, so every shipper only tracks and
+// completes their own segment of the trip.
+func (s *BookingService) acceptSharedPool(poolID primitive.ObjectID, driver models.UserRequest) ([]string, []models.Stop, error) {
+	ctx := context.Background()
+	pools := s.mongoClient.Database("logistics").Collection("shared_pools")
+	requests := s.mongoClient.Database("logistics").Collection("booking_requests")
+
+	var pool sharedPoolDocument
+	err := pools.FindOneAndUpdate(ctx,
+		bson.M{"_id": poolID, "status": "dispatched"},
+		bson.M{"$set": bson.M{"status": "accepted"}}).Decode(&pool)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil, errOfferNotFound
+	} else if err != nil {
+		return nil, nil, errors.New("error accepting shared booking")
+	}
+
+	var userIDs []string
+	var booked []models.BookingRequest
+	for _, member := range pool.Members {
+		if objectID, err := primitive.ObjectIDFromHex(member.MongoID); err == nil {
+			if _, err := requests.DeleteOne(ctx, bson.M{"_id": objectID}); err != nil {
+				log.Printf("Error deleting booking request %s: %v", member.MongoID, err)
+			}
+		}
+
+		err := s.ProcessBooked(models.BookingConfirmation{BookingReq: member, DriverID: driver.UserID, DriverName: driver.UserName})
+		if isPromotionError(err) {
+			// the member's promotion ran out; the rest of the pool still goes
+			log.Printf("Dropping pool member %s: %v", member.MongoID, err)
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		userIDs = append(userIDs, member.UserID)
+		booked = append(booked, member)
+	}
+	if len(booked) == 0 {
+		return nil, nil, errors.New("error accepting shared booking")
+	}
+
+	return userIDs, buildStops(booked), nil
+}
+
+// buildStops orders a pool's stops as all pickups, nearest first from the
+// first member's pickup, followed by all dropoffs, nearest first from the
+// last pickup.
+func buildStops(members []models.BookingRequest) []models.Stop {
+	var pickups, dropoffs []models.Stop
+	for _, member := range members {
+		pickups = append(pickups, models.Stop{Type: "pickup", UserID: member.UserID, UserName: member.UserName, Location: member.Pickup})
+		dropoffs = append(dropoffs, models.Stop{Type: "dropoff", UserID: member.UserID, UserName: member.UserName, Location: member.Dropoff})
+	}
+
+	origin := members[0].Pickup
+	sort.SliceStable(pickups, func(i, j int) bool {
+		return geo.Distance(origin, pickups[i].Location) < geo.Distance(origin, pickups[j].Location)
+	})
+
+	lastPickup := pickups[len(pickups)-1].Location
+	sort.SliceStable(dropoffs, func(i, j int) bool {
+		return geo.Distance(lastPickup, dropoffs[i].Location) < geo.Distance(lastPickup, dropoffs[j].Location)
+	})
+
+	return append(pickups, dropoffs...)
+}
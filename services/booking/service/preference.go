@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"logistics-platform/lib/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+func (s *BookingService) HandleListDriverPreferences(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(), `
+		SELECT dp.user_id, dp.driver_id, vd.name, dp.preference, dp.created_at
+		FROM driver_preferences dp
+		INNER JOIN vehicle_drivers vd ON vd.id = dp.driver_id
+		WHERE dp.user_id = $1
+		ORDER BY dp.created_at DESC`, user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching driver preferences"})
+		return
+	}
+	defer rows.Close()
+
+	favourites := []models.DriverPreference{}
+	blocked := []models.DriverPreference{}
+	for rows.Next() {
+		var preference models.DriverPreference
+		if err := rows.Scan(&preference.UserID, &preference.DriverID, &preference.DriverName, &preference.Preference, &preference.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading driver preferences"})
+			return
+		}
+
+		if preference.Preference == models.DriverPreferenceBlocked {
+			blocked = append(blocked, preference)
+		} else {
+			favourites = append(favourites, preference)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"favourites": favourites, "blocked": blocked})
+}
+
+// HandleSetDriverPreference marks a driver as a favourite or blocks them. A
+// driver is either one or the other, so setting a preference replaces any
+// earlier one.
+func (s *BookingService) HandleSetDriverPreference(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var preference models.DriverPreference
+	if err := c.ShouldBindJSON(&preference); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	err := s.PostgreSQLConn.QueryRow(ctx, "SELECT id, name FROM vehicle_drivers WHERE id = $1", c.Param("driverId")).
+		Scan(&preference.DriverID, &preference.DriverName)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "driver not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching driver"})
+		return
+	}
+
+	err = s.PostgreSQLConn.QueryRow(ctx, `
+		INSERT INTO driver_preferences (user_id, driver_id, preference) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, driver_id) DO UPDATE SET preference = EXCLUDED.preference, created_at = CURRENT_TIMESTAMP
+		RETURNING user_id, created_at`,
+		user.UserID, preference.DriverID, preference.Preference).Scan(&preference.UserID, &preference.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing driver preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preference": preference})
+}
+
+func (s *BookingService) HandleRemoveDriverPreference(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	pgComm, err := s.PostgreSQLConn.Exec(context.Background(), "DELETE FROM driver_preferences WHERE user_id = $1 AND driver_id = $2", user.UserID, c.Param("driverId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error removing driver preference"})
+		return
+	}
+	if pgComm.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "driver preference not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Driver preference removed"})
+}
+
+// driverPreferences loads the favourite and blocked drivers of the shippers on
+// a request. A pooled offer carries several shippers: a driver blocked by any
+// of them is blocked for the whole trip, and favourites only apply when there
+// is a single shipper.
+func (s *BookingService) driverPreferences(ctx context.Context, bookingReq models.BookingRequest) (favourites, blocked map[string]bool, err error) {
+	favourites = make(map[string]bool)
+	blocked = make(map[string]bool)
+
+	var userIDs []string
+	if bookingReq.UserID != "" {
+		userIDs = append(userIDs, bookingReq.UserID)
+	}
+	for _, stop := range bookingReq.Stops {
+		if stop.UserID != "" && !containsString(userIDs, stop.UserID) {
+			userIDs = append(userIDs, stop.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return favourites, blocked, nil
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, "SELECT driver_id::TEXT, preference FROM driver_preferences WHERE user_id = ANY($1::INTEGER[])", userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var driverID, preference string
+		if err := rows.Scan(&driverID, &preference); err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case preference == models.DriverPreferenceBlocked:
+			blocked[driverID] = true
+		case len(userIDs) == 1:
+			favourites[driverID] = true
+		}
+	}
+
+	return favourites, blocked, rows.Err()
+}
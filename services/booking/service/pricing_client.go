@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logistics-platform/lib/config"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/pricingrpc"
+	"logistics-platform/lib/token"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// pricingCallTimeout bounds each call to the pricing service, retries
+// included, as every call is made while a user waits.
+const pricingCallTimeout = 5 * time.Second
+
+// pricingServiceConfig retries calls the pricing service was unavailable for,
+// such as while it restarts. Every call is a quote or a read, so retrying one
+// is safe.
+const pricingServiceConfig = `{
+	"methodConfig": [{
+		"name": [{"service": "pricing.v1.PricingService"}],
+		"retryPolicy": {
+			"maxAttempts": 3,
+			"initialBackoff": "0.1s",
+			"maxBackoff": "1s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+var (
+	pricingClientOnce sync.Once
+	pricingClient     pricingrpc.PricingServiceClient
+	pricingClientErr  error
+)
+
+// pricingService returns the client of the pricing service's gRPC server at
+// PRICING_GRPC_ADDRESS. The connection is made on first use and shared.
+func pricingService() (pricingrpc.PricingServiceClient, error) {
+	pricingClientOnce.Do(func() {
+		conn, err := grpc.NewClient(config.GetPricingGRPCAddress(),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultServiceConfig(pricingServiceConfig))
+		if err != nil {
+			pricingClientErr = fmt.Errorf("failed to create pricing client: %w", err)
+			return
+		}
+		pricingClient = pricingrpc.NewPricingServiceClient(conn)
+	})
+	return pricingClient, pricingClientErr
+}
+
+var errUnsupportedVehicleType = errors.New("unsupported vehicle type")
+
+// fetchVehiclePricing returns the rate card of a vehicle type in effect at,
+// or now when at is zero, in a pricing region or by default when regionID is
+// zero. A non-zero experimentID applies that experiment variant's rates.
+func fetchVehiclePricing(vehicleType string, regionID int32, at time.Time, experimentID int32, variant string) (models.VehiclePricing, error) {
+	client, err := pricingService()
+	if err != nil {
+		return models.VehiclePricing{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pricingCallTimeout)
+	defer cancel()
+
+	vehiclePricing, err := client.GetVehiclePricing(ctx, &pricingrpc.GetVehiclePricingRequest{
+		VehicleType:  vehicleType,
+		At:           pricingrpc.FromTime(at),
+		RegionId:     regionID,
+		ExperimentId: experimentID,
+		Variant:      variant,
+	})
+	if status.Code(err) == codes.NotFound {
+		return models.VehiclePricing{}, fmt.Errorf("%w %q", errUnsupportedVehicleType, vehicleType)
+	} else if err != nil {
+		return models.VehiclePricing{}, fmt.Errorf("pricing service: %w", err)
+	}
+	return pricingrpc.ToVehiclePricing(vehiclePricing), nil
+}
+
+// priceQuote is the pricing service's estimate, with the promo code
+// discount already taken off Price, the region, tax rule and experiment
+// variant it was priced under, the trip and surge it was priced for and the
+// shared load discount it gets if pooled.
+type priceQuote struct {
+	Price             money.Amount
+	Currency          string
+	Discount          *models.PromotionDiscount
+	PromoError        string
+	RegionID          int32
+	TaxName           string
+	TaxRate           float64
+	TaxInclusive      bool
+	ExperimentID      int32
+	ExperimentVariant string
+	Distance          float64
+	Duration          float64
+	SurgeAmount       money.Amount
+	SharedDiscount    money.Amount
+}
+
+// apply prices a booking request at the quote.
+func (q priceQuote) apply(bookingReq *models.BookingRequest) {
+	bookingReq.Price, bookingReq.Currency = q.Price, q.Currency
+	bookingReq.RegionID, bookingReq.TaxName, bookingReq.TaxRate, bookingReq.TaxInclusive = q.RegionID, q.TaxName, q.TaxRate, q.TaxInclusive
+	bookingReq.ExperimentID, bookingReq.ExperimentVariant = q.ExperimentID, q.ExperimentVariant
+	bookingReq.QuotedDistance, bookingReq.QuotedDuration, bookingReq.SurgeAmount = q.Distance, q.Duration, q.SurgeAmount
+	bookingReq.SharedDiscount = q.SharedDiscount
+	bookingReq.PromotionID, bookingReq.Discount = 0, 0
+	if q.Discount != nil {
+		bookingReq.PromotionID, bookingReq.Discount = q.Discount.PromotionID, q.Discount.Amount
+	}
+}
+
+// fetchPriceEstimate quotes a booking request for its user. The pricing
+// service only trusts the user from a token, so one is issued for them.
+func fetchPriceEstimate(bookingReq models.BookingRequest) (priceQuote, error) {
+	client, err := pricingService()
+	if err != nil {
+		return priceQuote{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pricingCallTimeout)
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, models.InternalQuoteHeader, "booking")
+	if userID, err := strconv.Atoi(bookingReq.UserID); err == nil {
+		userToken, err := token.GenerateToken(int32(userID), bookingReq.UserName)
+		if err != nil {
+			return priceQuote{}, err
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+userToken)
+	}
+
+	msg, err := client.Estimate(ctx, pricingrpc.FromBookingRequest(bookingReq))
+	if err != nil {
+		return priceQuote{}, fmt.Errorf("pricing service: %w", err)
+	}
+
+	estimate, err := pricingrpc.ToPriceEstimate(msg)
+	if err != nil {
+		return priceQuote{}, err
+	}
+	return priceQuote{
+		Price:             estimate.TotalPrice,
+		Currency:          estimate.Currency,
+		Discount:          estimate.Discount,
+		PromoError:        estimate.PromoError,
+		RegionID:          estimate.RegionID,
+		TaxName:           estimate.TaxName,
+		TaxRate:           estimate.TaxRate,
+		TaxInclusive:      estimate.TaxInclusive,
+		ExperimentID:      estimate.ExperimentID,
+		ExperimentVariant: estimate.ExperimentVariant,
+		Distance:          estimate.Distance,
+		Duration:          estimate.Duration,
+		SurgeAmount:       estimate.SurgeAmount,
+		SharedDiscount:    estimate.SharedDiscount,
+	}, nil
+}
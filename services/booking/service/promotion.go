@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/promotion"
+)
+
+// priceBookingRequest prices a request with the pricing service, applying
+// its promo code if it has one. A code that does not apply is reported in
+// promoError rather than priced without the discount the user asked for.
+func priceBookingRequest(bookingReq *models.BookingRequest) (promoError string, err error) {
+	bookingReq.PromoCode = promotion.NormaliseCode(bookingReq.PromoCode)
+
+	quote, err := fetchPriceEstimate(*bookingReq)
+	if err != nil {
+		return "", err
+	}
+	if quote.PromoError != "" {
+		return quote.PromoError, nil
+	}
+
+	quote.apply(bookingReq)
+	return "", nil
+}
+
+// isPromotionError reports whether a booking failed because its promotion
+// could no longer be redeemed.
+func isPromotionError(err error) bool {
+	return errors.Is(err, promotion.ErrNotApplicable) || errors.Is(err, promotion.ErrNotFound)
+}
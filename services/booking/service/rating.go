@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"logistics-platform/lib/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+)
+
+// ratingWindow is how long after completion either party may rate a booking.
+const ratingWindow = 72 * time.Hour
+
+// Drivers rated below defaultDispatchMinRating (DISPATCH_MIN_RATING) on
+// average over at least dispatchMinRatingCount ratings are not offered
+// requests.
+const (
+	defaultDispatchMinRating = 3.0
+	dispatchMinRatingCount   = 5
+)
+
+func (s *BookingService) HandleUserRating(c *gin.Context) {
+	s.handleRating(c, models.RaterRoleUser)
+}
+
+func (s *BookingService) HandleDriverRating(c *gin.Context) {
+	s.handleRating(c, models.RaterRoleDriver)
+}
+
+func (s *BookingService) handleRating(c *gin.Context, raterRole string) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	rater, _ := authUser.(models.UserRequest)
+
+	raterID, err := strconv.Atoi(rater.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + raterRole + " id"})
+		return
+	}
+
+	bookingID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	var ratingReq models.RatingRequest
+	if err := c.ShouldBindJSON(&ratingReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if ratingReq.Tags == nil {
+		ratingReq.Tags = []string{}
+	}
+
+	var userID, driverID int32
+	var status string
+	var completedAt *time.Time
+	err = s.PostgreSQLConn.QueryRow(context.Background(),
+		"SELECT user_id, driver_id, status, completed_at FROM booking WHERE id=$1", bookingID).
+		Scan(&userID, &driverID, &status, &completedAt)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error", "err": err})
+		return
+	}
+
+	rateeID := driverID
+	if raterRole == models.RaterRoleDriver {
+		rateeID = userID
+		if driverID != int32(raterID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+			return
+		}
+	} else if userID != int32(raterID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "booking not found"})
+		return
+	}
+
+	if status != "completed" || completedAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only completed bookings can be rated"})
+		return
+	}
+	if time.Since(*completedAt) > ratingWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating window has closed"})
+		return
+	}
+
+	pgComm, err := s.PostgreSQLConn.Exec(context.Background(),
+		"INSERT INTO booking_ratings (booking_id, rater_role, rater_id, ratee_id, rating, tags, comment) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (booking_id, rater_role) DO NOTHING",
+		bookingID, raterRole, raterID, rateeID, ratingReq.Rating, ratingReq.Tags, ratingReq.Comment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error storing rating"})
+		return
+	}
+
+	if pgComm.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "booking already rated"})
+		return
+	}
+
+	if raterRole == models.RaterRoleUser {
+		// drop the cached aggregate so dispatch picks up the new rating
+		_ = s.redisClient.Del(context.Background(), fmt.Sprintf("%d-rating", rateeID)).Err()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rating submitted"})
+}
+
+// GetDriverRating returns the aggregated rating given to a driver by shippers.
+// It is an input signal for dispatch, so it is cached in redis like the
+// driver's vehicle type.
+func (s *BookingService) GetDriverRating(driverID string) (models.RatingSummary, error) {
+	var summary models.RatingSummary
+
+	cached, err := s.redisClient.Get(context.Background(), driverID+"-rating").Result()
+	if err == nil && cached != "" {
+		if err := json.Unmarshal([]byte(cached), &summary); err == nil {
+			return summary, nil
+		}
+	}
+
+	err = s.PostgreSQLConn.QueryRow(context.Background(),
+		"SELECT COALESCE(AVG(rating), 0)::float8, COUNT(*) FROM booking_ratings WHERE rater_role=$1 AND ratee_id=$2",
+		models.RaterRoleUser, driverID).Scan(&summary.AverageRating, &summary.RatingCount)
+	if err != nil {
+		return models.RatingSummary{}, fmt.Errorf("error fetching driver rating: %w", err)
+	}
+
+	if summaryJSON, err := json.Marshal(summary); err == nil {
+		_ = s.redisClient.Set(context.Background(), driverID+"-rating", summaryJSON, 1*time.Hour).Err()
+	}
+
+	return summary, nil
+}
+
+// poorlyRatedDrivers returns which of driverIDs shippers have rated below
+// DISPATCH_MIN_RATING often enough for it to count, in one query. Drivers
+// with fewer ratings are given the benefit of the doubt.
+func (s *BookingService) poorlyRatedDrivers(ctx context.Context, driverIDs []string) (map[string]bool, error) {
+	minRating := defaultDispatchMinRating
+	if viper.IsSet("DISPATCH_MIN_RATING") {
+		minRating = viper.GetFloat64("DISPATCH_MIN_RATING")
+	}
+
+	ids := make([]int32, 0, len(driverIDs))
+	for _, driverID := range driverIDs {
+		if id, err := strconv.Atoi(driverID); err == nil {
+			ids = append(ids, int32(id))
+		}
+	}
+
+	poorlyRated := make(map[string]bool)
+	if len(ids) == 0 {
+		return poorlyRated, nil
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, `
+		SELECT ratee_id FROM booking_ratings
+		WHERE rater_role = $1 AND ratee_id = ANY($2)
+		GROUP BY ratee_id
+		HAVING COUNT(*) >= $3 AND AVG(rating) < $4`,
+		models.RaterRoleUser, ids, dispatchMinRatingCount, minRating)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching driver ratings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var driverID int32
+		if err := rows.Scan(&driverID); err != nil {
+			return nil, fmt.Errorf("error scanning driver rating: %w", err)
+		}
+		poorlyRated[strconv.Itoa(int(driverID))] = true
+	}
+	return poorlyRated, rows.Err()
+}
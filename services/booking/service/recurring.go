@@ -0,0 +1,660 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	// the service image ships without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// recurringHorizon is how far ahead occurrences are materialised, so users
+	// can see and skip upcoming trips
+	recurringHorizon = 7 * 24 * time.Hour
+	// recurringLeadTime is how long before pickup an occurrence is offered to
+	// drivers
+	recurringLeadTime = 30 * time.Minute
+	recurringTick     = time.Minute
+)
+
+var recurrenceDays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+const recurringBookingColumns = `id, user_id, pickup_latitude, pickup_longitude, pickup_name, dropoff_latitude, dropoff_longitude, dropoff_name,
+	vehicle_type, frequency, by_days, time_of_day, timezone, to_char(starts_on, 'YYYY-MM-DD'), COALESCE(to_char(ends_on, 'YYYY-MM-DD'), ''),
+	exception_dates, prefer_same_driver, COALESCE(last_driver_id, 0), active, created_at`
+
+func (s *BookingService) HandleCreateRecurringBooking(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var rb models.RecurringBooking
+	if err := c.ShouldBindJSON(&rb); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := normaliseRecurrence(&rb); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, err := fetchVehiclePricing(rb.VehicleType, 0, time.Time{}, 0, "")
+	if errors.Is(err, errUnsupportedVehicleType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error checking vehicle type"})
+		return
+	}
+
+	ctx := context.Background()
+	err = s.PostgreSQLConn.QueryRow(ctx,
+		`INSERT INTO recurring_bookings (user_id, pickup_latitude, pickup_longitude, pickup_name, dropoff_latitude, dropoff_longitude, dropoff_name,
+			vehicle_type, frequency, by_days, time_of_day, timezone, starts_on, ends_on, exception_dates, prefer_same_driver)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) RETURNING id, user_id, active, created_at`,
+		user.UserID, rb.Pickup.Latitude, rb.Pickup.Longitude, rb.Pickup.Name, rb.Dropoff.Latitude, rb.Dropoff.Longitude, rb.Dropoff.Name,
+		rb.VehicleType, rb.Frequency, rb.ByDays, rb.TimeOfDay, rb.Timezone, rb.StartsOn, nullableString(rb.EndsOn), rb.ExceptionDates, rb.PreferSameDriver,
+	).Scan(&rb.ID, &rb.UserID, &rb.Active, &rb.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating recurring booking"})
+		return
+	}
+
+	if err := s.materialiseOccurrences(ctx, rb, time.Now()); err != nil {
+		log.Printf("Error scheduling recurring booking %d: %v", rb.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"recurring_booking": rb})
+}
+
+func (s *BookingService) HandleListRecurringBookings(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(),
+		"SELECT "+recurringBookingColumns+" FROM recurring_bookings WHERE user_id = $1 ORDER BY created_at DESC", user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching recurring bookings"})
+		return
+	}
+	defer rows.Close()
+
+	recurringBookings := []models.RecurringBooking{}
+	for rows.Next() {
+		rb, err := scanRecurringBooking(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading recurring bookings"})
+			return
+		}
+		recurringBookings = append(recurringBookings, rb)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recurring_bookings": recurringBookings})
+}
+
+// HandleListOccurrences returns the materialised occurrences of a recurring
+// booking that have not been dispatched yet, along with recent outcomes.
+func (s *BookingService) HandleListOccurrences(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	rows, err := s.PostgreSQLConn.Query(context.Background(),
+		`SELECT id, recurring_booking_id, user_id, pickup_at, status, error FROM scheduled_bookings
+		WHERE recurring_booking_id = $1 AND user_id = $2 AND pickup_at > NOW() - INTERVAL '7 days'
+		ORDER BY pickup_at`, c.Param("id"), user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching occurrences"})
+		return
+	}
+	defer rows.Close()
+
+	occurrences := []models.ScheduledBooking{}
+	for rows.Next() {
+		var occurrence models.ScheduledBooking
+		if err := rows.Scan(&occurrence.ID, &occurrence.RecurringBookingID, &occurrence.UserID, &occurrence.PickupAt, &occurrence.Status, &occurrence.Error); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading occurrences"})
+			return
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
+
+// HandleAddRecurringExceptions adds holiday or exception dates on which a
+// recurring booking does not run. Occurrences already scheduled on those dates
+// are skipped.
+func (s *BookingService) HandleAddRecurringExceptions(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	var body struct {
+		Dates []string `json:"dates" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dates, err := normaliseDates(body.Dates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating recurring booking"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exceptionDates []string
+	var timezone string
+	err = tx.QueryRow(ctx,
+		`UPDATE recurring_bookings SET exception_dates = ARRAY(SELECT DISTINCT unnest(exception_dates || $1::TEXT[]) ORDER BY 1), updated_at = NOW()
+		WHERE id = $2 AND user_id = $3 RETURNING exception_dates, timezone`,
+		dates, c.Param("id"), user.UserID).Scan(&exceptionDates, &timezone)
+	if err == pgx.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "recurring booking not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating recurring booking"})
+		return
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE scheduled_bookings SET status = 'skipped', updated_at = NOW()
+		WHERE recurring_booking_id = $1 AND status = 'scheduled' AND to_char(pickup_at AT TIME ZONE $2, 'YYYY-MM-DD') = ANY($3)`,
+		c.Param("id"), timezone, dates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating occurrences"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error updating recurring booking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exception_dates": exceptionDates})
+}
+
+// HandleCancelRecurringBooking stops a recurring booking and cancels its
+// occurrences that have not been dispatched yet.
+func (s *BookingService) HandleCancelRecurringBooking(c *gin.Context) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	ctx := context.Background()
+	tx, err := s.PostgreSQLConn.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error cancelling recurring booking"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	pgComm, err := tx.Exec(ctx, "UPDATE recurring_bookings SET active = FALSE, updated_at = NOW() WHERE id = $1 AND user_id = $2", c.Param("id"), user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error cancelling recurring booking"})
+		return
+	}
+	if pgComm.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "recurring booking not found"})
+		return
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE scheduled_bookings SET status = 'cancelled', updated_at = NOW() WHERE recurring_booking_id = $1 AND status = 'scheduled'", c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error cancelling occurrences"})
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error cancelling recurring booking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring booking cancelled"})
+}
+
+// RunRecurringScheduler materialises upcoming occurrences of every active
+// recurring booking and dispatches those due for pickup, along with shared
+// pools whose window has closed, until the service shuts down. Occurrences
+// are claimed with SKIP LOCKED, so several booking service instances can run
+// the scheduler side by side.
+func (s *BookingService) RunRecurringScheduler() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(recurringTick)
+	defer ticker.Stop()
+
+	for {
+		ctx := context.Background()
+		if err := s.materialiseAllOccurrences(ctx); err != nil {
+			log.Printf("Error materialising recurring bookings: %v", err)
+		}
+		if err := s.dispatchDueOccurrences(ctx); err != nil {
+			log.Printf("Error dispatching recurring bookings: %v", err)
+		}
+		if err := s.dispatchDueSharedPools(ctx); err != nil {
+			log.Printf("Error dispatching shared pools: %v", err)
+		}
+
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *BookingService) materialiseAllOccurrences(ctx context.Context) error {
+	rows, err := s.PostgreSQLConn.Query(ctx, "SELECT "+recurringBookingColumns+" FROM recurring_bookings WHERE active")
+	if err != nil {
+		return err
+	}
+
+	var recurringBookings []models.RecurringBooking
+	for rows.Next() {
+		rb, err := scanRecurringBooking(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		recurringBookings = append(recurringBookings, rb)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rb := range recurringBookings {
+		if err := s.materialiseOccurrences(ctx, rb, now); err != nil {
+			log.Printf("Error scheduling recurring booking %d: %v", rb.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *BookingService) materialiseOccurrences(ctx context.Context, rb models.RecurringBooking, now time.Time) error {
+	pickupTimes, err := occurrencesBetween(rb, now.Add(recurringLeadTime), now.Add(recurringHorizon))
+	if err != nil {
+		return err
+	}
+
+	for _, pickupAt := range pickupTimes {
+		_, err := s.PostgreSQLConn.Exec(ctx,
+			"INSERT INTO scheduled_bookings (recurring_booking_id, user_id, pickup_at) VALUES ($1, $2, $3) ON CONFLICT (recurring_booking_id, pickup_at) DO NOTHING",
+			rb.ID, rb.UserID, pickupAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatchDueOccurrences claims occurrences and one-off scheduled requests
+// whose pickup is within the lead time and turns each into a booking request.
+func (s *BookingService) dispatchDueOccurrences(ctx context.Context) error {
+	rows, err := s.PostgreSQLConn.Query(ctx,
+		`UPDATE scheduled_bookings SET status = 'dispatching', updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM scheduled_bookings WHERE status = 'scheduled' AND pickup_at <= $1
+			ORDER BY pickup_at LIMIT 100 FOR UPDATE SKIP LOCKED
+		) RETURNING id, COALESCE(recurring_booking_id, 0), user_id, pickup_at, booking_request`, time.Now().Add(recurringLeadTime))
+	if err != nil {
+		return err
+	}
+
+	var due []models.ScheduledBooking
+	for rows.Next() {
+		var occurrence models.ScheduledBooking
+		var bookingReqJSON []byte
+		if err := rows.Scan(&occurrence.ID, &occurrence.RecurringBookingID, &occurrence.UserID, &occurrence.PickupAt, &bookingReqJSON); err != nil {
+			rows.Close()
+			return err
+		}
+		if bookingReqJSON != nil {
+			occurrence.BookingRequest = &models.BookingRequest{}
+			if err := json.Unmarshal(bookingReqJSON, occurrence.BookingRequest); err != nil {
+				rows.Close()
+				return fmt.Errorf("error reading scheduled booking %d: %w", occurrence.ID, err)
+			}
+		}
+		due = append(due, occurrence)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, occurrence := range due {
+		status, dispatchErr := s.dispatchOccurrence(ctx, occurrence)
+		errMessage := ""
+		if dispatchErr != nil {
+			errMessage = dispatchErr.Error()
+			log.Printf("Error dispatching scheduled booking %d: %v", occurrence.ID, dispatchErr)
+		}
+
+		if _, err := s.PostgreSQLConn.Exec(ctx,
+			"UPDATE scheduled_bookings SET status = $1, error = $2, updated_at = NOW() WHERE id = $3",
+			status, errMessage, occurrence.ID); err != nil {
+			log.Printf("Error updating scheduled booking %d: %v", occurrence.ID, err)
+		}
+	}
+	return nil
+}
+
+// dispatchOccurrence rechecks the template, since it may have been cancelled
+// or given a new exception date after the occurrence was materialised, and
+// submits the booking request. It returns the occurrence's final status.
+func (s *BookingService) dispatchOccurrence(ctx context.Context, occurrence models.ScheduledBooking) (string, error) {
+	if occurrence.PickupAt.Before(time.Now()) {
+		return "missed", nil
+	}
+	if occurrence.BookingRequest != nil {
+		return s.dispatchScheduledRequest(ctx, *occurrence.BookingRequest)
+	}
+
+	row := s.PostgreSQLConn.QueryRow(ctx, "SELECT "+recurringBookingColumns+" FROM recurring_bookings WHERE id = $1", occurrence.RecurringBookingID)
+	rb, err := scanRecurringBooking(row)
+	if err != nil {
+		return "failed", fmt.Errorf("error loading recurring booking: %w", err)
+	}
+
+	if !rb.Active {
+		return "cancelled", nil
+	}
+	if loc, err := time.LoadLocation(rb.Timezone); err == nil && containsString(rb.ExceptionDates, occurrence.PickupAt.In(loc).Format("2006-01-02")) {
+		return "skipped", nil
+	}
+
+	var userName string
+	if err := s.PostgreSQLConn.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", rb.UserID).Scan(&userName); err != nil {
+		return "failed", fmt.Errorf("error loading user: %w", err)
+	}
+
+	pickupAt := occurrence.PickupAt
+	bookingReq := models.BookingRequest{
+		UserID:             fmt.Sprint(rb.UserID),
+		UserName:           userName,
+		Pickup:             rb.Pickup,
+		Dropoff:            rb.Dropoff,
+		VehicleType:        rb.VehicleType,
+		PickupAt:           &pickupAt,
+		RecurringBookingID: rb.ID,
+	}
+	if rb.PreferSameDriver && rb.LastDriverID != 0 {
+		bookingReq.PreferredDriverID = fmt.Sprint(rb.LastDriverID)
+	}
+
+	quote, err := fetchPriceEstimate(bookingReq)
+	if err != nil {
+		return "failed", fmt.Errorf("error estimating price: %w", err)
+	}
+	quote.apply(&bookingReq)
+
+	approvalID, err := s.applyOrganisationPolicy(ctx, &bookingReq)
+	if err != nil {
+		return "failed", err
+	}
+	if approvalID != "" {
+		return "awaiting_approval", nil
+	}
+
+	if err := s.ProcessBookingRequest(bookingReq); err != nil {
+		return "failed", err
+	}
+	return "dispatched", nil
+}
+
+// dispatchScheduledRequest prices and submits a one-off request held until
+// near pickup, through the same path as a request made then.
+func (s *BookingService) dispatchScheduledRequest(ctx context.Context, bookingReq models.BookingRequest) (string, error) {
+	promoError, err := priceBookingRequest(&bookingReq)
+	if err != nil {
+		return "failed", fmt.Errorf("error estimating price: %w", err)
+	}
+	if promoError != "" {
+		return "failed", errors.New(promoError)
+	}
+
+	approvalID, err := s.applyOrganisationPolicy(ctx, &bookingReq)
+	if err != nil {
+		return "failed", err
+	}
+	if approvalID != "" {
+		return "awaiting_approval", nil
+	}
+
+	if err := s.ProcessBookingRequest(bookingReq); err != nil {
+		return "failed", err
+	}
+	return "dispatched", nil
+}
+
+// scheduleBookingRequest holds a one-off request until recurringLeadTime
+// before its pickup, when the recurring scheduler dispatches it.
+func (s *BookingService) scheduleBookingRequest(ctx context.Context, bookingReq models.BookingRequest) (int32, error) {
+	bookingReqJSON, err := json.Marshal(bookingReq)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int32
+	err = s.PostgreSQLConn.QueryRow(ctx,
+		"INSERT INTO scheduled_bookings (user_id, pickup_at, booking_request) VALUES ($1, $2, $3) RETURNING id",
+		bookingReq.UserID, *bookingReq.PickupAt, bookingReqJSON).Scan(&id)
+	return id, err
+}
+
+// recordRecurringDriver remembers the driver who took an occurrence so the
+// next one can be offered to them first.
+func (s *BookingService) recordRecurringDriver(recurringBookingID int32, driverID string) {
+	_, err := s.PostgreSQLConn.Exec(context.Background(),
+		"UPDATE recurring_bookings SET last_driver_id = $1, updated_at = NOW() WHERE id = $2", driverID, recurringBookingID)
+	if err != nil {
+		log.Printf("Error recording driver for recurring booking %d: %v", recurringBookingID, err)
+	}
+}
+
+// occurrencesBetween expands a recurrence into the pickup times that fall in
+// [from, to). Days are walked in the template's time zone so pickups keep
+// their local time across daylight saving changes.
+func occurrencesBetween(rb models.RecurringBooking, from, to time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(rb.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	clock, err := time.Parse("15:04", rb.TimeOfDay)
+	if err != nil {
+		return nil, errors.New("time_of_day must be formatted as HH:MM")
+	}
+
+	startsOn, err := time.ParseInLocation("2006-01-02", rb.StartsOn, loc)
+	if err != nil {
+		return nil, errors.New("starts_on must be formatted as YYYY-MM-DD")
+	}
+
+	var endsOn time.Time
+	if rb.EndsOn != "" {
+		if endsOn, err = time.ParseInLocation("2006-01-02", rb.EndsOn, loc); err != nil {
+			return nil, errors.New("ends_on must be formatted as YYYY-MM-DD")
+		}
+	}
+
+	fromLocal := from.In(loc)
+	day := time.Date(fromLocal.Year(), fromLocal.Month(), fromLocal.Day(), 0, 0, 0, 0, loc)
+	if day.Before(startsOn) {
+		day = startsOn
+	}
+
+	var pickupTimes []time.Time
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !endsOn.IsZero() && day.After(endsOn) {
+			break
+		}
+		if !recursOn(rb, day.Weekday()) || containsString(rb.ExceptionDates, day.Format("2006-01-02")) {
+			continue
+		}
+
+		pickupAt := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if pickupAt.Before(from) || !pickupAt.Before(to) {
+			continue
+		}
+		pickupTimes = append(pickupTimes, pickupAt)
+	}
+
+	return pickupTimes, nil
+}
+
+func recursOn(rb models.RecurringBooking, weekday time.Weekday) bool {
+	switch rb.Frequency {
+	case models.FrequencyDaily:
+		return true
+	case models.FrequencyWeekdays:
+		return weekday != time.Saturday && weekday != time.Sunday
+	case models.FrequencyWeekly:
+		for _, code := range rb.ByDays {
+			if recurrenceDays[code] == weekday {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normaliseRecurrence validates a new recurring booking and canonicalises its
+// day codes and dates.
+func normaliseRecurrence(rb *models.RecurringBooking) error {
+	if _, err := time.LoadLocation(rb.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", rb.Timezone)
+	}
+	if _, err := time.Parse("15:04", rb.TimeOfDay); err != nil {
+		return errors.New("time_of_day must be formatted as HH:MM")
+	}
+
+	startsOn, err := time.Parse("2006-01-02", rb.StartsOn)
+	if err != nil {
+		return errors.New("starts_on must be formatted as YYYY-MM-DD")
+	}
+	if rb.EndsOn != "" {
+		endsOn, err := time.Parse("2006-01-02", rb.EndsOn)
+		if err != nil {
+			return errors.New("ends_on must be formatted as YYYY-MM-DD")
+		}
+		if endsOn.Before(startsOn) {
+			return errors.New("ends_on is before starts_on")
+		}
+	}
+
+	byDays := []string{}
+	if rb.Frequency == models.FrequencyWeekly {
+		seen := make(map[string]bool)
+		for _, code := range rb.ByDays {
+			code = strings.ToUpper(strings.TrimSpace(code))
+			if _, ok := recurrenceDays[code]; !ok {
+				return fmt.Errorf("unknown day %q, use MO, TU, WE, TH, FR, SA or SU", code)
+			}
+			if !seen[code] {
+				seen[code] = true
+				byDays = append(byDays, code)
+			}
+		}
+		if len(byDays) == 0 {
+			return errors.New("weekly recurrences need at least one day in by_days")
+		}
+	}
+	rb.ByDays = byDays
+
+	if rb.ExceptionDates, err = normaliseDates(rb.ExceptionDates); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func normaliseDates(dates []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalised := []string{}
+	for _, date := range dates {
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD", date)
+		}
+		date = t.Format("2006-01-02")
+		if !seen[date] {
+			seen[date] = true
+			normalised = append(normalised, date)
+		}
+	}
+	sort.Strings(normalised)
+	return normalised, nil
+}
+
+func scanRecurringBooking(row pgx.Row) (models.RecurringBooking, error) {
+	var rb models.RecurringBooking
+	err := row.Scan(&rb.ID, &rb.UserID, &rb.Pickup.Latitude, &rb.Pickup.Longitude, &rb.Pickup.Name,
+		&rb.Dropoff.Latitude, &rb.Dropoff.Longitude, &rb.Dropoff.Name, &rb.VehicleType, &rb.Frequency, &rb.ByDays,
+		&rb.TimeOfDay, &rb.Timezone, &rb.StartsOn, &rb.EndsOn, &rb.ExceptionDates, &rb.PreferSameDriver,
+		&rb.LastDriverID, &rb.Active, &rb.CreatedAt)
+	return rb, err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
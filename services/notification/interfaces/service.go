@@ -15,5 +15,11 @@ type NotificationInterface interface {
 	SendLocationUpdate(location models.DriverLocation) error
 	SendNotification(notification models.BookingNotification) error
 	HandleUserWebSocket(c *gin.Context)
+	SendOfferAction(message []byte, driver models.UserRequest) error
+	ConsumeOfferResults()
+	ConsumeChatDeliveries()
+	ConsumeSurgeHeatmaps()
+	HandleUserChatHistory(c *gin.Context)
+	HandleDriverChatHistory(c *gin.Context)
 	GracefulShutdown(server *http.Server)
 }
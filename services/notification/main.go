@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"logistics-platform/lib/config"
 	"logistics-platform/lib/middlewares/cors"
 	"logistics-platform/services/notification/router"
 	"logistics-platform/services/notification/service"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 func main() {
@@ -16,7 +19,23 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	service := service.NewNotificationService()
+	poolConfig, err := pgxpool.ParseConfig(config.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Failed to parse pool config: %v", err)
+	}
+
+	poolConfig.MaxConns = 10
+	poolConfig.MinConns = 2
+	poolConfig.MaxConnLifetime = 1 * time.Hour
+	poolConfig.MaxConnIdleTime = 30 * time.Minute
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pool.Close()
+
+	service := service.NewNotificationService(pool)
 
 	r := gin.Default()
 	r.Use(cors.CORSMiddleware())
@@ -29,6 +48,9 @@ func main() {
 
 	go service.ConsumeNotifications()
 	go service.ConsumeBookingNotifications()
+	go service.ConsumeOfferResults()
+	go service.ConsumeChatDeliveries()
+	go service.ConsumeSurgeHeatmaps()
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
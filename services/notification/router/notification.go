@@ -1,6 +1,7 @@
 package router
 
 import (
+	"logistics-platform/lib/middlewares/auth"
 	"logistics-platform/services/notification/interfaces"
 	"net/http"
 
@@ -15,4 +16,7 @@ func SetupRouter(router *gin.Engine, service interfaces.NotificationInterface) {
 		c.String(http.StatusOK, "pong")
 	})
 
+	router.GET("/user/chat/:bookingId", auth.AuthInjectionMiddleware(), service.HandleUserChatHistory)
+	router.GET("/driver/chat/:bookingId", auth.AuthInjectionMiddleware(), service.HandleDriverChatHistory)
+
 }
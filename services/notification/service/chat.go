@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v4"
+	"github.com/segmentio/kafka-go"
+)
+
+const maxChatMessageLength = 2000
+
+var errChatClosed = errors.New("chat is closed for this booking")
+
+// bookingParties returns the user and driver of a booking whose chat is open,
+// checking that the caller is one of them.
+func (s *NotificationService) bookingParties(ctx context.Context, bookingID int32, role, callerID string) (userID, driverID int32, err error) {
+	var status string
+	err = s.PostgreSQLConn.QueryRow(ctx, "SELECT user_id, driver_id, status FROM booking WHERE id = $1", bookingID).Scan(&userID, &driverID, &status)
+	if err == pgx.ErrNoRows {
+		return 0, 0, errors.New("booking not found")
+	} else if err != nil {
+		return 0, 0, fmt.Errorf("error loading booking: %w", err)
+	}
+
+	callerIsParty := (role == models.ChatRoleUser && strconv.Itoa(int(userID)) == callerID) ||
+		(role == models.ChatRoleDriver && strconv.Itoa(int(driverID)) == callerID)
+	if !callerIsParty {
+		return 0, 0, errors.New("booking not found")
+	}
+
+	if status == "completed" || status == "cancelled" {
+		return userID, driverID, errChatClosed
+	}
+
+	return userID, driverID, nil
+}
+
+// handleChat processes a chat_message or chat_read sent on a socket. role is
+// the kind of socket it arrived on and senderID the authenticated caller.
+// Errors are reported back on the sender's socket.
+func (s *NotificationService) handleChat(conn *wsConn, message []byte, role, senderID string) {
+	var chatMessage models.ChatMessage
+	if err := json.Unmarshal(message, &chatMessage); err != nil {
+		s.writeChatError(conn, chatMessage, "invalid chat message")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, driverID, err := s.bookingParties(ctx, chatMessage.BookingID, role, senderID)
+	if err != nil && !(errors.Is(err, errChatClosed) && chatMessage.Type == models.ChatTypeRead) {
+		s.writeChatError(conn, chatMessage, err.Error())
+		return
+	}
+
+	sender, recipient := userID, driverID
+	recipientRole := models.ChatRoleDriver
+	if role == models.ChatRoleDriver {
+		sender, recipient = driverID, userID
+		recipientRole = models.ChatRoleUser
+	}
+
+	switch chatMessage.Type {
+	case models.ChatTypeMessage:
+		chatMessage.Body = strings.TrimSpace(chatMessage.Body)
+		if chatMessage.Body == "" || len(chatMessage.Body) > maxChatMessageLength {
+			s.writeChatError(conn, chatMessage, fmt.Sprintf("message must be between 1 and %d characters", maxChatMessageLength))
+			return
+		}
+
+		chatMessage.SenderRole = role
+		chatMessage.SenderID = sender
+		chatMessage.RecipientID = recipient
+		chatMessage.ReadAt = nil
+		err := s.PostgreSQLConn.QueryRow(ctx, `
+			INSERT INTO chat_messages (booking_id, sender_role, sender_id, recipient_id, body, client_id)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, sent_at`,
+			chatMessage.BookingID, role, sender, recipient, chatMessage.Body, chatMessage.ClientID).Scan(&chatMessage.ID, &chatMessage.SentAt)
+		if err != nil {
+			log.Printf("Error storing chat message: %v", err)
+			s.writeChatError(conn, chatMessage, "error storing chat message")
+			return
+		}
+
+		// the stored copy doubles as the sender's delivery acknowledgement
+		if err := conn.WriteJSON(chatMessage); err != nil {
+			log.Printf("Error acknowledging chat message: %v", err)
+		}
+		s.sendToParty(recipientRole, strconv.Itoa(int(recipient)), chatMessage)
+
+	case models.ChatTypeRead:
+		rows, err := s.PostgreSQLConn.Query(ctx, `
+			UPDATE chat_messages SET read_at = NOW()
+			WHERE booking_id = $1 AND recipient_id = $2 AND sender_role = $3 AND read_at IS NULL
+			RETURNING id`, chatMessage.BookingID, sender, recipientRole)
+		if err != nil {
+			log.Printf("Error marking chat messages read: %v", err)
+			return
+		}
+
+		receipt := models.ChatReceipt{Type: models.ChatTypeRead, BookingID: chatMessage.BookingID, ReaderRole: role, At: time.Now()}
+		for rows.Next() {
+			var id int32
+			if err := rows.Scan(&id); err == nil {
+				receipt.MessageIDs = append(receipt.MessageIDs, id)
+			}
+		}
+		rows.Close()
+
+		if len(receipt.MessageIDs) > 0 {
+			s.sendToParty(recipientRole, strconv.Itoa(int(recipient)), receipt)
+		}
+
+	default:
+		s.writeChatError(conn, chatMessage, "unknown chat message type")
+	}
+}
+
+// closeChat tells both parties that a booking's chat has ended. New messages
+// are refused from then on because the booking is no longer active.
+func (s *NotificationService) closeChat(notification models.BookedNotification) {
+	if notification.BookingID == 0 {
+		return
+	}
+
+	receipt := models.ChatReceipt{Type: models.ChatTypeClosed, BookingID: notification.BookingID, At: time.Now()}
+	s.sendToParty(models.ChatRoleUser, notification.UserID, receipt)
+	s.sendToParty(models.ChatRoleDriver, notification.DriverID, receipt)
+}
+
+// chatDelivery is a chat payload for a socket held by another instance,
+// relayed on the chat_deliveries topic.
+type chatDelivery struct {
+	Role        string          `json:"role"`
+	RecipientID string          `json:"recipient_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// sendToParty relays a chat payload to one party. Their socket is written
+// directly when this instance holds it; otherwise the payload goes out on
+// chat_deliveries for whichever instance does.
+func (s *NotificationService) sendToParty(role, id string, payload interface{}) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding chat for %s %s: %v", role, id, err)
+		return
+	}
+
+	delivery := chatDelivery{Role: role, RecipientID: id, Payload: payloadJSON}
+	if s.deliverChat(delivery) {
+		return
+	}
+
+	deliveryJSON, err := json.Marshal(delivery)
+	if err != nil {
+		log.Printf("Error encoding chat for %s %s: %v", role, id, err)
+		return
+	}
+	if err := s.chatWriter.WriteMessages(context.Background(), kafka.Message{Value: deliveryJSON}); err != nil {
+		log.Printf("Error relaying chat to %s %s: %v", role, id, err)
+	}
+}
+
+// deliverChat writes a chat payload to the recipient's socket, reporting
+// whether this instance holds it.
+func (s *NotificationService) deliverChat(delivery chatDelivery) bool {
+	connections := &s.userConnections
+	if delivery.Role == models.ChatRoleDriver {
+		connections = &s.driverConnections
+	}
+
+	conn, ok := connections.Load(delivery.RecipientID)
+	if !ok {
+		return false
+	}
+	if err := conn.(*wsConn).WriteMessage(websocket.TextMessage, delivery.Payload); err != nil {
+		log.Printf("Error relaying chat to %s %s: %v", delivery.Role, delivery.RecipientID, err)
+	}
+	return true
+}
+
+// ConsumeChatDeliveries writes chat relayed by other instances to the sockets
+// this instance holds. A recipient who is not connected anywhere sees the
+// message in the chat history on reconnect.
+func (s *NotificationService) ConsumeChatDeliveries() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.shutdown:
+			log.Println("Stopping chat delivery consumer")
+			return
+		default:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			msg, err := s.chatReader.FetchMessage(ctx)
+			cancel()
+
+			if err != nil {
+				if err == context.DeadlineExceeded {
+					time.Sleep(1 * time.Second)
+				} else {
+					log.Printf("Error fetching chat delivery: %v", err)
+				}
+				continue
+			}
+
+			var delivery chatDelivery
+			if err := json.Unmarshal(msg.Value, &delivery); err != nil {
+				log.Printf("Error unmarshaling chat delivery: %v", err)
+			} else {
+				s.deliverChat(delivery)
+			}
+
+			if err := s.chatReader.CommitMessages(context.Background(), msg); err != nil {
+				log.Printf("Error committing message: %v", err)
+			}
+		}
+	}
+}
+
+func (s *NotificationService) writeChatError(conn *wsConn, chatMessage models.ChatMessage, message string) {
+	if err := conn.WriteJSON(gin.H{"type": "chat_error", "booking_id": chatMessage.BookingID, "client_id": chatMessage.ClientID, "error": message}); err != nil {
+		log.Printf("Error sending chat error: %v", err)
+	}
+}
+
+func (s *NotificationService) HandleUserChatHistory(c *gin.Context) {
+	s.handleChatHistory(c, models.ChatRoleUser)
+}
+
+func (s *NotificationService) HandleDriverChatHistory(c *gin.Context) {
+	s.handleChatHistory(c, models.ChatRoleDriver)
+}
+
+// handleChatHistory returns every message of a booking's chat to either
+// party, including after the booking has ended.
+func (s *NotificationService) handleChatHistory(c *gin.Context, role string) {
+	authUser, ok := c.Get("user")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid auth token"})
+		return
+	}
+
+	user, _ := authUser.(models.UserRequest)
+
+	bookingID, err := strconv.Atoi(c.Param("bookingId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	ctx := context.Background()
+	if _, _, err := s.bookingParties(ctx, int32(bookingID), role, user.UserID); err != nil && !errors.Is(err, errChatClosed) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := s.PostgreSQLConn.Query(ctx, `
+		SELECT id, booking_id, sender_role, sender_id, recipient_id, body, COALESCE(client_id, ''), sent_at, read_at
+		FROM chat_messages WHERE booking_id = $1 ORDER BY sent_at, id`, bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error fetching chat"})
+		return
+	}
+	defer rows.Close()
+
+	messages := []models.ChatMessage{}
+	for rows.Next() {
+		message := models.ChatMessage{Type: models.ChatTypeMessage}
+		if err := rows.Scan(&message.ID, &message.BookingID, &message.SenderRole, &message.SenderID, &message.RecipientID,
+			&message.Body, &message.ClientID, &message.SentAt, &message.ReadAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading chat"})
+			return
+		}
+		messages = append(messages, message)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeWait bounds a single write so a stalled client cannot hold the write
+// lock indefinitely
+const writeWait = 10 * time.Second
+
+// wsConn is a websocket connection that is safe to write from several
+// goroutines. gorilla/websocket supports one concurrent reader and one
+// concurrent writer, but messages are pushed to a socket from several Kafka
+// readers and handlers at once, so every write goes through the connection's
+// mutex.
+type wsConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func newWSConn(conn *websocket.Conn) *wsConn {
+	return &wsConn{Conn: conn}
+}
+
+func (c *wsConn) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteJSON(v)
+}
+
+func (c *wsConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.Conn.WriteMessage(messageType, data)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"log"
+	"logistics-platform/lib/models"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// etaMinChange is the smallest change in minutes worth pushing to the user
+	etaMinChange = 1.0
+	// etaMinInterval rate limits routing, and so pushes, per trip
+	etaMinInterval = 15 * time.Second
+)
+
+type activeTrip struct {
+	mu       sync.Mutex
+	userID   string
+	pickup   models.GeoPoint
+	dropoff  models.GeoPoint
+	phase    string
+	lastETA  float64
+	lastSent time.Time
+	// routing is set while a route is being computed; lastRouted is when the
+	// last one was started
+	routing    bool
+	lastRouted time.Time
+}
+
+// trackTrip keeps the endpoints of each booking a driver is carrying so that
+// location updates can be turned into ETAs.
+func (s *NotificationService) trackTrip(notification models.BookedNotification) {
+	switch notification.Status {
+	case "booked":
+		if notification.Pickup == nil || notification.Dropoff == nil {
+			return
+		}
+		s.activeTrips.Store(tripKey(notification.DriverID, notification.UserID), &activeTrip{
+			userID:  notification.UserID,
+			pickup:  *notification.Pickup,
+			dropoff: *notification.Dropoff,
+			phase:   "pickup",
+		})
+	case "picked_up":
+		if trip, ok := s.activeTrips.Load(tripKey(notification.DriverID, notification.UserID)); ok {
+			trip := trip.(*activeTrip)
+			trip.mu.Lock()
+			trip.phase = "dropoff"
+			trip.lastSent = time.Time{}
+			trip.lastRouted = time.Time{}
+			trip.mu.Unlock()
+		}
+	case "completed", "cancelled":
+		s.activeTrips.Delete(tripKey(notification.DriverID, notification.UserID))
+	}
+}
+
+// pushETA sends an eta_update to the user when the ETA from the driver's new
+// location to the user's own pickup or dropoff differs meaningfully from the
+// last one sent. Each trip is routed at most once per etaMinInterval, in the
+// background so location pings are never held up by the routing provider.
+func (s *NotificationService) pushETA(conn *wsConn, location models.DriverLocation, userID string) {
+	value, ok := s.activeTrips.Load(tripKey(location.DriverID, userID))
+	if !ok {
+		return
+	}
+	trip := value.(*activeTrip)
+
+	trip.mu.Lock()
+	if trip.routing || (!trip.lastRouted.IsZero() && time.Since(trip.lastRouted) < etaMinInterval) {
+		trip.mu.Unlock()
+		return
+	}
+	trip.routing = true
+	trip.lastRouted = time.Now()
+	phase, target := trip.phase, trip.pickup
+	if phase == "dropoff" {
+		target = trip.dropoff
+	}
+	trip.mu.Unlock()
+
+	go s.routeETA(conn, trip, location, phase, target)
+}
+
+func (s *NotificationService) routeETA(conn *wsConn, trip *activeTrip, location models.DriverLocation, phase string, target models.GeoPoint) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	route, err := s.routingProvider.Route(ctx, location.Location, target)
+	cancel()
+
+	trip.mu.Lock()
+	defer trip.mu.Unlock()
+	trip.routing = false
+
+	if err != nil {
+		log.Printf("Error routing driver %s: %v", location.DriverID, err)
+		return
+	}
+	// the driver picked the user up while the route was computed
+	if trip.phase != phase {
+		return
+	}
+	if !trip.lastSent.IsZero() && math.Abs(route.Duration-trip.lastETA) < etaMinChange {
+		return
+	}
+
+	update := models.ETAUpdate{
+		Type:       "eta_update",
+		DriverID:   location.DriverID,
+		Phase:      phase,
+		ETAMinutes: math.Round(route.Duration*10) / 10,
+		DistanceKm: math.Round(route.Distance*100) / 100,
+		Provider:   route.Provider,
+		UpdatedAt:  time.Now(),
+	}
+	if err := conn.WriteJSON(update); err != nil {
+		log.Printf("Error sending ETA to user %s: %v", trip.userID, err)
+		return
+	}
+
+	trip.lastETA = route.Duration
+	trip.lastSent = update.UpdatedAt
+}
+
+func tripKey(driverID, userID string) string {
+	return driverID + "/" + userID
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"logistics-platform/lib/models"
+	"time"
+)
+
+// ConsumeSurgeHeatmaps pushes each surge heatmap the pricing service
+// publishes to every connected driver, and keeps the latest for drivers who
+// connect before the next one.
+func (s *NotificationService) ConsumeSurgeHeatmaps() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.shutdown:
+			log.Println("Stopping surge heatmap consumer")
+			return
+		default:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			msg, err := s.heatmapReader.FetchMessage(ctx)
+			cancel()
+
+			if err != nil {
+				if err == context.DeadlineExceeded {
+					time.Sleep(1 * time.Second)
+				} else {
+					log.Printf("Error fetching surge heatmap: %v", err)
+				}
+				continue
+			}
+
+			var heatmap models.SurgeHeatmap
+			if err := json.Unmarshal(msg.Value, &heatmap); err != nil {
+				log.Printf("Error unmarshaling surge heatmap: %v", err)
+			} else {
+				s.broadcastHeatmap(heatmap)
+			}
+
+			if err := s.heatmapReader.CommitMessages(context.Background(), msg); err != nil {
+				log.Printf("Error committing message: %v", err)
+			}
+		}
+	}
+}
+
+func (s *NotificationService) broadcastHeatmap(heatmap models.SurgeHeatmap) {
+	s.heatmapMu.Lock()
+	// heatmaps can arrive out of order after a rebalance
+	if s.heatmap != nil && heatmap.GeneratedAt.Before(s.heatmap.GeneratedAt) {
+		s.heatmapMu.Unlock()
+		return
+	}
+	s.heatmap = &heatmap
+	s.heatmapMu.Unlock()
+
+	s.driverConnections.Range(func(driverID, conn interface{}) bool {
+		if err := conn.(*wsConn).WriteJSON(heatmap); err != nil {
+			log.Printf("Error sending surge heatmap to driver %s: %v", driverID, err)
+		}
+		return true
+	})
+}
+
+// sendLatestHeatmap gives a driver who has just connected the current
+// heatmap.
+func (s *NotificationService) sendLatestHeatmap(conn *wsConn, driverID string) {
+	s.heatmapMu.Lock()
+	heatmap := s.heatmap
+	s.heatmapMu.Unlock()
+
+	if heatmap == nil {
+		return
+	}
+	if err := conn.WriteJSON(heatmap); err != nil {
+		log.Printf("Error sending surge heatmap to driver %s: %v", driverID, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	kafkaConfig "logistics-platform/lib/kafka"
 	"logistics-platform/lib/models"
+	"logistics-platform/lib/routing"
 	"logistics-platform/lib/token"
 	"logistics-platform/services/notification/interfaces"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -29,18 +31,36 @@ type NotificationService struct {
 	driverConnections      sync.Map
 	userConnections        sync.Map
 	driverUserConnections  sync.Map
+	ridersMu               sync.Mutex
+	activeTrips            sync.Map
+	routingProvider        routing.Provider
 	locationWriter         *kafka.Writer
+	offerActionWriter      *kafka.Writer
 	notificationReader     *kafka.Reader
 	bookNotificationReader *kafka.Reader
+	offerResultReader      *kafka.Reader
+	chatWriter             *kafka.Writer
+	chatReader             *kafka.Reader
+	heatmapReader          *kafka.Reader
+	heatmapMu              sync.Mutex
+	heatmap                *models.SurgeHeatmap
+	PostgreSQLConn         *pgxpool.Pool
 	shutdown               chan struct{}
 	wg                     sync.WaitGroup
 }
 
-func NewNotificationService() interfaces.NotificationInterface {
+func NewNotificationService(pool *pgxpool.Pool) interfaces.NotificationInterface {
 	return &NotificationService{
+		PostgreSQLConn:         pool,
 		locationWriter:         kafkaConfig.InitKafkaWriter("driver_locations"),
+		offerActionWriter:      kafkaConfig.InitKafkaWriter("driver_offer_actions"),
 		notificationReader:     kafkaConfig.InitKafkaReader("driver_notification", "driver_notification"),
-		bookNotificationReader: kafkaConfig.InitKafkaReader("booking_notifications", "notification_service_group"),
+		bookNotificationReader: kafkaConfig.InitBroadcastReader("booking_notifications", "notification_service"),
+		offerResultReader:      kafkaConfig.InitBroadcastReader("driver_offer_results", "notification_service"),
+		chatWriter:             kafkaConfig.InitKafkaWriter("chat_deliveries"),
+		chatReader:             kafkaConfig.InitBroadcastReader("chat_deliveries", "notification_service"),
+		heatmapReader:          kafkaConfig.InitBroadcastReader("surge_heatmap", "notification_service"),
+		routingProvider:        routing.NewProvider(nil),
 		shutdown:               make(chan struct{}),
 	}
 }
@@ -123,15 +143,22 @@ func (s *NotificationService) ConsumeBookingNotifications() {
 
 			// log.Printf("Processing notification: %+v", notification)
 
+			s.trackTrip(notification)
+
 			// Handle notification status
 			switch notification.Status {
 			case "booked":
-				s.driverUserConnections.Store(notification.DriverID, notification.UserID)
+				s.addRider(notification.DriverID, notification.UserID)
 				s.NotifyUser(notification.UserID, notification)
 			case "completed":
-				s.driverUserConnections.Delete(notification.DriverID)
+				s.removeRider(notification.DriverID, notification.UserID)
+				s.closeChat(notification)
 				s.NotifyUser(notification.UserID, notification)
 				s.userConnections.Delete(notification.UserID)
+			case "cancelled":
+				s.removeRider(notification.DriverID, notification.UserID)
+				s.closeChat(notification)
+				s.NotifyUser(notification.UserID, notification)
 			default:
 				s.NotifyUser(notification.UserID, notification)
 			}
@@ -149,7 +176,7 @@ func (s *NotificationService) ConsumeBookingNotifications() {
 func (s *NotificationService) NotifyUser(userID string, notification models.BookedNotification) {
 	conn, ok := s.userConnections.Load(userID)
 	if ok {
-		if err := conn.(*websocket.Conn).WriteJSON(notification); err != nil {
+		if err := conn.(*wsConn).WriteJSON(notification); err != nil {
 			log.Printf("Error sending notification to user %s: %v", userID, err)
 		}
 	} else {
@@ -158,11 +185,12 @@ func (s *NotificationService) NotifyUser(userID string, notification models.Book
 }
 
 func (s *NotificationService) HandleDriverWebSocket(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade connection: %v", err)
 		return
 	}
+	conn := newWSConn(ws)
 	defer conn.Close()
 
 	// Expect an initial message with the authentication token
@@ -201,10 +229,38 @@ func (s *NotificationService) HandleDriverWebSocket(c *gin.Context) {
 		s.SendLocationUpdate(models.DriverLocation{DriverID: driverID})
 	}()
 
-	// Proceed with WebSocket communication
+	s.sendLatestHeatmap(conn, driverID)
+
+	// Proceed with WebSocket communication. Replies to offers and chat carry a
+	// type; everything else is a location update.
 	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			log.Printf("Error reading driver message: %v", err)
+			break
+		}
+
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			log.Printf("Error reading driver message JSON: %v", err)
+			break
+		}
+
+		switch envelope.Type {
+		case models.OfferActionAccept, models.OfferActionDecline:
+			if err := s.SendOfferAction(message, user); err != nil {
+				log.Printf("Error sending offer action: %v", err)
+			}
+			continue
+		case models.ChatTypeMessage, models.ChatTypeRead:
+			s.handleChat(conn, message, models.ChatRoleDriver, driverID)
+			continue
+		}
+
 		var location models.DriverLocation
-		if err := conn.ReadJSON(&location); err != nil {
+		if err := json.Unmarshal(message, &location); err != nil {
 			log.Printf("Error reading location JSON: %v", err)
 			break
 		}
@@ -223,14 +279,20 @@ func (s *NotificationService) SendLocationUpdate(location models.DriverLocation)
 		return fmt.Errorf("failed to marshal location: %w", err)
 	}
 
-	// if there is any driver user connection, send the notification to the user
-	userID, ok := s.driverUserConnections.Load(location.DriverID)
-	if ok {
-		conn, ok := s.userConnections.Load(userID.(string))
-		if ok {
-			err = conn.(*websocket.Conn).WriteJSON(location)
-		} else {
-			log.Print("User connection not found")
+	// if the driver is carrying any users, send the location to each of them
+	riders := s.riders(location.DriverID)
+	if len(riders) > 0 {
+		for _, userID := range riders {
+			conn, ok := s.userConnections.Load(userID)
+			if !ok {
+				log.Print("User connection not found")
+				continue
+			}
+			if writeErr := conn.(*wsConn).WriteJSON(location); writeErr != nil {
+				err = writeErr
+				continue
+			}
+			s.pushETA(conn.(*wsConn), location, userID)
 		}
 	} else {
 		err = s.locationWriter.WriteMessages(context.Background(),
@@ -240,21 +302,63 @@ func (s *NotificationService) SendLocationUpdate(location models.DriverLocation)
 	return err
 }
 
+// riders returns the users whose active bookings are assigned to the driver.
+// A shared trip has one rider per shipper.
+func (s *NotificationService) riders(driverID string) []string {
+	userIDs, ok := s.driverUserConnections.Load(driverID)
+	if !ok {
+		return nil
+	}
+	return userIDs.([]string)
+}
+
+func (s *NotificationService) addRider(driverID, userID string) {
+	s.ridersMu.Lock()
+	defer s.ridersMu.Unlock()
+
+	current := s.riders(driverID)
+	for _, existing := range current {
+		if existing == userID {
+			return
+		}
+	}
+	updated := append(append([]string{}, current...), userID)
+	s.driverUserConnections.Store(driverID, updated)
+}
+
+func (s *NotificationService) removeRider(driverID, userID string) {
+	s.ridersMu.Lock()
+	defer s.ridersMu.Unlock()
+
+	var updated []string
+	for _, existing := range s.riders(driverID) {
+		if existing != userID {
+			updated = append(updated, existing)
+		}
+	}
+	if len(updated) == 0 {
+		s.driverUserConnections.Delete(driverID)
+		return
+	}
+	s.driverUserConnections.Store(driverID, updated)
+}
+
 func (s *NotificationService) SendNotification(notification models.BookingNotification) error {
 	conn, ok := s.driverConnections.Load(notification.DriverID)
 	if !ok {
 		return fmt.Errorf("driver %s not connected", notification.DriverID)
 	}
 
-	return conn.(*websocket.Conn).WriteJSON(notification)
+	return conn.(*wsConn).WriteJSON(notification)
 }
 
 func (s *NotificationService) HandleUserWebSocket(c *gin.Context) {
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade connection: %v", err)
 		return
 	}
+	conn := newWSConn(ws)
 
 	defer conn.Close()
 
@@ -288,13 +392,23 @@ func (s *NotificationService) HandleUserWebSocket(c *gin.Context) {
 	s.userConnections.Store(userID, conn)
 	defer s.userConnections.Delete(userID)
 
-	// Proceed with WebSocket communication
+	// Proceed with WebSocket communication; users only send chat
 	for {
-		_, _, err := conn.ReadMessage()
+		_, message, err := conn.ReadMessage()
 		if err != nil {
 			log.Printf("Error reading message: %v", err)
 			break
 		}
+
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			continue
+		}
+		if envelope.Type == models.ChatTypeMessage || envelope.Type == models.ChatTypeRead {
+			s.handleChat(conn, message, models.ChatRoleUser, userID)
+		}
 	}
 }
 
@@ -352,6 +466,11 @@ func (s *NotificationService) GracefulShutdown(server *http.Server) {
 	closeWithTimeout(s.locationWriter.Close, "location writer")
 	closeWithTimeout(s.notificationReader.Close, "notification reader")
 	closeWithTimeout(s.bookNotificationReader.Close, "book notification reader")
+	closeWithTimeout(s.offerActionWriter.Close, "offer action writer")
+	closeWithTimeout(s.offerResultReader.Close, "offer result reader")
+	closeWithTimeout(s.chatWriter.Close, "chat writer")
+	closeWithTimeout(s.chatReader.Close, "chat reader")
+	closeWithTimeout(s.heatmapReader.Close, "surge heatmap reader")
 
 	log.Println("Server exiting")
 	os.Exit(0)
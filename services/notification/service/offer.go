@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// SendOfferAction forwards a driver's accept_offer or decline_offer message to
+// the booking service. The driver is always taken from the socket's token,
+// never from the message. Messages without an offer id are rejected on the
+// socket straight away.
+func (s *NotificationService) SendOfferAction(message []byte, driver models.UserRequest) error {
+	var action models.OfferAction
+	if err := json.Unmarshal(message, &action); err != nil {
+		return fmt.Errorf("failed to unmarshal offer action: %w", err)
+	}
+	action.DriverID = driver.UserID
+	action.DriverName = driver.UserName
+
+	if action.MongoID == "" {
+		s.sendOfferResult(models.OfferActionResult{
+			Type:      "offer_ack",
+			RequestID: action.RequestID,
+			Action:    action.Type,
+			DriverID:  action.DriverID,
+			Error:     "mongo_id is required",
+		})
+		return nil
+	}
+
+	actionJSON, err := json.Marshal(action)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offer action: %w", err)
+	}
+
+	return s.offerActionWriter.WriteMessages(context.Background(), kafka.Message{Value: actionJSON})
+}
+
+// ConsumeOfferResults delivers the booking service's acknowledgements of
+// offer actions to the drivers who sent them.
+func (s *NotificationService) ConsumeOfferResults() {
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.shutdown:
+			log.Println("Stopping offer result consumer")
+			return
+		default:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			msg, err := s.offerResultReader.FetchMessage(ctx)
+			cancel()
+
+			if err != nil {
+				if err == context.DeadlineExceeded {
+					time.Sleep(1 * time.Second)
+				} else {
+					log.Printf("Error fetching offer result: %v", err)
+				}
+				continue
+			}
+
+			var result models.OfferActionResult
+			if err := json.Unmarshal(msg.Value, &result); err != nil {
+				log.Printf("Error unmarshaling offer result: %v", err)
+			} else {
+				s.sendOfferResult(result)
+			}
+
+			if err := s.offerResultReader.CommitMessages(context.Background(), msg); err != nil {
+				log.Printf("Error committing message: %v", err)
+			}
+		}
+	}
+}
+
+func (s *NotificationService) sendOfferResult(result models.OfferActionResult) {
+	conn, ok := s.driverConnections.Load(result.DriverID)
+	if !ok {
+		log.Printf("Driver connection not found for driverID: %s", result.DriverID)
+		return
+	}
+
+	if err := conn.(*wsConn).WriteJSON(result); err != nil {
+		log.Printf("Error sending offer result to driver %s: %v", result.DriverID, err)
+	}
+}
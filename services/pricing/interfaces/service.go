@@ -2,17 +2,29 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"logistics-platform/lib/models"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PricingInterface interface {
 	HandlePriceEstimate(c *gin.Context)
+	HandleVehiclePricing(c *gin.Context)
+	HandleVehicleTypes(c *gin.Context)
+	HandleSurge(c *gin.Context)
+	HandleSurgeHistory(c *gin.Context)
 	EstimatePrice(ctx context.Context, req models.BookingRequest) (models.PriceEstimate, error)
 	GetVehiclePricing(vehicleType string) (models.VehiclePricing, error)
-	CalculateSurgeMultiplier(ctx context.Context, pickup, dropoff models.GeoPoint) float64
+	VehiclePricingAt(vehicleType string, regionID int32, at time.Time, experimentID int32, variant string) (models.VehiclePricing, error)
+	SurgeAt(ctx context.Context, location models.GeoPoint, pickupAt time.Time) (models.SurgeAtLocation, error)
+	RecordExposure(experimentID int32, variant, userID string)
+	LoadVehiclePricing(ctx context.Context) error
+	WatchVehiclePricing()
+	RunSurgeEngine()
+	CalculateSurgeMultiplier(ctx context.Context, pickup, dropoff models.GeoPoint, pickupAt time.Time) float64
 	GetCurrentDemand(location models.GeoPoint) (float64, error)
-	GracefulShutdown(server *http.Server)
+	GracefulShutdown(grpcServer io.Closer, server *http.Server)
 }
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http"
+	"time"
+	// time zones are embedded so regions price correctly on images without
+	// a zoneinfo database
+	_ "time/tzdata"
 
 	"logistics-platform/lib/config"
 	"logistics-platform/lib/database"
 	"logistics-platform/services/pricing/router"
+	"logistics-platform/services/pricing/rpc"
 	"logistics-platform/services/pricing/service"
 
 	"logistics-platform/lib/middlewares/cors"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -24,7 +33,32 @@ func main() {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 
-	service := service.NewPricingService(redisClient)
+	poolConfig, err := pgxpool.ParseConfig(config.GetDBConnectionString())
+	if err != nil {
+		log.Fatalf("Failed to parse pool config: %v", err)
+	}
+
+	poolConfig.MaxConns = 10
+	poolConfig.MinConns = 2
+	poolConfig.MaxConnLifetime = 1 * time.Hour
+	poolConfig.MaxConnIdleTime = 30 * time.Minute
+
+	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pool.Close()
+
+	service := service.NewPricingService(redisClient, pool)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := service.LoadVehiclePricing(ctx); err != nil {
+		log.Fatalf("Failed to load vehicle pricing: %v", err)
+	}
+	cancel()
+
+	go service.WatchVehiclePricing()
+	go service.RunSurgeEngine()
 
 	r := gin.Default()
 	r.Use(cors.CORSMiddleware())
@@ -41,5 +75,20 @@ func main() {
 		}
 	}()
 
-	service.GracefulShutdown(server)
+	// the booking service prices over gRPC, from the same service
+	grpcServer := grpc.NewServer()
+	grpcStopper := rpc.SetupServer(grpcServer, service)
+
+	listener, err := net.Listen("tcp", ":9086")
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
+	service.GracefulShutdown(grpcStopper, server)
 }
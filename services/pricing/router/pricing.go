@@ -13,5 +13,9 @@ func SetupRouter(router *gin.Engine, service interfaces.PricingInterface) {
 	})
 
 	router.POST("/pricing/estimate", service.HandlePriceEstimate)
+	router.GET("/pricing/vehicles", service.HandleVehicleTypes)
+	router.GET("/pricing/vehicles/:type", service.HandleVehiclePricing)
+	router.GET("/pricing/surge", service.HandleSurge)
+	router.GET("/pricing/surge/history", service.HandleSurgeHistory)
 
 }
@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/pricingrpc"
+	"logistics-platform/lib/token"
+	"logistics-platform/services/pricing/interfaces"
+	"logistics-platform/services/pricing/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type server struct {
+	pricingrpc.UnimplementedPricingServiceServer
+	service interfaces.PricingInterface
+}
+
+// SetupServer registers the pricing API on grpcServer, served from the same
+// PricingInterface as the REST router, along with grpc.health.v1. Closing what
+// it returns reports NOT_SERVING, so callers stop sending new calls, and then
+// stops grpcServer once the calls in flight have finished.
+func SetupServer(grpcServer *grpc.Server, service interfaces.PricingInterface) io.Closer {
+	pricingrpc.RegisterPricingServiceServer(grpcServer, &server{service: service})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pricingrpc.PricingService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	return &stopper{grpcServer: grpcServer, health: healthServer}
+}
+
+type stopper struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+}
+
+func (s *stopper) Close() error {
+	s.health.Shutdown()
+	s.grpcServer.GracefulStop()
+	return nil
+}
+
+// Estimate prices a booking request for the user of the bearer token in the
+// authorization metadata, as POST /pricing/estimate. Quotes carrying the
+// internal quote metadata are not counted as experiment exposures.
+func (s *server) Estimate(ctx context.Context, req *pricingrpc.EstimateRequest) (*pricingrpc.PriceEstimate, error) {
+	if req.GetVehicleType() == "" || req.GetPickup() == nil || req.GetDropoff() == nil {
+		return nil, status.Error(codes.InvalidArgument, "vehicle_type, pickup and dropoff are required")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	bookingReq := pricingrpc.ToBookingRequest(req)
+	if values := md.Get("authorization"); len(values) > 0 {
+		if authToken := strings.TrimPrefix(values[0], "Bearer "); authToken != "" {
+			if user, err := token.GetUserFromToken(authToken); err == nil {
+				bookingReq.UserID = user.UserID
+			}
+		}
+	}
+
+	estimate, err := s.service.EstimatePrice(ctx, bookingReq)
+	if errors.Is(err, service.ErrUnsupportedVehicleType) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	if estimate.ExperimentID != 0 && len(md.Get(models.InternalQuoteHeader)) == 0 {
+		go s.service.RecordExposure(estimate.ExperimentID, estimate.ExperimentVariant, bookingReq.UserID)
+	}
+
+	return pricingrpc.FromPriceEstimate(estimate), nil
+}
+
+// GetVehiclePricing returns a vehicle type's rate card, as
+// GET /pricing/vehicles/:type.
+func (s *server) GetVehiclePricing(ctx context.Context, req *pricingrpc.GetVehiclePricingRequest) (*pricingrpc.VehiclePricing, error) {
+	if req.GetVehicleType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "vehicle_type is required")
+	}
+
+	at := time.Now()
+	if req.GetAt() != nil {
+		at = req.GetAt().AsTime()
+	}
+
+	vehiclePricing, err := s.service.VehiclePricingAt(req.GetVehicleType(), req.GetRegionId(), at, req.GetExperimentId(), req.GetVariant())
+	if errors.Is(err, service.ErrUnsupportedVehicleType) || errors.Is(err, service.ErrVariantNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return pricingrpc.FromVehiclePricing(vehiclePricing), nil
+}
+
+// GetSurge returns the surge at a pickup location, as GET /pricing/surge.
+func (s *server) GetSurge(ctx context.Context, req *pricingrpc.GetSurgeRequest) (*pricingrpc.SurgeAtLocation, error) {
+	if req.GetLocation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "location is required")
+	}
+
+	pickupAt := time.Now()
+	if req.GetAt() != nil {
+		pickupAt = req.GetAt().AsTime()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	current, err := s.service.SurgeAt(ctx, pricingrpc.ToGeoPoint(req.GetLocation()), pickupAt)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return pricingrpc.FromSurgeAtLocation(current), nil
+}
+
+// statusError reports a cancelled or expired call as such, so callers can
+// tell it from a failure of the service.
+func statusError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
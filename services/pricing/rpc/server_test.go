@@ -0,0 +1,197 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/pricingrpc"
+	"logistics-platform/lib/token"
+	"logistics-platform/services/pricing/interfaces"
+	"logistics-platform/services/pricing/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakePricing prices every trip the same and records who it priced for. Only
+// the methods the server calls are implemented.
+type fakePricing struct {
+	interfaces.PricingInterface
+	estimatedFor chan string
+	exposures    chan string
+}
+
+func (f *fakePricing) EstimatePrice(ctx context.Context, req models.BookingRequest) (models.PriceEstimate, error) {
+	f.estimatedFor <- req.UserID
+	if req.VehicleType != "van" {
+		return models.PriceEstimate{}, fmt.Errorf("%w: %s", service.ErrUnsupportedVehicleType, req.VehicleType)
+	}
+	return models.PriceEstimate{VehicleType: "van", Currency: "EUR", ExperimentID: 4, ExperimentVariant: "treatment",
+		TotalPrice: money.FromFloat(42.5), Payable: money.FromFloat(42.5)}, nil
+}
+
+func (f *fakePricing) RecordExposure(experimentID int32, variant, userID string) {
+	f.exposures <- userID
+}
+
+func (f *fakePricing) VehiclePricingAt(vehicleType string, regionID int32, at time.Time, experimentID int32, variant string) (models.VehiclePricing, error) {
+	if vehicleType != "van" {
+		return models.VehiclePricing{}, fmt.Errorf("%w: %s", service.ErrUnsupportedVehicleType, vehicleType)
+	}
+	return models.VehiclePricing{Type: vehicleType, RegionID: regionID, BasePrice: 10, EffectiveFrom: at}, nil
+}
+
+func (f *fakePricing) SurgeAt(ctx context.Context, location models.GeoPoint, pickupAt time.Time) (models.SurgeAtLocation, error) {
+	return models.SurgeAtLocation{Location: location, Multiplier: 1.5, Zone: &models.ZoneSurge{ZoneID: 1, Multiplier: 1.5}}, nil
+}
+
+func newTestClient(t *testing.T) (*grpc.ClientConn, *fakePricing) {
+	t.Helper()
+
+	fake := &fakePricing{estimatedFor: make(chan string, 1), exposures: make(chan string, 1)}
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	SetupServer(grpcServer, fake)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, fake
+}
+
+func estimateRequest(vehicleType string) *pricingrpc.EstimateRequest {
+	return &pricingrpc.EstimateRequest{
+		VehicleType: vehicleType,
+		Pickup:      &pricingrpc.GeoPoint{Latitude: 52.52, Longitude: 13.405},
+		Dropoff:     &pricingrpc.GeoPoint{Latitude: 52.3906, Longitude: 13.0645},
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	conn, fake := newTestClient(t)
+	client := pricingrpc.NewPricingServiceClient(conn)
+
+	userToken, err := token.GenerateToken(12, "Ada")
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		metadata     []string
+		wantUser     string
+		wantExposure bool
+	}{
+		{name: "anonymous", wantExposure: true},
+		{name: "signed in", metadata: []string{"authorization", "Bearer " + userToken}, wantUser: "12", wantExposure: true},
+		{name: "invalid token", metadata: []string{"authorization", "Bearer nonsense"}, wantExposure: true},
+		{name: "internal quote", metadata: []string{"authorization", "Bearer " + userToken, models.InternalQuoteHeader, "booking"}, wantUser: "12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ctx = metadata.AppendToOutgoingContext(ctx, tt.metadata...)
+
+			estimate, err := client.Estimate(ctx, estimateRequest("van"))
+			if err != nil {
+				t.Fatalf("Estimate() error = %v", err)
+			}
+			if estimate.GetTotalPrice() != "42.5" || estimate.GetCurrency() != "EUR" {
+				t.Errorf("Estimate() = %v, want 42.5 EUR", estimate)
+			}
+			if user := <-fake.estimatedFor; user != tt.wantUser {
+				t.Errorf("priced for user %q, want %q", user, tt.wantUser)
+			}
+
+			select {
+			case user := <-fake.exposures:
+				if !tt.wantExposure {
+					t.Errorf("recorded an exposure for %q, want none", user)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantExposure {
+					t.Error("no exposure recorded")
+				}
+			}
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	conn, fake := newTestClient(t)
+	client := pricingrpc.NewPricingServiceClient(conn)
+	ctx := context.Background()
+
+	_, err := client.Estimate(ctx, estimateRequest("spaceship"))
+	<-fake.estimatedFor
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Estimate() of an unsupported vehicle = %v, want InvalidArgument", err)
+	}
+
+	if _, err := client.Estimate(ctx, &pricingrpc.EstimateRequest{VehicleType: "van"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Estimate() without locations = %v, want InvalidArgument", err)
+	}
+
+	if _, err := client.GetVehiclePricing(ctx, &pricingrpc.GetVehiclePricingRequest{VehicleType: "spaceship"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetVehiclePricing() of an unsupported vehicle = %v, want NotFound", err)
+	}
+
+	if _, err := client.GetSurge(ctx, &pricingrpc.GetSurgeRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetSurge() without a location = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGetVehiclePricingAndSurge(t *testing.T) {
+	conn, _ := newTestClient(t)
+	client := pricingrpc.NewPricingServiceClient(conn)
+	ctx := context.Background()
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	vehiclePricing, err := client.GetVehiclePricing(ctx, &pricingrpc.GetVehiclePricingRequest{VehicleType: "van", RegionId: 2, At: pricingrpc.FromTime(at)})
+	if err != nil {
+		t.Fatalf("GetVehiclePricing() error = %v", err)
+	}
+	if got := pricingrpc.ToVehiclePricing(vehiclePricing); got.RegionID != 2 || got.BasePrice != 10 || !got.EffectiveFrom.Equal(at) {
+		t.Errorf("GetVehiclePricing() = %+v, want region 2's card at %v", got, at)
+	}
+
+	current, err := client.GetSurge(ctx, &pricingrpc.GetSurgeRequest{Location: &pricingrpc.GeoPoint{Latitude: 52.52, Longitude: 13.405}})
+	if err != nil {
+		t.Fatalf("GetSurge() error = %v", err)
+	}
+	if got := pricingrpc.ToSurgeAtLocation(current); got.Multiplier != 1.5 || got.Zone == nil || got.Zone.ZoneID != 1 {
+		t.Errorf("GetSurge() = %+v, want 1.5 in zone 1", got)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	conn, _ := newTestClient(t)
+	client := healthpb.NewHealthClient(conn)
+
+	for _, name := range []string{"", pricingrpc.PricingService_ServiceDesc.ServiceName} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", name, err)
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Check(%q) = %v, want SERVING", name, resp.GetStatus())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// experiments holds every pricing experiment, ended ones included, so trips
+// quoted under a variant can still be invoiced at its rates.
+type experiments struct {
+	mu          sync.RWMutex
+	experiments []models.PricingExperiment
+}
+
+func (e *experiments) replace(experiments []models.PricingExperiment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.experiments = experiments
+}
+
+// assign returns the variant a user is in for the first experiment running
+// at that covers the region and vehicle type. Anonymous users are never
+// assigned, so they see the rate card.
+func (e *experiments) assign(userID string, regionID int32, vehicleType string, at time.Time) (models.PricingExperiment, models.ExperimentVariant, bool) {
+	if userID == "" {
+		return models.PricingExperiment{}, models.ExperimentVariant{}, false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, experiment := range e.experiments {
+		if !experiment.Active || experiment.StartsAt.After(at) || (experiment.EndsAt != nil && !experiment.EndsAt.After(at)) {
+			continue
+		}
+		if (experiment.VehicleType != "" && experiment.VehicleType != vehicleType) || (experiment.RegionID != 0 && experiment.RegionID != regionID) {
+			continue
+		}
+		return experiment, bucket(experiment, userID), true
+	}
+	return models.PricingExperiment{}, models.ExperimentVariant{}, false
+}
+
+// variant looks up a variant of an experiment by name.
+func (e *experiments) variant(experimentID int32, name string) (models.ExperimentVariant, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, experiment := range e.experiments {
+		if experiment.ID != experimentID {
+			continue
+		}
+		for _, variant := range experiment.Variants {
+			if variant.Name == name {
+				return variant, true
+			}
+		}
+	}
+	return models.ExperimentVariant{}, false
+}
+
+// bucket deterministically picks a user's variant. The experiment id is part
+// of the hash, so a user's variants in different experiments are unrelated.
+func bucket(experiment models.PricingExperiment, userID string) models.ExperimentVariant {
+	total := 0
+	for _, variant := range experiment.Variants {
+		total += variant.Weight
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", experiment.ID, userID)))
+	point := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+
+	for _, variant := range experiment.Variants {
+		if point < variant.Weight {
+			return variant
+		}
+		point -= variant.Weight
+	}
+	return experiment.Variants[len(experiment.Variants)-1]
+}
+
+func (s *PricingService) loadExperiments(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, `SELECT id, name, description, COALESCE(vehicle_type, ''), COALESCE(region_id, 0), variants, starts_at, ends_at,
+		active, created_at FROM pricing_experiments ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var loaded []models.PricingExperiment
+	for rows.Next() {
+		var experiment models.PricingExperiment
+		if err := rows.Scan(&experiment.ID, &experiment.Name, &experiment.Description, &experiment.VehicleType, &experiment.RegionID,
+			&experiment.Variants, &experiment.StartsAt, &experiment.EndsAt, &experiment.Active, &experiment.CreatedAt); err != nil {
+			return err
+		}
+		// experiments are validated on the way in, but a variant-less one
+		// must never be bucketed
+		if len(experiment.Variants) == 0 {
+			continue
+		}
+		loaded = append(loaded, experiment)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.experiments.replace(loaded)
+	return nil
+}
+
+// RecordExposure counts a quote shown to a user towards their variant, which
+// the admin experiment report measures conversion against.
+func (s *PricingService) RecordExposure(experimentID int32, variant, userID string) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = s.pool.Exec(ctx, `
+		INSERT INTO pricing_experiment_exposures (experiment_id, user_id, variant) VALUES ($1, $2, $3)
+		ON CONFLICT (experiment_id, user_id) DO UPDATE SET quotes = pricing_experiment_exposures.quotes + 1, variant = EXCLUDED.variant,
+			last_quoted_at = NOW()`,
+		experimentID, id, variant)
+	if err != nil {
+		log.Printf("Error recording exposure to experiment %d: %v", experimentID, err)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"logistics-platform/lib/models"
+	"sync"
+	"time"
+)
+
+type pricingRules struct {
+	mu    sync.RWMutex
+	rules []models.PricingRule
+}
+
+func (r *pricingRules) replace(rules []models.PricingRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = rules
+}
+
+// at returns the rule that applies to a pickup in a region at local, a time
+// in the region's time zone. Rules for every region are considered
+// alongside the region's own.
+func (r *pricingRules) at(regionID int32, local time.Time) (models.PricingRule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var applied models.PricingRule
+	found := false
+	for _, rule := range r.rules {
+		if rule.RegionID != 0 && rule.RegionID != regionID {
+			continue
+		}
+		if !rule.Matches(local) {
+			continue
+		}
+		if !found || outranks(rule, applied) {
+			applied, found = rule, true
+		}
+	}
+	return applied, found
+}
+
+// outranks resolves overlapping rules: the higher priority, then a region's
+// own rule, then the older rule.
+func outranks(rule, other models.PricingRule) bool {
+	if rule.Priority != other.Priority {
+		return rule.Priority > other.Priority
+	}
+	if (rule.RegionID != 0) != (other.RegionID != 0) {
+		return rule.RegionID != 0
+	}
+	return rule.ID < other.ID
+}
+
+func (s *PricingService) loadPricingRules(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, `SELECT id, COALESCE(region_id, 0), name, kind, days, dates, start_time, end_time, multiplier, priority, active,
+		created_at FROM pricing_rules WHERE active ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var loaded []models.PricingRule
+	for rows.Next() {
+		var rule models.PricingRule
+		if err := rows.Scan(&rule.ID, &rule.RegionID, &rule.Name, &rule.Kind, &rule.Days, &rule.Dates, &rule.StartTime, &rule.EndTime,
+			&rule.Multiplier, &rule.Priority, &rule.Active, &rule.CreatedAt); err != nil {
+			return err
+		}
+		loaded = append(loaded, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.pricingRules.replace(loaded)
+	return nil
+}
@@ -2,58 +2,55 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
+	"logistics-platform/lib/fare"
+	kafkaConfig "logistics-platform/lib/kafka"
 	"logistics-platform/lib/models"
+	"logistics-platform/lib/money"
+	"logistics-platform/lib/promotion"
+	"logistics-platform/lib/routing"
+	"logistics-platform/lib/surge"
+	"logistics-platform/lib/token"
 	"logistics-platform/lib/utils"
 	"logistics-platform/services/pricing/interfaces"
 	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/spf13/viper"
 )
 
 type PricingService struct {
-	redisClient *redis.Client
+	redisClient   *redis.Client
+	pool          *pgxpool.Pool
+	rateCards     *rateCards
+	surgeZones    *surgeZones
+	regions       *regions
+	experiments   *experiments
+	pricingRules  *pricingRules
+	routing       routing.Provider
+	heatmapWriter *kafka.Writer
 }
 
-var vehiclePricingData = map[string]models.VehiclePricing{
-	"light_truck": {
-		Type:           "light_truck",
-		BasePrice:      20.0,
-		PricePerKm:     0.1117,
-		PricePerMinute: 0.34,
-	},
-	"van": {
-		Type:           "van",
-		BasePrice:      20.0,
-		PricePerKm:     0.1791,
-		PricePerMinute: 0.34,
-	},
-	"truck": {
-		Type:           "truck",
-		BasePrice:      50.0,
-		PricePerKm:     0.2924,
-		PricePerMinute: 0.5,
-	},
-	"heavy_truck": {
-		Type:           "heavy_truck",
-		BasePrice:      100.0,
-		PricePerKm:     0.3488,
-		PricePerMinute: 0.6,
-	},
-	"trailer": {
-		Type:           "trailer",
-		BasePrice:      200.0,
-		PricePerKm:     0.7859,
-		PricePerMinute: 0.8,
-	},
-}
-
-func NewPricingService(redisClient *redis.Client) interfaces.PricingInterface {
+func NewPricingService(redisClient *redis.Client, pool *pgxpool.Pool) interfaces.PricingInterface {
 	return &PricingService{
-		redisClient: redisClient,
+		redisClient:   redisClient,
+		pool:          pool,
+		rateCards:     &rateCards{},
+		surgeZones:    &surgeZones{},
+		regions:       &regions{},
+		experiments:   &experiments{},
+		pricingRules:  &pricingRules{},
+		routing:       routing.NewProvider(redisClient),
+		heatmapWriter: kafkaConfig.InitKafkaWriter("surge_heatmap"),
 	}
 }
 
@@ -64,117 +61,239 @@ func (s *PricingService) HandlePriceEstimate(c *gin.Context) {
 		return
 	}
 
+	// estimates are public; only a signed-in user's token decides which of
+	// their promo code limits and experiment variant apply, never the body
+	req.UserID = ""
+	if authToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); authToken != "" {
+		if user, err := token.GetUserFromToken(authToken); err == nil {
+			req.UserID = user.UserID
+		}
+	}
+
 	PriceEstimate, err := s.EstimatePrice(c, req)
-	if err != nil {
+	if errors.Is(err, ErrUnsupportedVehicleType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// the booking service re-quotes what the user already saw, which is not
+	// another exposure
+	if PriceEstimate.ExperimentID != 0 && c.GetHeader(models.InternalQuoteHeader) == "" {
+		go s.RecordExposure(PriceEstimate.ExperimentID, PriceEstimate.ExperimentVariant, req.UserID)
+	}
+
+	// price is the fare booked, kept alongside the breakdown for existing
+	// clients
 	c.JSON(http.StatusOK, struct {
-		PriceEstimate float64 `json:"price"`
+		models.PriceEstimate
+		Price money.Amount `json:"price"`
 	}{
-		PriceEstimate: PriceEstimate.TotalPrice,
+		PriceEstimate: PriceEstimate,
+		Price:         PriceEstimate.TotalPrice,
 	})
 }
 
-func (s *PricingService) EstimatePrice(ctx context.Context, req models.BookingRequest) (models.PriceEstimate, error) {
-	distance := calculateDistance(req.Pickup, req.Dropoff)
-	duration := estimateDuration(distance)
-
-	vehiclePricing, err := s.GetVehiclePricing(req.VehicleType)
-	if err != nil {
-		return models.PriceEstimate{}, err
+// HandleVehiclePricing returns a vehicle type's rate card in effect now, or
+// at the RFC 3339 time in at, as VehiclePricingAt. region, experiment and
+// variant are optional.
+func (s *PricingService) HandleVehiclePricing(c *gin.Context) {
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at must be an RFC 3339 timestamp"})
+			return
+		}
 	}
 
-	basePrice := vehiclePricing.BasePrice +
-		(distance * vehiclePricing.PricePerKm) +
-		(duration * vehiclePricing.PricePerMinute)
+	var regionID int32
+	if value := c.Query("region"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid region"})
+			return
+		}
+		regionID = int32(id)
+	}
 
-	surgeMultiplier := s.CalculateSurgeMultiplier(ctx, req.Pickup, req.Dropoff)
+	var experimentID int32
+	if value := c.Query("experiment"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid experiment"})
+			return
+		}
+		experimentID = int32(id)
+	}
 
-	totalPrice := basePrice * surgeMultiplier
+	vehiclePricing, err := s.VehiclePricingAt(c.Param("type"), regionID, at, experimentID, c.Query("variant"))
+	if errors.Is(err, ErrUnsupportedVehicleType) || errors.Is(err, ErrVariantNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-	return models.PriceEstimate{
-		BasePrice:  basePrice,
-		Distance:   distance,
-		Duration:   duration,
-		Surge:      surgeMultiplier,
-		TotalPrice: totalPrice,
-	}, nil
+	c.JSON(http.StatusOK, vehiclePricing)
 }
 
-func calculateDistance(pickup, dropoff models.GeoPoint) float64 {
-	// Haversine formula for calculating distance between two points on a sphere
-	const earthRadius = 6371 // km
+func (s *PricingService) EstimatePrice(ctx context.Context, req models.BookingRequest) (models.PriceEstimate, error) {
+	now := time.Now()
+	region := s.regions.at(req.Pickup)
+	vehiclePricing, err := s.regionalVehiclePricing(region, req.VehicleType, now)
+	if err != nil {
+		return models.PriceEstimate{}, err
+	}
+
+	experiment, variant, inExperiment := s.experiments.assign(req.UserID, region.ID, req.VehicleType, now)
+	if inExperiment {
+		vehiclePricing = variant.Apply(vehiclePricing)
+	}
 
-	lat1 := toRadians(pickup.Latitude)
-	lon1 := toRadians(pickup.Longitude)
-	lat2 := toRadians(dropoff.Latitude)
-	lon2 := toRadians(dropoff.Longitude)
+	// the routing provider falls back to haversine, so this only fails when
+	// ctx is done
+	route, err := s.routing.Route(ctx, req.Pickup, req.Dropoff)
+	if err != nil {
+		return models.PriceEstimate{}, err
+	}
 
-	dlat := lat2 - lat1
-	dlon := lon2 - lon1
+	// calendar rules follow the pickup, which is later for scheduled
+	// bookings
+	pickupAt := now
+	if req.PickupAt != nil && req.PickupAt.After(now) {
+		pickupAt = *req.PickupAt
+	}
+	surgeMultiplier, rule := s.surgeMultiplier(ctx, req.Pickup, pickupAt)
 
-	a := math.Sin(dlat/2)*math.Sin(dlat/2) +
-		math.Cos(lat1)*math.Cos(lat2)*
-			math.Sin(dlon/2)*math.Sin(dlon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	currency := region.Currency
+	components, surcharges := fare.Compute(vehiclePricing, route.Distance, route.Duration, req.Cargo, currency)
+	estimate := models.PriceEstimate{
+		VehicleType:             req.VehicleType,
+		Currency:                currency,
+		RegionID:                region.ID,
+		Region:                  region.Name,
+		RuleVersion:             vehiclePricing.Version,
+		ExperimentID:            experiment.ID,
+		ExperimentVariant:       variant.Name,
+		Distance:                route.Distance,
+		Duration:                route.Duration,
+		RoutingProvider:         route.Provider,
+		BaseFare:                components.BaseFare,
+		DistanceCharge:          components.DistanceCharge,
+		TimeCharge:              components.TimeCharge,
+		WeightCharge:            components.WeightCharge,
+		VolumeCharge:            components.VolumeCharge,
+		MinimumChargeAdjustment: components.MinimumChargeAdjustment,
+		BasePrice:               components.Total(),
+		Surge:                   surgeMultiplier,
+		PricingRule:             rule,
+		FragileSurcharge:        surcharges.Fragile,
+		HazardousSurcharge:      surcharges.Hazardous,
+		LoadingAssistanceFee:    surcharges.LoadingAssistance,
+	}
+	estimate.SurgeAmount = estimate.BasePrice.Mul(surgeMultiplier - 1).Round(currency)
+	if req.AllowShared {
+		// only taken off once the request is pooled with others
+		estimate.SharedDiscount = fare.SharedDiscount(estimate.BasePrice+estimate.SurgeAmount, currency)
+	}
+	estimate.TotalPrice = estimate.BasePrice + estimate.SurgeAmount + surcharges.Total()
 
-	return earthRadius * c
-}
+	if req.PromoCode != "" {
+		if err := s.applyPromotion(ctx, req, &estimate); err != nil {
+			return models.PriceEstimate{}, err
+		}
+	}
 
-func toRadians(deg float64) float64 {
-	return deg * (math.Pi / 180)
+	applyTax(&estimate, region)
+	return estimate, nil
 }
 
-func estimateDuration(distance float64) float64 {
-	// Assuming an average speed of 40 km/h
-	averageSpeed := 40.0                // km/h
-	return distance / averageSpeed * 60 // Convert to minutes
-}
+// applyPromotion takes a promo code's discount off the estimate. A code that
+// does not apply leaves the price as it was and says why in PromoError.
+func (s *PricingService) applyPromotion(ctx context.Context, req models.BookingRequest, estimate *models.PriceEstimate) error {
+	discount, err := promotion.Quote(ctx, s.pool, req.PromoCode, promotion.Fare{
+		UserID:      req.UserID,
+		VehicleType: req.VehicleType,
+		Pickup:      req.Pickup,
+		Amount:      estimate.TotalPrice,
+		Currency:    estimate.Currency,
+	})
+	if errors.Is(err, promotion.ErrNotFound) || errors.Is(err, promotion.ErrNotApplicable) {
+		estimate.PromoError = err.Error()
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-func (s *PricingService) GetVehiclePricing(vehicleType string) (models.VehiclePricing, error) {
-	return vehiclePricingData[vehicleType], nil
+	estimate.Discount = &discount
+	estimate.TotalPrice -= discount.Amount
+	return nil
 }
 
-func (s *PricingService) CalculateSurgeMultiplier(ctx context.Context, pickup, dropoff models.GeoPoint) float64 {
-	demand, err := s.GetCurrentDemand(pickup)
-	if err != nil {
-		log.Printf("Failed to get current demand: %v", err)
-		return 1.0
+// applyTax adds the platform fee the trip's invoice will charge and the
+// region's tax on the fare and fee. An inclusive tax is only itemised, as
+// it is already part of them.
+func applyTax(estimate *models.PriceEstimate, region models.Region) {
+	estimate.PlatformFee = money.FromFloat(viper.GetFloat64("INVOICE_PLATFORM_FEE")).Round(estimate.Currency)
+	estimate.TaxName = region.TaxName
+	estimate.TaxRate = region.TaxRate
+	estimate.TaxInclusive = region.TaxInclusive
+
+	taxable := estimate.TotalPrice + estimate.PlatformFee
+	if region.TaxInclusive {
+		estimate.Tax = taxable.Mul(region.TaxRate / (100 + region.TaxRate)).Round(estimate.Currency)
+		estimate.Payable = taxable
+	} else {
+		estimate.Tax = taxable.Percent(region.TaxRate).Round(estimate.Currency)
+		estimate.Payable = taxable + estimate.Tax
 	}
+}
 
-	hour := time.Now().Hour()
+// CalculateSurgeMultiplier is the surge engine's published multiplier for
+// the pickup's zone, raised further by the peak, weekend or holiday rule of
+// the pickup's region at pickupAt but never beyond the configured maximum.
+func (s *PricingService) CalculateSurgeMultiplier(ctx context.Context, pickup, dropoff models.GeoPoint, pickupAt time.Time) float64 {
+	multiplier, _ := s.surgeMultiplier(ctx, pickup, pickupAt)
+	return multiplier
+}
 
+// surgeMultiplier also names the pricing rule applied, if any. Rules are
+// evaluated in the local time of the pickup's region.
+func (s *PricingService) surgeMultiplier(ctx context.Context, pickup models.GeoPoint, pickupAt time.Time) (float64, string) {
 	surgeFactor := 1.0
-	if demand > 0.8 { // High demand
-		surgeFactor *= 1.5
-	} else if demand > 0.6 { // Moderate demand
-		surgeFactor *= 1.2
+	state, ok, err := s.zoneSurge(ctx, pickup)
+	if err != nil {
+		log.Printf("Failed to get zone surge: %v", err)
+	} else if ok {
+		surgeFactor = state.Multiplier
 	}
 
-	// Increase surge during peak hours (7-9 AM and 5-7 PM)
-	if (hour >= 7 && hour <= 9) || (hour >= 17 && hour <= 19) {
-		surgeFactor *= 1.2
+	region := s.regions.at(pickup)
+	rule, ok := s.pricingRules.at(region.ID, pickupAt.In(location(region)))
+	if ok {
+		surgeFactor *= rule.Multiplier
 	}
 
-	return surgeFactor
+	return math.Max(1, math.Min(surgeFactor, surge.LoadConfig().MaxMultiplier)), rule.Name
 }
 
+// GetCurrentDemand is the share of open requests against idle drivers in the
+// location's zone when the engine last ran, from 0 (no requests) towards 1.
 func (s *PricingService) GetCurrentDemand(location models.GeoPoint) (float64, error) {
-	// Get nearby drivers
-	nearby, err := s.redisClient.GeoRadius(context.Background(), "driver_locations", location.Longitude, location.Latitude, &redis.GeoRadiusQuery{
-		Radius: 1000,
-		Unit:   "km",
-	}).Result()
-	if err != nil {
+	state, ok, err := s.zoneSurge(context.Background(), location)
+	if err != nil || !ok || state.OpenRequests == 0 {
 		return 0, err
 	}
 
-	demand := 1.0 - math.Min(1.0, float64(len(nearby))/100.0)
-	return demand, nil
+	return float64(state.OpenRequests) / float64(state.OpenRequests+state.IdleDrivers), nil
 }
 
-func (s *PricingService) GracefulShutdown(server *http.Server) {
-	utils.WaitForShutdown(server, s.redisClient)
+// GracefulShutdown waits for SIGINT or SIGTERM, then stops the gRPC server
+// before the HTTP server, Redis and Kafka it prices with are closed.
+func (s *PricingService) GracefulShutdown(grpcServer io.Closer, server *http.Server) {
+	utils.WaitForShutdown(grpcServer, server, s.redisClient, s.heatmapWriter)
 }
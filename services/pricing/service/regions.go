@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const defaultCurrency = "USD"
+
+type regions struct {
+	mu      sync.RWMutex
+	regions []models.Region
+}
+
+func (r *regions) replace(regions []models.Region) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.regions = regions
+}
+
+// at returns the region containing point, or the default region when no
+// region does. Regions are ordered by id, so the oldest of overlapping
+// regions wins.
+func (r *regions) at(point models.GeoPoint) models.Region {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, region := range r.regions {
+		if geo.InPolygon(point, region.Polygon) {
+			return region
+		}
+	}
+	return defaultRegion()
+}
+
+// defaultRegion prices pickups outside every region in PRICING_CURRENCY,
+// taxed at INVOICE_TAX_RATE on top of the fare, with pricing rules evaluated
+// in PRICING_TIMEZONE.
+func defaultRegion() models.Region {
+	currency := defaultCurrency
+	if viper.IsSet("PRICING_CURRENCY") {
+		currency = strings.ToUpper(viper.GetString("PRICING_CURRENCY"))
+	}
+	return models.Region{
+		Currency: currency,
+		TaxName:  "Tax",
+		TaxRate:  viper.GetFloat64("INVOICE_TAX_RATE"),
+		Timezone: viper.GetString("PRICING_TIMEZONE"),
+	}
+}
+
+// locations caches time zones by name, as regions are looked up on every
+// quote.
+var locations sync.Map
+
+// location is the time zone a region's pricing rules are evaluated in. A
+// region without one uses the default region's, and without that UTC, so
+// prices never depend on where the server runs.
+func location(region models.Region) *time.Location {
+	name := region.Timezone
+	if name == "" {
+		name = viper.GetString("PRICING_TIMEZONE")
+	}
+	if name == "" {
+		return time.UTC
+	}
+
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Unknown pricing time zone %q, using UTC: %v", name, err)
+		loc = time.UTC
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+func (s *PricingService) loadRegions(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, `SELECT id, name, country, currency, polygon, tax_name, tax_rate, tax_inclusive, timezone, active, created_at
+		FROM pricing_regions WHERE active ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var loaded []models.Region
+	for rows.Next() {
+		var region models.Region
+		if err := rows.Scan(&region.ID, &region.Name, &region.Country, &region.Currency, &region.Polygon, &region.TaxName, &region.TaxRate,
+			&region.TaxInclusive, &region.Timezone, &region.Active, &region.CreatedAt); err != nil {
+			return err
+		}
+		loaded = append(loaded, region)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.regions.replace(loaded)
+	return nil
+}
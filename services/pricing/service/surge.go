@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"logistics-platform/lib/geo"
+	"logistics-platform/lib/models"
+	"logistics-platform/lib/surge"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
+	"github.com/spf13/viper"
+)
+
+// defaultSurgeInterval is how often zone multipliers are recomputed, unless
+// SURGE_INTERVAL (seconds) is set.
+const defaultSurgeInterval = 60 * time.Second
+
+// openRequestTTL matches the expiry of booking requests in MongoDB; older
+// open requests were never accepted and no longer count as demand.
+const openRequestTTL = 10 * time.Minute
+
+// surgeLockKey lets a single pricing instance compute each round, so
+// smoothing is applied once per interval however many instances run.
+const surgeLockKey = "surge_lock"
+
+type surgeZones struct {
+	mu    sync.RWMutex
+	zones []models.SurgeZone
+}
+
+func (z *surgeZones) replace(zones []models.SurgeZone) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	z.zones = zones
+}
+
+func (z *surgeZones) all() []models.SurgeZone {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	return z.zones
+}
+
+// at returns the zone containing point; zones are ordered by id, so the
+// oldest of overlapping zones wins.
+func (z *surgeZones) at(point models.GeoPoint) (models.SurgeZone, bool) {
+	for _, zone := range z.all() {
+		if geo.InPolygon(point, zone.Polygon) {
+			return zone, true
+		}
+	}
+	return models.SurgeZone{}, false
+}
+
+// RunSurgeEngine recomputes every zone's multiplier from its open requests
+// and idle drivers on a schedule and publishes them to Redis for all pricing
+// instances to read.
+func (s *PricingService) RunSurgeEngine() {
+	interval := defaultSurgeInterval
+	if viper.IsSet("SURGE_INTERVAL") {
+		interval = time.Duration(viper.GetInt("SURGE_INTERVAL")) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := s.loadSurgeZones(ctx); err != nil {
+			log.Printf("Error loading surge zones: %v", err)
+		}
+
+		// the lock expires just before the next round, so that round is free
+		// to take it
+		acquired, err := s.redisClient.SetNX(ctx, surgeLockKey, 1, interval*9/10).Result()
+		if err != nil {
+			log.Printf("Error acquiring surge lock: %v", err)
+		} else if acquired {
+			if err := s.computeSurge(ctx); err != nil {
+				log.Printf("Error computing surge: %v", err)
+			}
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+func (s *PricingService) loadSurgeZones(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, "SELECT id, name, polygon, max_multiplier, active, created_at FROM surge_zones WHERE active ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var zones []models.SurgeZone
+	for rows.Next() {
+		var zone models.SurgeZone
+		if err := rows.Scan(&zone.ID, &zone.Name, &zone.Polygon, &zone.MaxMultiplier, &zone.Active, &zone.CreatedAt); err != nil {
+			return err
+		}
+		zones = append(zones, zone)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.surgeZones.replace(zones)
+	return nil
+}
+
+func (s *PricingService) computeSurge(ctx context.Context) error {
+	if err := surge.PruneOpenRequests(ctx, s.redisClient, time.Now().Add(-openRequestTTL)); err != nil {
+		return fmt.Errorf("error pruning open requests: %w", err)
+	}
+
+	previous, err := s.redisClient.HGetAll(ctx, surge.MultipliersKey).Result()
+	if err != nil {
+		return fmt.Errorf("error reading multipliers: %w", err)
+	}
+
+	cfg := surge.LoadConfig()
+	now := time.Now()
+	zones := s.surgeZones.all()
+	states := make([]models.ZoneSurge, 0, len(zones))
+	published := map[string]interface{}{}
+	for _, zone := range zones {
+		openRequests, err := surge.CountInZone(ctx, s.redisClient, surge.OpenRequestsKey, zone.Polygon)
+		if err != nil {
+			return fmt.Errorf("error counting open requests in zone %d: %w", zone.ID, err)
+		}
+		idleDrivers, err := surge.CountInZone(ctx, s.redisClient, surge.DriversKey, zone.Polygon)
+		if err != nil {
+			return fmt.Errorf("error counting drivers in zone %d: %w", zone.ID, err)
+		}
+
+		last := 1.0
+		var lastState models.ZoneSurge
+		if err := json.Unmarshal([]byte(previous[strconv.Itoa(int(zone.ID))]), &lastState); err == nil {
+			last = lastState.Multiplier
+		}
+
+		state := models.ZoneSurge{
+			ZoneID:       zone.ID,
+			ZoneName:     zone.Name,
+			Multiplier:   surge.Next(last, surge.Target(openRequests, idleDrivers, zone.MaxMultiplier, cfg), cfg),
+			OpenRequests: openRequests,
+			IdleDrivers:  idleDrivers,
+			UpdatedAt:    now,
+		}
+		stateJSON, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		published[strconv.Itoa(int(zone.ID))] = stateJSON
+		states = append(states, state)
+	}
+
+	// replacing the whole hash drops zones that were deactivated
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, surge.MultipliersKey)
+	if len(published) > 0 {
+		pipe.HSet(ctx, surge.MultipliersKey, published)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// history and the heatmap are for transparency; prices already follow
+	// the published multipliers, so failures here are only logged
+	if err := s.recordSurgeHistory(ctx, states); err != nil {
+		log.Printf("Error recording surge history: %v", err)
+	}
+	if err := s.publishHeatmap(ctx, zones, states, now); err != nil {
+		log.Printf("Error publishing surge heatmap: %v", err)
+	}
+	return nil
+}
+
+// recordSurgeHistory keeps every computed multiplier, whether or not
+// hysteresis let it change.
+func (s *PricingService) recordSurgeHistory(ctx context.Context, states []models.ZoneSurge) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, state := range states {
+		batch.Queue("INSERT INTO surge_history (zone_id, multiplier, open_requests, idle_drivers, computed_at) VALUES ($1, $2, $3, $4, $5)",
+			state.ZoneID, state.Multiplier, state.OpenRequests, state.IdleDrivers, state.UpdatedAt)
+	}
+
+	results := s.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range states {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return results.Close()
+}
+
+// publishHeatmap sends the zones and their multipliers to the notification
+// service, which pushes them to every connected driver.
+func (s *PricingService) publishHeatmap(ctx context.Context, zones []models.SurgeZone, states []models.ZoneSurge, at time.Time) error {
+	heatmap := models.SurgeHeatmap{Type: "surge_heatmap", Zones: []models.HeatmapZone{}, GeneratedAt: at}
+	for i, zone := range zones {
+		centre, _ := geo.BoundingCircle(zone.Polygon)
+		heatmap.Zones = append(heatmap.Zones, models.HeatmapZone{
+			ZoneID:       zone.ID,
+			ZoneName:     zone.Name,
+			Polygon:      zone.Polygon,
+			Centre:       centre,
+			Multiplier:   states[i].Multiplier,
+			OpenRequests: states[i].OpenRequests,
+			IdleDrivers:  states[i].IdleDrivers,
+		})
+	}
+
+	heatmapJSON, err := json.Marshal(heatmap)
+	if err != nil {
+		return err
+	}
+	return s.heatmapWriter.WriteMessages(ctx, kafka.Message{Value: heatmapJSON})
+}
+
+// zoneSurge returns the published surge of the zone containing point. Points
+// outside every zone have no surge.
+func (s *PricingService) zoneSurge(ctx context.Context, point models.GeoPoint) (models.ZoneSurge, bool, error) {
+	zone, ok := s.surgeZones.at(point)
+	if !ok {
+		return models.ZoneSurge{}, false, nil
+	}
+
+	stateJSON, err := s.redisClient.HGet(ctx, surge.MultipliersKey, strconv.Itoa(int(zone.ID))).Result()
+	if err == redis.Nil {
+		// not computed yet
+		return models.ZoneSurge{ZoneID: zone.ID, ZoneName: zone.Name, Multiplier: 1}, true, nil
+	} else if err != nil {
+		return models.ZoneSurge{}, false, err
+	}
+
+	var state models.ZoneSurge
+	if err := json.Unmarshal([]byte(stateJSON), &state); err != nil {
+		return models.ZoneSurge{}, false, err
+	}
+	return state, true, nil
+}
+
+// maxSurgeHistoryRange bounds how much history one request can read.
+const maxSurgeHistoryRange = 7 * 24 * time.Hour
+
+// HandleSurge shows the surge a trip picked up at lat and lng would be quoted
+// now, and the state of the surge zone it is in. With at, an RFC 3339 time,
+// the pricing rules are those of a pickup scheduled then.
+func (s *PricingService) HandleSurge(c *gin.Context) {
+	point, ok, err := queryPoint(c)
+	if err != nil || !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng are required and must be numbers"})
+		return
+	}
+
+	pickupAt := time.Now()
+	if value := c.Query("at"); value != "" {
+		if pickupAt, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "at must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	current, err := s.SurgeAt(ctx, point, pickupAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get zone surge: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, current)
+}
+
+// SurgeAt is the surge a trip picked up at location at pickupAt is quoted,
+// along with the state of the surge zone containing it.
+func (s *PricingService) SurgeAt(ctx context.Context, location models.GeoPoint, pickupAt time.Time) (models.SurgeAtLocation, error) {
+	current := models.SurgeAtLocation{Location: location}
+	current.Multiplier, current.PricingRule = s.surgeMultiplier(ctx, location, pickupAt)
+	state, ok, err := s.zoneSurge(ctx, location)
+	if err != nil {
+		return models.SurgeAtLocation{}, err
+	}
+	if ok {
+		current.Zone = &state
+	}
+	return current, nil
+}
+
+// HandleSurgeHistory lists the multipliers computed between the RFC 3339
+// times from and to, the last day by default, oldest first. They can be
+// narrowed to one zone by zone_id, or to the zone containing lat and lng.
+func (s *PricingService) HandleSurgeHistory(c *gin.Context) {
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		var err error
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	from := to.Add(-24 * time.Hour)
+	if value := c.Query("from"); value != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+	if to.Sub(from) > maxSurgeHistoryRange {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %s of history can be read at once", maxSurgeHistoryRange)})
+		return
+	}
+
+	query := `SELECT h.zone_id, z.name, h.multiplier, h.open_requests, h.idle_drivers, h.computed_at
+		FROM surge_history h JOIN surge_zones z ON z.id = h.zone_id
+		WHERE h.computed_at >= $1 AND h.computed_at < $2`
+	args := []interface{}{from, to}
+
+	if value := c.Query("zone_id"); value != "" {
+		zoneID, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid zone_id"})
+			return
+		}
+		args = append(args, zoneID)
+		query += " AND h.zone_id = $3"
+	} else if point, ok, err := queryPoint(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be numbers"})
+		return
+	} else if ok {
+		zone, ok := s.surgeZones.at(point)
+		if !ok {
+			// outside every zone there is never any surge to show
+			c.JSON(http.StatusOK, []models.ZoneSurge{})
+			return
+		}
+		args = append(args, zone.ID)
+		query += " AND h.zone_id = $3"
+	}
+	query += " ORDER BY h.computed_at, h.zone_id"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch surge history: %v", err)})
+		return
+	}
+	defer rows.Close()
+
+	history := []models.ZoneSurge{}
+	for rows.Next() {
+		var state models.ZoneSurge
+		if err := rows.Scan(&state.ZoneID, &state.ZoneName, &state.Multiplier, &state.OpenRequests, &state.IdleDrivers, &state.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to scan surge history: %v", err)})
+			return
+		}
+		history = append(history, state)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch surge history: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// queryPoint reads the lat and lng query parameters; ok is false when either
+// is missing.
+func queryPoint(c *gin.Context) (point models.GeoPoint, ok bool, err error) {
+	if c.Query("lat") == "" || c.Query("lng") == "" {
+		return models.GeoPoint{}, false, nil
+	}
+	if point.Latitude, err = strconv.ParseFloat(c.Query("lat"), 64); err != nil {
+		return models.GeoPoint{}, false, err
+	}
+	if point.Longitude, err = strconv.ParseFloat(c.Query("lng"), 64); err != nil {
+		return models.GeoPoint{}, false, err
+	}
+	return point, true, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"logistics-platform/lib/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// vehiclePricingChannel is published on by the admin service whenever a rate
+// card changes, so every pricing instance reloads straight away.
+const vehiclePricingChannel = "vehicle_pricing_updated"
+
+// defaultPricingReloadInterval bounds how stale rate cards get if an update
+// notification is missed, unless PRICING_RELOAD_INTERVAL (seconds) is set.
+const defaultPricingReloadInterval = 60 * time.Second
+
+var ErrUnsupportedVehicleType = errors.New("unsupported vehicle type")
+
+var ErrVariantNotFound = errors.New("experiment variant not found")
+
+const vehiclePricingColumns = `id, COALESCE(region_id, 0), vehicle_type, version, base_price, price_per_km, price_per_minute, price_per_kg, price_per_cubic_metre,
+	minimum_charges, fragile_surcharge, hazardous_surcharge, loading_assistance_fee, retired, effective_from, created_at`
+
+// rateCards holds every version of every vehicle type's rate card in every
+// region, newest first. Scheduled versions are loaded too, so they take
+// effect on time without waiting for a reload.
+type rateCards struct {
+	mu       sync.RWMutex
+	versions map[rateCardKey][]models.VehiclePricing
+}
+
+// rateCardKey identifies a rate card; region zero is the default.
+type rateCardKey struct {
+	regionID    int32
+	vehicleType string
+}
+
+func (r *rateCards) replace(versions map[rateCardKey][]models.VehiclePricing) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions = versions
+}
+
+// has reports whether a region has its own rate card for a vehicle type.
+func (r *rateCards) has(regionID int32, vehicleType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.versions[rateCardKey{regionID, vehicleType}]) > 0
+}
+
+// current returns the version of a region's rate card for a vehicle type in
+// effect at.
+func (r *rateCards) current(regionID int32, vehicleType string, at time.Time) (models.VehiclePricing, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, version := range r.versions[rateCardKey{regionID, vehicleType}] {
+		if !version.EffectiveFrom.After(at) {
+			return version, !version.Retired
+		}
+	}
+	return models.VehiclePricing{}, false
+}
+
+func (r *rateCards) vehicleTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{}
+	types := []string{}
+	for key := range r.versions {
+		if !seen[key.vehicleType] {
+			seen[key.vehicleType] = true
+			types = append(types, key.vehicleType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// LoadVehiclePricing reads every rate card version, the pricing regions they
+// apply in, the pricing experiments varying them and the pricing rules
+// raising surge from Postgres.
+func (s *PricingService) LoadVehiclePricing(ctx context.Context) error {
+	if err := s.loadRegions(ctx); err != nil {
+		return fmt.Errorf("error fetching pricing regions: %w", err)
+	}
+	if err := s.loadExperiments(ctx); err != nil {
+		return fmt.Errorf("error fetching pricing experiments: %w", err)
+	}
+	if err := s.loadPricingRules(ctx); err != nil {
+		return fmt.Errorf("error fetching pricing rules: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, "SELECT "+vehiclePricingColumns+" FROM vehicle_pricing ORDER BY vehicle_type, effective_from DESC, version DESC")
+	if err != nil {
+		return fmt.Errorf("error fetching vehicle pricing: %w", err)
+	}
+	defer rows.Close()
+
+	versions := map[rateCardKey][]models.VehiclePricing{}
+	for rows.Next() {
+		var vp models.VehiclePricing
+		var minimumCharges []byte
+		if err := rows.Scan(&vp.ID, &vp.RegionID, &vp.Type, &vp.Version, &vp.BasePrice, &vp.PricePerKm, &vp.PricePerMinute, &vp.PricePerKg,
+			&vp.PricePerCubicMetre, &minimumCharges, &vp.FragileSurcharge, &vp.HazardousSurcharge, &vp.LoadingAssistanceFee, &vp.Retired,
+			&vp.EffectiveFrom, &vp.CreatedAt); err != nil {
+			return fmt.Errorf("error reading vehicle pricing: %w", err)
+		}
+		if err := json.Unmarshal(minimumCharges, &vp.MinimumCharges); err != nil {
+			return fmt.Errorf("error reading minimum charges of vehicle pricing %d: %w", vp.ID, err)
+		}
+		key := rateCardKey{vp.RegionID, vp.Type}
+		versions[key] = append(versions[key], vp)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading vehicle pricing: %w", err)
+	}
+
+	s.rateCards.replace(versions)
+	return nil
+}
+
+// WatchVehiclePricing reloads rate cards, regions, experiments and pricing
+// rules when the admin service announces a change, and periodically in case
+// an announcement was missed.
+func (s *PricingService) WatchVehiclePricing() {
+	interval := defaultPricingReloadInterval
+	if viper.IsSet("PRICING_RELOAD_INTERVAL") {
+		interval = time.Duration(viper.GetInt("PRICING_RELOAD_INTERVAL")) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pubsub := s.redisClient.Subscribe(context.Background(), vehiclePricingChannel)
+	defer pubsub.Close()
+	updates := pubsub.Channel()
+
+	for {
+		select {
+		case <-updates:
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := s.LoadVehiclePricing(ctx); err != nil {
+			log.Printf("Error reloading vehicle pricing: %v", err)
+		}
+		cancel()
+	}
+}
+
+// GetVehiclePricing returns a vehicle type's default rate card in effect now.
+func (s *PricingService) GetVehiclePricing(vehicleType string) (models.VehiclePricing, error) {
+	return s.regionalVehiclePricing(defaultRegion(), vehicleType, time.Now())
+}
+
+// VehiclePricingAt returns a vehicle type's rate card in effect at, which is
+// how past trips are invoiced at the rates they were booked on. The card of
+// pricing region regionID is returned if it has its own, the default card
+// otherwise; a non-zero experimentID applies that experiment variant's rates.
+func (s *PricingService) VehiclePricingAt(vehicleType string, regionID int32, at time.Time, experimentID int32, variant string) (models.VehiclePricing, error) {
+	if !s.rateCards.has(regionID, vehicleType) {
+		regionID = 0
+	}
+
+	vehiclePricing, ok := s.rateCards.current(regionID, vehicleType, at)
+	if !ok {
+		return models.VehiclePricing{}, fmt.Errorf("%w: %s", ErrUnsupportedVehicleType, vehicleType)
+	}
+
+	if experimentID != 0 {
+		experimentVariant, ok := s.experiments.variant(experimentID, variant)
+		if !ok {
+			return models.VehiclePricing{}, ErrVariantNotFound
+		}
+		vehiclePricing = experimentVariant.Apply(vehiclePricing)
+	}
+	return vehiclePricing, nil
+}
+
+// regionalVehiclePricing returns the rate card of a vehicle type in effect
+// at in a region. A region without its own card uses the default one, as
+// long as it is priced in the same currency.
+func (s *PricingService) regionalVehiclePricing(region models.Region, vehicleType string, at time.Time) (models.VehiclePricing, error) {
+	regionID := region.ID
+	if !s.rateCards.has(regionID, vehicleType) && region.Currency == defaultRegion().Currency {
+		regionID = 0
+	}
+
+	vehiclePricing, ok := s.rateCards.current(regionID, vehicleType, at)
+	if !ok {
+		return models.VehiclePricing{}, fmt.Errorf("%w: %s", ErrUnsupportedVehicleType, vehicleType)
+	}
+	return vehiclePricing, nil
+}
+
+// HandleVehicleTypes lists the rate card in effect for every vehicle type
+// currently offered, in the region of the lat and lng given or by default.
+func (s *PricingService) HandleVehicleTypes(c *gin.Context) {
+	region := defaultRegion()
+	if c.Query("lat") != "" && c.Query("lng") != "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+		if latErr != nil || lngErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must be numbers"})
+			return
+		}
+		region = s.regions.at(models.GeoPoint{Latitude: lat, Longitude: lng})
+	}
+
+	vehicles := []models.VehiclePricing{}
+	for _, vehicleType := range s.rateCards.vehicleTypes() {
+		if vehiclePricing, err := s.regionalVehiclePricing(region, vehicleType, time.Now()); err == nil {
+			vehicles = append(vehicles, vehiclePricing)
+		}
+	}
+
+	c.JSON(http.StatusOK, vehicles)
+}